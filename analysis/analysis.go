@@ -0,0 +1,594 @@
+// Package analysis looks up the licenses of the dependencies of Go
+// binaries and modules. It is the library behind the golicense command and
+// reports structured results without writing any output.
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v18/github"
+
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/license/apache"
+	"github.com/mitchellh/golicense/license/bitbucket"
+	"github.com/mitchellh/golicense/license/command"
+	githubFinder "github.com/mitchellh/golicense/license/github"
+	"github.com/mitchellh/golicense/license/golang"
+	"github.com/mitchellh/golicense/license/gopkg"
+	"github.com/mitchellh/golicense/license/goproxy"
+	"github.com/mitchellh/golicense/license/local"
+	"github.com/mitchellh/golicense/license/mapper"
+	"github.com/mitchellh/golicense/license/pkggodev"
+	"github.com/mitchellh/golicense/license/raw"
+	"github.com/mitchellh/golicense/license/resolver"
+	"github.com/mitchellh/golicense/module"
+)
+
+// DefaultParallel is the default for Options.Parallel.
+const DefaultParallel = 5
+
+// Options are the settings for Analyze and Resolve. The zero value looks
+// up licenses with the default finders and no configuration.
+type Options struct {
+	// Config is the configuration (if any). This is used to check if a
+	// license is allowed or not.
+	Config *config.Config
+
+	// Finders are the license finders, in order of priority. If nil,
+	// DefaultFinders is used. If empty, licenses aren't looked up.
+	Finders []license.Finder
+
+	// Translators translate module paths that no finder has a license
+	// for into ones that are more likely to. If nil, DefaultTranslators
+	// is used.
+	Translators []license.Translator
+
+	// PrivateFinders are the finders for internal modules, see
+	// MarkPrivate. These modules can't be found publicly, so they aren't
+	// translated or looked up with Finders, and these finders must not
+	// use the network. If nil, only the overrides of the configuration
+	// are used. If empty, internal modules aren't looked up.
+	PrivateFinders []license.Finder
+
+	// Cache, if set, is consulted before the finders and stores the
+	// licenses they find.
+	Cache Cache
+
+	// Updates, if set, is used to check for newer versions of every
+	// module, which are set as Module.Latest in the results.
+	Updates *goproxy.Proxy
+
+	// MaxAge, if set, marks every module version published longer ago
+	// than this as Module.Stale in the results. The publish time is
+	// requested from Versions, or else taken from the commit date of a
+	// pseudo-version.
+	MaxAge time.Duration
+
+	// Versions is the module proxy that MaxAge requests the publish time
+	// of each module version from. If nil, only pseudo-versions have one.
+	Versions *goproxy.Proxy
+
+	// IncludeStd adds a module for the Go standard library of each Go
+	// version the binaries were built with, see StdModules. Its license is
+	// always StdLicense.
+	IncludeStd bool
+
+	// Skip removes all modules whose path and version contain any of
+	// these strings before looking them up.
+	Skip []string
+
+	// Exclude removes all modules whose path matches any of these import
+	// paths or glob patterns, in addition to the Exclude of the Config.
+	Exclude []string
+
+	// Parallel is the number of modules to look up concurrently. If zero,
+	// DefaultParallel is used.
+	Parallel int
+
+	// LookupTimeout is the longest a lookup of a single module may take
+	// before it is reported as an error. Zero means no limit.
+	LookupTimeout time.Duration
+
+	// MinConfidence is the lowest confidence at which a license detected
+	// from its text is trusted. Licenses detected with a lower confidence
+	// are replaced by an unknown license. Zero trusts every detection.
+	MinConfidence float64
+
+	// Listener, if set, is notified as lookups progress.
+	Listener Listener
+}
+
+// Listener is notified of the progress of the license lookups. The calls
+// for different modules are made concurrently.
+type Listener interface {
+	// Start is called when the license lookup for a module is started.
+	Start(*module.Module)
+
+	// Update is called for each status update during the license lookup.
+	Update(*module.Module, license.StatusType, string)
+
+	// Finish is called when a module license lookup is complete with
+	// the results of the lookup.
+	Finish(*module.Module, *license.License, error)
+}
+
+// Cache stores the licenses found for modules between runs.
+type Cache interface {
+	// Find returns the cached license of the module. If it isn't cached,
+	// find is called and a license it returns is stored.
+	Find(m module.Module, find func() (*license.License, error)) (*license.License, error)
+}
+
+// Result is the outcome of the license lookup of a single module.
+type Result struct {
+	// Module is the module, with Latest set if updates were checked.
+	Module module.Module
+
+	// License is the license found, nil if none was found.
+	License *license.License
+
+	// State is whether the license is allowed by the configuration. It
+	// is always StateUnknown without a configuration.
+	State config.AllowState
+
+	// Err is the error looking up the license, if any. A license may
+	// still be found if only some of the finders failed.
+	Err error
+}
+
+// Analyze reads the dependencies of the given binaries, or go.mod files
+// and module directories, and looks up their licenses. An error is only
+// returned if the dependencies can't be read; lookup errors are reported
+// per module in the results.
+func Analyze(ctx context.Context, binaries []string, opts Options) ([]Result, error) {
+	mods, err := ReadModules(binaries)
+	if err != nil {
+		return nil, err
+	}
+	if opts.IncludeStd {
+		mods = append(mods, StdModules(binaries)...)
+	}
+
+	mods, _ = SkipMain(mods)
+	mods, _ = Skip(mods, opts.Skip)
+	exclude := append([]string{}, opts.Exclude...)
+	if opts.Config != nil {
+		exclude = append(exclude, opts.Config.Exclude...)
+	}
+	mods, _ = Exclude(mods, exclude)
+	MarkPrivate(mods, PrivatePatterns(opts.Config))
+	return Resolve(ctx, mods, opts), nil
+}
+
+// Exclude splits the modules into those to look up and those that match
+// any of the import paths or glob patterns, such as "github.com/myorg/*".
+// A pattern can be limited to some versions, such as
+// "github.com/foo/bar@<v2.0.0".
+func Exclude(mods []module.Module, patterns []string) ([]module.Module, []module.Module) {
+	var kept, excluded []module.Module
+	for _, m := range mods {
+		match := false
+		for _, p := range patterns {
+			if mapper.MatchModule(p, m) {
+				match = true
+				break
+			}
+		}
+
+		if match {
+			excluded = append(excluded, m)
+		} else {
+			kept = append(kept, m)
+		}
+	}
+
+	return kept, excluded
+}
+
+// SkipMain splits the modules into the dependencies to look up and the
+// main modules, see module.Module.Main. The main module is the code being
+// checked, so it has no license to look up.
+func SkipMain(mods []module.Module) ([]module.Module, []module.Module) {
+	var kept, main []module.Module
+	for _, m := range mods {
+		if m.Main {
+			main = append(main, m)
+		} else {
+			kept = append(kept, m)
+		}
+	}
+
+	return kept, main
+}
+
+// Skip splits the modules into those to look up and those whose path and
+// version contain any of the given strings.
+func Skip(mods []module.Module, skip []string) ([]module.Module, []module.Module) {
+	var kept, skipped []module.Module
+	for _, m := range mods {
+		match := false
+		for _, s := range skip {
+			if strings.Contains(m.String(), s) {
+				match = true
+				break
+			}
+		}
+
+		if match {
+			skipped = append(skipped, m)
+		} else {
+			kept = append(kept, m)
+		}
+	}
+
+	return kept, skipped
+}
+
+// PrivatePatterns returns the patterns of internal modules from the
+// GOPRIVATE and GONOSUMDB environment variables, which the Go tool uses for
+// the same purpose, and the "private" configuration.
+func PrivatePatterns(c *config.Config) []string {
+	var result []string
+	for _, env := range []string{"GOPRIVATE", "GONOSUMDB"} {
+		if v := os.Getenv(env); v != "" {
+			result = append(result, v)
+		}
+	}
+	if c != nil {
+		result = append(result, c.Private...)
+	}
+
+	return result
+}
+
+// MarkPrivate sets Private on the modules that match any of the patterns,
+// which use the syntax of GOPRIVATE. The internal modules are returned.
+func MarkPrivate(mods []module.Module, patterns []string) []module.Module {
+	var private []module.Module
+	for i, m := range mods {
+		if module.MatchPrefixPatterns(patterns, m.Path) {
+			mods[i].Private = true
+			private = append(private, mods[i])
+		}
+	}
+
+	return private
+}
+
+// Resolve looks up the licenses of the given modules. The results are in
+// the same order as the modules.
+func Resolve(ctx context.Context, mods []module.Module, opts Options) []Result {
+	ts := opts.Translators
+	if ts == nil {
+		ts = DefaultTranslators(opts.Config, nil)
+	}
+	fs := opts.Finders
+	if fs == nil {
+		fs = DefaultFinders(ctx, opts.Config, nil)
+	}
+	privateFs := opts.PrivateFinders
+	if privateFs == nil {
+		var override map[string]string
+		if opts.Config != nil {
+			override = opts.Config.Override
+		}
+		privateFs = []license.Finder{&mapper.Finder{Map: override}}
+	}
+	parallel := opts.Parallel
+	if parallel <= 0 {
+		parallel = DefaultParallel
+	}
+
+	results := lookupAll(ctx, mods, opts.Listener, newSemaphore(parallel), func(ctx context.Context, m *module.Module) (*license.License, error) {
+		if m.Path == StdPath {
+			// The standard library isn't looked up, its license is known
+			lic := StdLicense
+			return &lic, nil
+		}
+
+		// We first try the untranslated version. If we can detect
+		// a license then take that. Otherwise, we translate.
+		find := func() (*license.License, error) {
+			return lookupTimeout(ctx, opts.LookupTimeout, func(ctx context.Context) (*license.License, error) {
+				if m.Private {
+					// Internal modules can't be found publicly
+					license.UpdateStatus(ctx, license.StatusNormal, "internal module, not looked up publicly")
+					return license.Find(ctx, *m, privateFs)
+				}
+
+				lic, err := license.Find(ctx, *m, fs)
+				if lic == nil && !license.IsTransient(err) && !m.IsLocal() {
+					// Only retry if the module wasn't found. A rate limit
+					// would fail the same way for the translated module,
+					// and a local directory has nothing to translate.
+					if t := license.Translate(ctx, *m, ts); t != *m {
+						lic, err = license.Find(ctx, t, fs)
+					}
+				}
+
+				return lic, err
+			})
+		}
+
+		var lic *license.License
+		var err error
+		if opts.Cache != nil {
+			// The cache only calls find if the module isn't cached. A hash
+			// mismatch is neither a hit nor a miss.
+			called := false
+			lic, err = opts.Cache.Find(*m, func() (*license.License, error) {
+				called = true
+				return find()
+			})
+			if called || err == nil {
+				license.MetricsFromContext(ctx).CacheLookup(!called)
+			}
+		} else {
+			lic, err = find()
+		}
+
+		// A module without a license is reported without an error, only
+		// failed lookups are errors.
+		if errors.Is(err, license.ErrNotFound) {
+			err = nil
+		}
+
+		if opts.Updates != nil && !m.Private {
+			license.UpdateStatus(ctx, license.StatusNormal, "checking for updates")
+			info, err := opts.Updates.Info(ctx, m.MajorPath(), "")
+			if err == nil && goproxy.Newer(m.Version, info.Version) {
+				m.Latest = info.Version
+			}
+		}
+
+		if opts.MaxAge > 0 {
+			checkAge(ctx, m, opts)
+		}
+
+		// Not every finder returns an SPDX ID, so try to map the name
+		if lic != nil && lic.SPDX == "" {
+			lic.SPDX = license.SPDXFromName(lic.Name)
+		}
+
+		if lic != nil && lic.Confidence > 0 {
+			license.UpdateStatus(ctx, license.StatusNormal, fmt.Sprintf(
+				"detected %s with confidence %.2f", lic, lic.Confidence))
+			if lic.Confidence < opts.MinConfidence {
+				license.UpdateStatus(ctx, license.StatusWarning, fmt.Sprintf(
+					"confidence of %s is below %.2f, treating as unknown",
+					lic, opts.MinConfidence))
+				lic = lowConfidence(lic)
+			}
+		}
+
+		return lic, err
+	})
+
+	for i, r := range results {
+		results[i].State = config.StateUnknown
+		if opts.Config != nil {
+			results[i].State = opts.Config.AllowedModule(&r.Module, r.License)
+		}
+	}
+
+	return results
+}
+
+// checkAge sets when a module version was published and whether that was
+// longer ago than the maximum age of the options. Modules without a known
+// publish time are never stale.
+func checkAge(ctx context.Context, m *module.Module, opts Options) {
+	if opts.Versions != nil && !m.Private && !m.IsLocal() {
+		license.UpdateStatus(ctx, license.StatusNormal, "checking version age")
+		if info, err := opts.Versions.Info(ctx, m.MajorPath(), m.Version); err == nil {
+			m.Time = info.Time
+		}
+	}
+	if m.Time.IsZero() {
+		m.Time, _ = m.PseudoTime()
+	}
+
+	if !m.Time.IsZero() && time.Since(m.Time) > opts.MaxAge {
+		m.Stale = true
+		license.UpdateStatus(ctx, license.StatusWarning, fmt.Sprintf(
+			"version was published on %s", m.Time.Format("2006-01-02")))
+	}
+}
+
+// lowConfidence returns an unknown license in place of a license detected
+// with too low a confidence. The name still mentions the detected license
+// for manual review, but it has no SPDX ID so it is neither allowed nor
+// denied by ID.
+func lowConfidence(l *license.License) *license.License {
+	return &license.License{
+		Name:       l.Name + " (low confidence)",
+		URL:        l.URL,
+		Source:     l.Source,
+		Confidence: l.Confidence,
+	}
+}
+
+// DefaultTranslators returns the translators used by the golicense command,
+// including those from the "translate" configuration. If client is nil,
+// http.DefaultClient is used.
+func DefaultTranslators(c *config.Config, client *http.Client) []license.Translator {
+	if c == nil {
+		c = &config.Config{}
+	}
+
+	return []license.Translator{
+		&mapper.Translator{Map: c.Translate},
+		&goproxy.Translator{Client: client},
+		&resolver.Translator{Client: client},
+		&apache.Translator{},
+		&golang.Translator{},
+		&gopkg.Translator{},
+	}
+}
+
+// OfflineTranslators returns the translators of DefaultTranslators that
+// don't make network requests, for running without network access.
+func OfflineTranslators(c *config.Config) []license.Translator {
+	if c == nil {
+		c = &config.Config{}
+	}
+
+	return []license.Translator{
+		&mapper.Translator{Map: c.Translate},
+		&apache.Translator{},
+		&golang.Translator{},
+		&gopkg.Translator{},
+	}
+}
+
+// FinderOptions are the settings for the finders of FinderRegistry. The
+// zero value creates the finders that don't need any credentials.
+type FinderOptions struct {
+	// Client is the HTTP client of the network finders. If nil,
+	// http.DefaultClient is used.
+	Client *http.Client
+
+	// Offline, if true, registers the network finders without creating
+	// them, so they can still be named in the configuration but nothing
+	// is looked up over the network.
+	Offline bool
+
+	// LicenseDirs are the directories the local finder looks for modules
+	// in, see local.Finder.
+	LicenseDirs []string
+
+	// GitHubClient is the client for github.com, and GitHubClients an
+	// optional pool of clients used in turn, see githubFinder.RepoAPI. If
+	// GitHubClient is nil, a client without authentication is created
+	// from Client, which is heavily rate limited.
+	GitHubClient  *github.Client
+	GitHubClients []*github.Client
+
+	// GitHubEnterpriseHost and GitHubEnterpriseClient are the GitHub
+	// Enterprise instance, if any, see githubFinder.RepoAPI.
+	GitHubEnterpriseHost   string
+	GitHubEnterpriseClient *github.Client
+
+	// BitbucketToken is the access token of the Bitbucket API. Without it
+	// the bitbucket finder doesn't look anything up.
+	BitbucketToken string
+
+	// Translators are used to find the "ref" configuration of translated
+	// module paths, see Refs. If nil, DefaultTranslators is used.
+	Translators []license.Translator
+}
+
+// FinderRegistry returns a registry of every license finder in its
+// default order, by the names used in the "finders" configuration. The
+// exec and raw finders are only created if the configuration has a
+// command or URLs for them.
+func FinderRegistry(ctx context.Context, c *config.Config, opts FinderOptions) *license.Registry {
+	if c == nil {
+		c = &config.Config{}
+	}
+
+	var reg license.Registry
+	reg.Register("override", func() (license.Finder, error) {
+		return &mapper.Finder{Map: c.Override}, nil
+	})
+	reg.Register("local", func() (license.Finder, error) {
+		return &local.Finder{Dirs: opts.LicenseDirs}, nil
+	})
+	reg.Register("exec", func() (license.Finder, error) {
+		if len(c.Exec) == 0 {
+			// Only used if a command is configured
+			return nil, nil
+		}
+
+		return &command.Finder{Command: c.Exec}, nil
+	})
+	if opts.Offline {
+		for _, name := range []string{"pkggodev", "goproxy", "github", "bitbucket", "raw"} {
+			reg.Register(name, func() (license.Finder, error) { return nil, nil })
+		}
+
+		return &reg
+	}
+
+	// The refs may need network requests to translate, so they are only
+	// looked up once, by the first finder that uses them
+	var refs map[string]string
+	getRefs := func() map[string]string {
+		if refs == nil {
+			ts := opts.Translators
+			if ts == nil {
+				ts = DefaultTranslators(c, opts.Client)
+			}
+			refs = Refs(ctx, c, ts)
+		}
+
+		return refs
+	}
+
+	// pkg.go.dev knows the license of the exact version so it is
+	// preferred over the default branch license from GitHub.
+	reg.Register("pkggodev", func() (license.Finder, error) {
+		return &pkggodev.Finder{Client: opts.Client}, nil
+	})
+	// The module zip from GOPROXY has the license files of the exact
+	// version for modules on any host.
+	reg.Register("goproxy", func() (license.Finder, error) {
+		return &goproxy.Finder{Client: opts.Client}, nil
+	})
+	reg.Register("github", func() (license.Finder, error) {
+		client := opts.GitHubClient
+		if client == nil {
+			client = github.NewClient(opts.Client)
+		}
+
+		return &githubFinder.RepoAPI{
+			Client:           client,
+			Clients:          opts.GitHubClients,
+			EnterpriseHost:   opts.GitHubEnterpriseHost,
+			EnterpriseClient: opts.GitHubEnterpriseClient,
+			Ref:              getRefs(),
+		}, nil
+	})
+	reg.Register("bitbucket", func() (license.Finder, error) {
+		return &bitbucket.Finder{Client: opts.Client, Token: opts.BitbucketToken}, nil
+	})
+	reg.Register("raw", func() (license.Finder, error) {
+		if len(c.Raw) == 0 {
+			// Only used if URLs are configured
+			return nil, nil
+		}
+
+		return &raw.Finder{Client: opts.Client, URLs: c.Raw, Ref: getRefs()}, nil
+	})
+
+	return &reg
+}
+
+// DefaultFinders returns the finders of FinderRegistry that don't need
+// any credentials, in their default order. If client is nil,
+// http.DefaultClient is used.
+func DefaultFinders(ctx context.Context, c *config.Config, client *http.Client) []license.Finder {
+	// Every finder can be created, so there is no error
+	fs, _ := FinderRegistry(ctx, c, FinderOptions{Client: client}).Finders(nil)
+	return fs
+}
+
+// Refs returns the git refs from the "ref" configuration for the GitHub
+// finder. Refs are configured by import path but the finder may only see
+// the translated path, so both are registered.
+func Refs(ctx context.Context, c *config.Config, ts []license.Translator) map[string]string {
+	refs := map[string]string{}
+	for k, v := range c.Ref {
+		refs[k] = v
+		refs[license.Translate(ctx, module.Module{Path: k}, ts).Path] = v
+	}
+
+	return refs
+}
@@ -0,0 +1,648 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/license/goproxy"
+	"github.com/mitchellh/golicense/license/local"
+	"github.com/mitchellh/golicense/license/mapper"
+	"github.com/mitchellh/golicense/license/pkggodev"
+	"github.com/mitchellh/golicense/module"
+)
+
+func TestAnalyze(t *testing.T) {
+	// The test binary is a Go binary with real dependencies, including
+	// testify, so it serves as the fixture.
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	var f license.MockFinder
+	f.On("License", mock.Anything, mock.MatchedBy(func(m module.Module) bool {
+		return m.Path == "github.com/stretchr/testify"
+	})).Return(&license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+	f.On("License", mock.Anything, mock.Anything).Return(nil, nil)
+
+	results, err := Analyze(context.Background(), []string{exe}, Options{
+		Config:      &config.Config{Allow: []string{"MIT"}},
+		Finders:     []license.Finder{&f},
+		Translators: []license.Translator{},
+		Skip:        []string{"golang.org/x/"},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+
+	found := false
+	for _, r := range results {
+		require.NoError(t, r.Err)
+		require.False(t, strings.HasPrefix(r.Module.Path, "golang.org/x/"), r.Module.Path)
+
+		if r.Module.Path == "github.com/stretchr/testify" {
+			found = true
+			require.Equal(t, "MIT", r.License.SPDX)
+			require.Equal(t, config.StateAllowed, r.State)
+			continue
+		}
+
+		// No license is never allowed
+		require.Nil(t, r.License)
+		require.Equal(t, config.StateDenied, r.State)
+	}
+	require.True(t, found)
+}
+
+func TestAnalyze_includeStd(t *testing.T) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	results, err := Analyze(context.Background(), []string{exe}, Options{
+		Finders:     []license.Finder{},
+		Translators: []license.Translator{},
+		IncludeStd:  true,
+	})
+	require.NoError(t, err)
+
+	// The standard library of the Go version of the test binary is
+	// reported without being looked up
+	var std []Result
+	for _, r := range results {
+		if r.Module.Path == StdPath {
+			std = append(std, r)
+		}
+	}
+	require.Len(t, std, 1)
+	require.Equal(t, runtime.Version(), std[0].Module.Version)
+	require.NoError(t, std[0].Err)
+	require.Equal(t, "BSD-3-Clause", std[0].License.SPDX)
+
+	// A go.mod file has no Go version of a build
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/foo\n"), 0644))
+	require.Empty(t, StdModules([]string{dir}))
+}
+
+func TestAnalyze_readError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "not-a-binary")
+	require.NoError(t, ioutil.WriteFile(path, []byte("hello"), 0644))
+
+	_, err := Analyze(context.Background(), []string{path}, Options{})
+	require.Error(t, err)
+
+	var rerr *ReadError
+	require.True(t, errors.As(err, &rerr))
+	require.Equal(t, path, rerr.Path)
+}
+
+func TestReadModules_goMod(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(`module example.com/foo
+
+require (
+	github.com/foo/bar v1.0.0
+	github.com/foo/baz v0.1.0
+)
+`), 0644))
+
+	// The same module given twice is only read once
+	mods, err := ReadModules([]string{dir, filepath.Join(dir, "go.mod")})
+	require.NoError(t, err)
+	require.Equal(t, []module.Module{
+		{Path: "github.com/foo/bar", Version: "v1.0.0"},
+		{Path: "github.com/foo/baz", Version: "v0.1.0"},
+	}, mods)
+}
+
+func TestReadModules_errorOrder(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"a", "b", "c", "d"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, []byte("hello"), 0644))
+		paths = append(paths, path)
+	}
+
+	// The error is always for the first path, however they are scheduled
+	for i := 0; i < 10; i++ {
+		_, _, err := readAllModules(paths, len(paths), false)
+
+		var rerr *ReadError
+		require.True(t, errors.As(err, &rerr))
+		require.Equal(t, paths[0], rerr.Path)
+	}
+}
+
+func BenchmarkReadModules_serial(b *testing.B) {
+	benchmarkReadModules(b, 1)
+}
+
+func BenchmarkReadModules_parallel(b *testing.B) {
+	benchmarkReadModules(b, runtime.NumCPU())
+}
+
+// benchmarkReadModules reads several copies of the test binary, which has
+// real dependencies, with the given number of parallel reads.
+func benchmarkReadModules(b *testing.B, parallel int) {
+	exe, err := os.Executable()
+	require.NoError(b, err)
+	data, err := ioutil.ReadFile(exe)
+	require.NoError(b, err)
+
+	dir := b.TempDir()
+	paths := make([]string, 8)
+	for i := range paths {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("bin%d", i))
+		require.NoError(b, ioutil.WriteFile(paths[i], data, 0755))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := readAllModules(paths, parallel, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSkip(t *testing.T) {
+	mods := []module.Module{
+		{Path: "github.com/foo/bar", Version: "v1.0.0"},
+		{Path: "github.com/foo/baz", Version: "v0.1.0"},
+		{Path: "golang.org/x/text", Version: "v0.3.0"},
+	}
+
+	kept, skipped := Skip(mods, []string{"baz", "golang.org/"})
+	require.Equal(t, mods[:1], kept)
+	require.Equal(t, mods[1:], skipped)
+
+	kept, skipped = Skip(mods, nil)
+	require.Equal(t, mods, kept)
+	require.Empty(t, skipped)
+}
+
+func TestSkipMain(t *testing.T) {
+	mods := []module.Module{
+		{Path: "github.com/foo/bar", Version: "v0.0.0-20190312203227-4b39c73a6495"},
+		{Path: "example.com/app", Version: module.DevelVersion, Main: true},
+		{Path: "golang.org/x/text", Version: "v0.3.0"},
+	}
+
+	kept, main := SkipMain(mods)
+	require.Equal(t, []module.Module{mods[0], mods[2]}, kept)
+	require.Equal(t, mods[1:2], main)
+}
+
+func TestExclude(t *testing.T) {
+	mods := []module.Module{
+		{Path: "github.com/foo/bar", Version: "v1.0.0"},
+		{Path: "github.com/myorg/baz", Version: "v0.1.0"},
+		{Path: "github.com/myorg/sub/qux", Version: "v0.2.0"},
+		{Path: "golang.org/x/text", Version: "v0.3.0"},
+	}
+
+	kept, excluded := Exclude(mods, []string{"github.com/myorg/*", "golang.org/x/text"})
+	require.Equal(t, mods[:1], kept)
+	require.Equal(t, mods[1:], excluded)
+
+	// Patterns match the whole path, not a substring
+	kept, excluded = Exclude(mods, []string{"github.com/foo", "myorg"})
+	require.Equal(t, mods, kept)
+	require.Empty(t, excluded)
+
+	// Patterns can be limited to some versions
+	kept, excluded = Exclude(mods, []string{"github.com/foo/bar@v1.0.1", "github.com/myorg/*@>=v0.2.0"})
+	require.Equal(t, []module.Module{mods[0], mods[1], mods[3]}, kept)
+	require.Equal(t, mods[2:3], excluded)
+}
+
+func TestAnalyze_exclude(t *testing.T) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	// Excluded modules are never looked up, even with an override
+	var f license.MockFinder
+	f.On("License", mock.Anything, mock.MatchedBy(func(m module.Module) bool {
+		return strings.HasPrefix(m.Path, "github.com/stretchr/")
+	})).Return(nil, errors.New("excluded module looked up"))
+	f.On("License", mock.Anything, mock.Anything).Return(nil, nil)
+
+	results, err := Analyze(context.Background(), []string{exe}, Options{
+		Config: &config.Config{
+			Exclude:  []string{"github.com/stretchr/*"},
+			Override: map[string]string{"github.com/stretchr/testify": "MIT"},
+		},
+		Finders:     []license.Finder{&f},
+		Translators: []license.Translator{},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+
+	for _, r := range results {
+		require.False(t, strings.HasPrefix(r.Module.Path, "github.com/stretchr/"), r.Module.Path)
+	}
+}
+
+func TestResolve_minConfidence(t *testing.T) {
+	// A lightly edited MIT license still matches, but not exactly
+	dir := t.TempDir()
+	path := filepath.Join(dir, "github.com", "foo", "bar", "LICENSE")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(modifiedMIT), 0644))
+
+	cases := []struct {
+		MinConfidence float64
+		Name          string
+		SPDX          string
+		State         config.AllowState
+	}{
+		{0, "MIT License", "MIT", config.StateAllowed},
+		{0.9, "MIT License", "MIT", config.StateAllowed},
+		{0.99, "MIT License (low confidence)", "", config.StateNotAllowed},
+	}
+
+	for _, tt := range cases {
+		t.Run(fmt.Sprint(tt.MinConfidence), func(t *testing.T) {
+			results := Resolve(context.Background(), []module.Module{
+				{Path: "github.com/foo/bar", Version: "v1.0.0"},
+			}, Options{
+				Config:        &config.Config{Allow: []string{"MIT"}},
+				Finders:       []license.Finder{&local.Finder{Dirs: []string{dir}}},
+				Translators:   []license.Translator{},
+				MinConfidence: tt.MinConfidence,
+			})
+			require.Len(t, results, 1)
+
+			r := results[0]
+			require.NoError(t, r.Err)
+			require.NotNil(t, r.License)
+			require.Equal(t, tt.Name, r.License.Name)
+			require.Equal(t, tt.SPDX, r.License.SPDX)
+			require.Equal(t, tt.State, r.State)
+			require.True(t, r.License.Confidence >= 0.9 && r.License.Confidence < 0.99,
+				"confidence: %v", r.License.Confidence)
+		})
+	}
+}
+
+const modifiedMIT = `MIT License
+
+Copyright (c) 2018 Example
+
+Permission is hereby granted, at no cost, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+`
+
+func TestResolve_translate(t *testing.T) {
+	m := module.Module{Path: "example.com/foo/bar", Version: "v1.0.0"}
+	translated := module.Module{Path: "github.com/foo/bar", Version: "v1.0.0"}
+	mit := &license.License{Name: "MIT License", SPDX: "MIT"}
+
+	cases := []struct {
+		Name       string
+		Err        error
+		Translate  bool
+		Translated bool
+		License    *license.License
+	}{
+		{"not found", nil, true, true, mit},
+		{"error", errors.New("repository not found"), true, true, mit},
+		{"rate limited", license.Transient(errors.New("rate limited")), true, false, nil},
+		{"no translation", nil, false, false, nil},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			var f license.MockFinder
+			f.On("License", mock.Anything, m).Return(nil, tt.Err)
+			f.On("License", mock.Anything, translated).Return(mit, nil)
+
+			tmap := map[string]string{}
+			if tt.Translate {
+				tmap[m.Path] = translated.Path
+			}
+
+			results := Resolve(context.Background(), []module.Module{m}, Options{
+				Finders:     []license.Finder{&f},
+				Translators: []license.Translator{&mapper.Translator{Map: tmap}},
+			})
+			require.Len(t, results, 1)
+			require.Equal(t, tt.License, results[0].License)
+
+			// A transient error isn't repeated for the translated module
+			f.AssertNumberOfCalls(t, "License", map[bool]int{false: 1, true: 2}[tt.Translated])
+			if !tt.Translated {
+				f.AssertNotCalled(t, "License", mock.Anything, translated)
+			}
+			if tt.Err != nil && !tt.Translated {
+				require.Error(t, results[0].Err)
+			}
+		})
+	}
+}
+
+func TestAnalyze_goModReplace(t *testing.T) {
+	dir := t.TempDir()
+	mod := filepath.Join(dir, "mod")
+	require.NoError(t, os.Mkdir(mod, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(mod, "go.mod"), []byte(`module example.com/foo
+
+require (
+	github.com/foo/bar v1.0.0
+	github.com/foo/local v1.0.0
+)
+
+replace github.com/foo/bar => github.com/fork/bar v1.0.1
+
+replace github.com/foo/local => ../local
+`), 0644))
+
+	// The local replacement has its own license
+	localDir := filepath.Join(dir, "local")
+	require.NoError(t, os.Mkdir(localDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(localDir, "LICENSE"), []byte(modifiedMIT), 0644))
+
+	var f license.MockFinder
+	f.On("License", mock.Anything, module.Module{Path: "github.com/fork/bar", Version: "v1.0.1"}).
+		Return(&license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0"}, nil)
+
+	results, err := Analyze(context.Background(), []string{mod}, Options{
+		Finders:     []license.Finder{&local.Finder{}, &f},
+		Translators: []license.Translator{},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	// The local directory is found by the local finder and never by the
+	// other finders
+	require.Equal(t, localDir, results[0].Module.Path)
+	require.NoError(t, results[0].Err)
+	require.Equal(t, "MIT", results[0].License.SPDX)
+	require.Equal(t, "local", results[0].License.Source)
+
+	// The fork is looked up, not the module it replaces
+	require.Equal(t, "github.com/fork/bar", results[1].Module.Path)
+	require.Equal(t, "v1.0.1", results[1].Module.Version)
+	require.Equal(t, "Apache-2.0", results[1].License.SPDX)
+	f.AssertNumberOfCalls(t, "License", 1)
+}
+
+func TestAnalyze_private(t *testing.T) {
+	t.Setenv("GOPRIVATE", "git.corp.example.com/*")
+	t.Setenv("GONOSUMDB", "")
+
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(`module example.com/foo
+
+require (
+	git.corp.example.com/team/lib v1.0.0
+	github.com/foo/bar v1.0.0
+)
+`), 0644))
+
+	// Record every request made by the network finder
+	var lock sync.Mutex
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		requested = append(requested, r.URL.Path)
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	results, err := Analyze(context.Background(), []string{dir}, Options{
+		Config: &config.Config{
+			Override: map[string]string{"git.corp.example.com/team/lib": "MIT"},
+		},
+		Finders:     []license.Finder{&pkggodev.Finder{Client: srv.Client(), URL: srv.URL}},
+		Translators: []license.Translator{},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	// The internal module only gets the override, and the public module
+	// isn't internal
+	require.Equal(t, "git.corp.example.com/team/lib", results[0].Module.Path)
+	require.True(t, results[0].Module.Private)
+	require.NoError(t, results[0].Err)
+	require.Equal(t, "MIT", results[0].License.SPDX)
+	require.False(t, results[1].Module.Private)
+
+	// Only the public module was looked up over the network
+	require.Equal(t, []string{"/github.com/foo/bar@v1.0.0"}, requested)
+}
+
+func TestAnalyze_updates(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(`module example.com/foo
+
+require (
+	github.com/foo/bar v1.0.0
+	github.com/foo/major/v2 v2.0.0
+)
+`), 0644))
+
+	// The latest version of a v2+ module is requested with its suffix
+	latest := map[string]string{
+		"/github.com/foo/bar/@latest":      "v1.2.0",
+		"/github.com/foo/major/@latest":    "v1.9.0",
+		"/github.com/foo/major/v2/@latest": "v2.1.0",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v, ok := latest[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"Version":%q}`, v)
+	}))
+	defer srv.Close()
+
+	results, err := Analyze(context.Background(), []string{dir}, Options{
+		Finders:     []license.Finder{},
+		Translators: []license.Translator{},
+		Updates:     &goproxy.Proxy{Client: srv.Client(), URL: srv.URL},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, "github.com/foo/bar", results[0].Module.Path)
+	require.Equal(t, "v1.2.0", results[0].Module.Latest)
+	require.Equal(t, "github.com/foo/major", results[1].Module.Path)
+	require.Equal(t, "v2.1.0", results[1].Module.Latest)
+}
+
+func TestAnalyze_maxAge(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(`module example.com/foo
+
+require (
+	github.com/foo/new v1.1.0
+	github.com/foo/old v1.0.0
+	github.com/foo/oldmajor/v2 v2.0.0
+	github.com/foo/pseudo v0.0.0-20150102030405-4b39c73a6495
+)
+`), 0644))
+
+	// The proxy knows the publish time of the tagged versions only, v2+
+	// modules by their path with the major version suffix
+	published := map[string]time.Time{
+		"/github.com/foo/new/@v/v1.1.0.info":         time.Now().Add(-24 * time.Hour),
+		"/github.com/foo/old/@v/v1.0.0.info":         time.Date(2015, 1, 2, 0, 0, 0, 0, time.UTC),
+		"/github.com/foo/oldmajor/v2/@v/v2.0.0.info": time.Date(2016, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tm, ok := published[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"Version":"v1","Time":%q}`, tm.Format(time.RFC3339))
+	}))
+	defer srv.Close()
+
+	results, err := Analyze(context.Background(), []string{dir}, Options{
+		Finders:     []license.Finder{},
+		Translators: []license.Translator{},
+		MaxAge:      365 * 24 * time.Hour,
+		Versions:    &goproxy.Proxy{Client: srv.Client(), URL: srv.URL},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	// A recent version isn't stale, an old one is, and the pseudo-version
+	// has the date of its commit
+	require.Equal(t, "github.com/foo/new", results[0].Module.Path)
+	require.False(t, results[0].Module.Stale)
+	require.False(t, results[0].Module.Time.IsZero())
+	require.Equal(t, "github.com/foo/old", results[1].Module.Path)
+	require.True(t, results[1].Module.Stale)
+	require.Equal(t, "github.com/foo/oldmajor", results[2].Module.Path)
+	require.True(t, results[2].Module.Stale)
+	require.Equal(t, time.Date(2016, 1, 2, 0, 0, 0, 0, time.UTC), results[2].Module.Time)
+	require.Equal(t, "github.com/foo/pseudo", results[3].Module.Path)
+	require.True(t, results[3].Module.Stale)
+	require.Equal(t, time.Date(2015, 1, 2, 3, 4, 5, 0, time.UTC), results[3].Module.Time)
+}
+
+func TestFinderRegistry(t *testing.T) {
+	configured := &config.Config{
+		Exec: []string{"echo", "MIT"},
+		Raw:  map[string]string{"git.example.com": "https://{host}/{owner}/{repo}/raw/{ref}/LICENSE"},
+	}
+
+	cases := []struct {
+		Name     string
+		Config   *config.Config
+		Opts     FinderOptions
+		Expected []string
+	}{
+		{
+			"default",
+			nil,
+			FinderOptions{},
+			[]string{"override", "local", "pkggodev", "goproxy", "github", "bitbucket"},
+		},
+
+		{
+			"configured",
+			configured,
+			FinderOptions{},
+			[]string{"override", "local", "exec", "pkggodev", "goproxy", "github", "bitbucket", "raw"},
+		},
+
+		{
+			"offline",
+			configured,
+			FinderOptions{Offline: true},
+			[]string{"override", "local", "exec"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			reg := FinderRegistry(context.Background(), tt.Config, tt.Opts)
+			require.Equal(t, []string{
+				"override", "local", "exec", "pkggodev", "goproxy", "github", "bitbucket", "raw",
+			}, reg.Names())
+
+			fs, err := reg.Finders(nil)
+			require.NoError(t, err)
+			var names []string
+			for _, f := range fs {
+				names = append(names, license.FinderName(f))
+			}
+			require.Equal(t, tt.Expected, names)
+		})
+	}
+}
+
+func TestPrivatePatterns(t *testing.T) {
+	t.Setenv("GOPRIVATE", "git.corp.example.com")
+	t.Setenv("GONOSUMDB", "github.com/myorg/*,github.com/other")
+
+	patterns := PrivatePatterns(&config.Config{Private: []string{"example.org/internal"}})
+	require.Equal(t, []string{
+		"git.corp.example.com",
+		"github.com/myorg/*,github.com/other",
+		"example.org/internal",
+	}, patterns)
+
+	mods := []module.Module{
+		{Path: "git.corp.example.com/lib"},
+		{Path: "github.com/myorg/tool"},
+		{Path: "github.com/foo/bar"},
+		{Path: "example.org/internal/pkg"},
+	}
+	private := MarkPrivate(mods, patterns)
+	require.Len(t, private, 3)
+	require.True(t, mods[0].Private)
+	require.True(t, mods[1].Private)
+	require.False(t, mods[2].Private)
+	require.True(t, mods[3].Private)
+}
+
+func TestResolve_notFound(t *testing.T) {
+	var f license.MockFinder
+	f.On("License", mock.Anything, mock.Anything).Return(nil, license.ErrNotFound)
+
+	results := Resolve(context.Background(), []module.Module{{Path: "github.com/foo/bar", Version: "v1.0.0"}}, Options{
+		Config:      &config.Config{Allow: []string{"MIT"}},
+		Finders:     []license.Finder{&f},
+		Translators: []license.Translator{},
+	})
+
+	// A module without a license isn't a failed lookup
+	require.Len(t, results, 1)
+	require.NoError(t, results[0].Err)
+	require.Nil(t, results[0].License)
+	require.Equal(t, config.StateDenied, results[0].State)
+}
@@ -0,0 +1,185 @@
+package analysis
+
+import (
+	"debug/buildinfo"
+	"debug/macho"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rsc/goversion/version"
+
+	"github.com/mitchellh/golicense/module"
+)
+
+// exeFormat is the file format of a binary, used to explain errors.
+type exeFormat string
+
+const (
+	formatUnknown exeFormat = ""
+	formatELF     exeFormat = "ELF"
+	formatPE      exeFormat = "Windows PE"
+	formatMachO   exeFormat = "Mach-O"
+	formatFat     exeFormat = "Mach-O universal"
+)
+
+// detectFormat returns the format of a binary from its first bytes.
+func detectFormat(header []byte) exeFormat {
+	if len(header) < 8 {
+		return formatUnknown
+	}
+
+	switch be, le := binary.BigEndian.Uint32(header), binary.LittleEndian.Uint32(header); {
+	case string(header[:4]) == "\x7fELF":
+		return formatELF
+
+	case string(header[:2]) == "MZ":
+		return formatPE
+
+	case be == macho.Magic32 || be == macho.Magic64 || le == macho.Magic32 || le == macho.Magic64:
+		return formatMachO
+
+	// Java class files have the same magic number. Like file(1), tell
+	// them apart by the number of architectures, which is the class file
+	// version for Java and always far higher.
+	case be == macho.MagicFat && binary.BigEndian.Uint32(header[4:]) < 20:
+		return formatFat
+	}
+
+	return formatUnknown
+}
+
+// readExeModules reads the dependencies of a compiled Go binary. For a
+// macOS universal binary, every architecture is read and the union of
+// their dependencies is returned.
+func readExeModules(path string) ([]module.Module, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(f, header); err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+
+	format := detectFormat(header)
+	if format == formatFat {
+		mods, err := readFatModules(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s binary: %w", format, err)
+		}
+
+		return mods, nil
+	}
+
+	info, err := readExe(path)
+	switch {
+	case err != nil && format == formatUnknown:
+		return nil, fmt.Errorf("unrecognized executable format: %w", err)
+
+	case err != nil:
+		return nil, fmt.Errorf("%s binary: %w", format, err)
+
+	case !hasModuleInfo(info):
+		return nil, ErrNoModuleInfo
+	}
+
+	// From the raw module string from the binary, we need to parse this
+	// into structured data with the module information.
+	return module.ParseExeData(info)
+}
+
+// hasModuleInfo returns true if the build information of a binary lists
+// its main module or a dependency. A binary built in GOPATH mode still has
+// build information, such as the Go version, but neither of these.
+func hasModuleInfo(info string) bool {
+	for _, line := range strings.Split(info, "\n") {
+		if strings.HasPrefix(line, "mod\t") || strings.HasPrefix(line, "dep\t") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readFatModules reads the dependencies of every architecture of a macOS
+// universal binary. The architectures of a universal binary are usually
+// built from the same source, but their dependencies can differ with
+// build constraints.
+func readFatModules(r io.ReaderAt) ([]module.Module, error) {
+	ff, err := macho.NewFatFile(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []module.Module
+	seen := map[module.Module]struct{}{}
+	for _, arch := range ff.Arches {
+		bi, err := buildinfo.Read(io.NewSectionReader(r, int64(arch.Offset), int64(arch.Size)))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", machoArch(arch.Cpu), err)
+		}
+		if len(bi.Deps) == 0 {
+			return nil, fmt.Errorf("%s: %w", machoArch(arch.Cpu), ErrNoModuleInfo)
+		}
+
+		mods, err := module.ParseExeData(bi.String())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", machoArch(arch.Cpu), err)
+		}
+		for _, m := range mods {
+			if _, ok := seen[m]; !ok {
+				seen[m] = struct{}{}
+				result = append(result, m)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// machoArch returns the Go name of a Mach-O CPU type.
+func machoArch(cpu macho.Cpu) string {
+	switch cpu {
+	case macho.CpuAmd64:
+		return "amd64"
+
+	case macho.CpuArm64:
+		return "arm64"
+
+	case macho.Cpu386:
+		return "386"
+
+	case macho.CpuArm:
+		return "arm"
+
+	default:
+		return cpu.String()
+	}
+}
+
+// readExe reads the module information from a compiled Go binary.
+//
+// goversion doesn't recognize every file that carries Go build information,
+// such as Go plugins (-buildmode=plugin) and other shared objects, so if it
+// fails or finds no module information we fall back to the standard
+// library's debug/buildinfo. The module information it returns is in the
+// same format that module.ParseExeData expects.
+func readExe(path string) (string, error) {
+	vsn, err := version.ReadExe(path)
+	if err == nil && vsn.ModuleInfo != "" {
+		return vsn.ModuleInfo, nil
+	}
+
+	bi, biErr := buildinfo.ReadFile(path)
+	if biErr != nil {
+		// Report the original error since that is the primary reader.
+		return "", err
+	}
+
+	return bi.String(), nil
+}
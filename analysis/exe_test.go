@@ -0,0 +1,167 @@
+package analysis
+
+import (
+	"bytes"
+	"debug/macho"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadModules_formats(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds binaries")
+	}
+
+	cases := []struct {
+		Name     string
+		Path     func(t *testing.T) string
+		Format   exeFormat
+		Expected []string
+	}{
+		{
+			"ELF",
+			func(t *testing.T) string { return buildExe(t, "linux", "amd64") },
+			formatELF,
+			[]string{"github.com/davecgh/go-spew"},
+		},
+		{
+			"PE",
+			func(t *testing.T) string { return buildExe(t, "windows", "amd64") },
+			formatPE,
+			[]string{"github.com/davecgh/go-spew"},
+		},
+		{
+			"Mach-O",
+			func(t *testing.T) string { return buildExe(t, "darwin", "arm64") },
+			formatMachO,
+			[]string{"github.com/pmezard/go-difflib"},
+		},
+		{
+			"universal",
+			func(t *testing.T) string {
+				return writeFat(t, map[macho.Cpu]string{
+					macho.CpuAmd64: buildExe(t, "darwin", "amd64"),
+					macho.CpuArm64: buildExe(t, "darwin", "arm64"),
+				})
+			},
+			formatFat,
+			// The union of both architectures
+			[]string{"github.com/davecgh/go-spew", "github.com/pmezard/go-difflib"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			path := tt.Path(t)
+
+			data, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, tt.Format, detectFormat(data))
+
+			mods, err := ReadModules([]string{path})
+			require.NoError(t, err)
+
+			var actual []string
+			for _, m := range mods {
+				actual = append(actual, m.Path)
+			}
+			require.Equal(t, tt.Expected, actual)
+		})
+	}
+}
+
+func TestReadModules_formatErrors(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Data     []byte
+		Expected string
+	}{
+		{
+			"unknown",
+			[]byte("hello"),
+			"unrecognized executable format",
+		},
+		{
+			"truncated PE",
+			[]byte("MZ\x90\x00\x03\x00\x00\x00"),
+			"Windows PE binary",
+		},
+		{
+			"truncated universal",
+			[]byte("\xca\xfe\xba\xbe\x00\x00\x00\x02"),
+			"Mach-O universal binary",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "bin")
+			require.NoError(t, ioutil.WriteFile(path, tt.Data, 0644))
+
+			_, err := ReadModules([]string{path})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), path)
+			require.Contains(t, err.Error(), tt.Expected)
+		})
+	}
+}
+
+// buildExe builds testdata/exe for the given platform and returns the path
+// to the binary.
+func buildExe(t *testing.T, goos, goarch string) string {
+	t.Helper()
+
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not found")
+	}
+
+	path := filepath.Join(t.TempDir(), "exe-"+goos+"-"+goarch)
+	cmd := exec.Command(goBin, "build", "-o", path, "./testdata/exe")
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0", "GOOS="+goos, "GOARCH="+goarch)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	return path
+}
+
+// writeFat writes a macOS universal binary from the given Mach-O binaries
+// and returns its path.
+func writeFat(t *testing.T, slices map[macho.Cpu]string) string {
+	t.Helper()
+
+	const align = 14 // 2^14, like lipo for arm64
+	var header, body bytes.Buffer
+	offset := uint32(8 + 20*len(slices))
+	binary.Write(&header, binary.BigEndian, []uint32{macho.MagicFat, uint32(len(slices))})
+	for _, cpu := range []macho.Cpu{macho.CpuAmd64, macho.CpuArm64} {
+		path, ok := slices[cpu]
+		if !ok {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+
+		// Pad to the alignment of the slice
+		pad := (1<<align - offset%(1<<align)) % (1 << align)
+		body.Write(make([]byte, pad))
+		offset += pad
+
+		binary.Write(&header, binary.BigEndian, []uint32{
+			uint32(cpu), 3, offset, uint32(len(data)), align,
+		})
+		body.Write(data)
+		offset += uint32(len(data))
+	}
+
+	path := filepath.Join(t.TempDir(), "exe-universal")
+	require.NoError(t, ioutil.WriteFile(path, append(header.Bytes(), body.Bytes()...), 0755))
+	return path
+}
@@ -0,0 +1,134 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+// lookupFunc looks up the license of a single module. The module may be
+// modified to add information to the report.
+type lookupFunc func(context.Context, *module.Module) (*license.License, error)
+
+// lookupAll runs f for every module and waits for all of them to complete.
+// The semaphore limits the number of concurrent lookups, and modules still
+// waiting for it when ctx is done fail with its error. Start and Finish
+// are called on the listener for every module and status updates made with
+// the context given to f are routed to it. The results are in the same
+// order as the modules.
+func lookupAll(ctx context.Context, mods []module.Module, l Listener, sem semaphore, f lookupFunc) []Result {
+	if l == nil {
+		l = nopListener{}
+	}
+
+	results := make([]Result, len(mods))
+	var wg sync.WaitGroup
+	for i, m := range mods {
+		wg.Add(1)
+		go func(i int, m module.Module) {
+			defer wg.Done()
+
+			// Acquire a semaphore so that we can limit concurrency. If the
+			// context is cancelled first, the module fails without a lookup.
+			if err := sem.AcquireContext(ctx); err != nil {
+				l.Start(&m)
+				l.Finish(&m, nil, err)
+				results[i] = Result{Module: m, Err: err}
+				return
+			}
+			defer sem.Release()
+
+			// Build the context
+			sl := &statusListener{m: &m, l: l}
+			ctx := license.StatusWithContext(ctx, sl)
+
+			// Lookup. A finder that outlives a timeout may still update
+			// its status, so the listener is closed before Finish.
+			l.Start(&m)
+			lic, err := f(ctx, &m)
+			sl.Close()
+			l.Finish(&m, lic, err)
+
+			// Each goroutine writes its own element so no lock is needed
+			results[i] = Result{Module: m, License: lic, Err: err}
+		}(i, m)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// statusListener is a license.StatusListener implementation that updates
+// the Listener for a single module by calling Update. Updates are dropped
+// once it is closed.
+type statusListener struct {
+	m *module.Module
+	l Listener
+
+	lock   sync.Mutex
+	closed bool
+}
+
+// UpdateStatus implements license.StatusListener
+func (sl *statusListener) UpdateStatus(t license.StatusType, msg string) {
+	sl.lock.Lock()
+	defer sl.lock.Unlock()
+	if sl.closed {
+		return
+	}
+
+	sl.l.Update(sl.m, t, msg)
+}
+
+// Close stops routing updates to the Listener. It waits for an update in
+// progress so that none can reach the Listener after Close returns.
+func (sl *statusListener) Close() {
+	sl.lock.Lock()
+	defer sl.lock.Unlock()
+	sl.closed = true
+}
+
+// nopListener is a Listener that ignores all calls.
+type nopListener struct{}
+
+func (nopListener) Start(*module.Module)                              {}
+func (nopListener) Update(*module.Module, license.StatusType, string) {}
+func (nopListener) Finish(*module.Module, *license.License, error)    {}
+
+// lookupTimeout runs f with a context that is cancelled after the timeout,
+// or with ctx as is if the timeout is zero. If the timeout passes or ctx is
+// done before f returns, an error is returned right away even if f ignores
+// the context, so that a hung request can't block the run.
+func lookupTimeout(ctx context.Context, timeout time.Duration, f func(context.Context) (*license.License, error)) (*license.License, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	type result struct {
+		lic *license.License
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		lic, err := f(ctx)
+		ch <- result{lic, err}
+	}()
+
+	select {
+	case r := <-ch:
+		return r.lic, r.err
+
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("license lookup timed out")
+		}
+
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,212 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupAll(t *testing.T) {
+	var mods []module.Module
+	for i := 0; i < 50; i++ {
+		mods = append(mods, module.Module{
+			Path:    fmt.Sprintf("github.com/foo/mod%d", i),
+			Version: "v1.0.0",
+		})
+	}
+
+	out := &countListener{}
+	results := lookupAll(context.Background(), mods, out, newSemaphore(5),
+		func(ctx context.Context, m *module.Module) (*license.License, error) {
+			return &license.License{Name: "MIT License", SPDX: "MIT"}, nil
+		})
+
+	require.Len(t, out.Started, len(mods))
+	require.Len(t, out.Finished, len(mods))
+	for i, m := range mods {
+		require.Equal(t, 1, out.Finished[m.Path], m.Path)
+		require.Equal(t, m, results[i].Module)
+		require.Equal(t, "MIT", results[i].License.SPDX)
+	}
+}
+
+func TestLookupAll_cancel(t *testing.T) {
+	var mods []module.Module
+	for i := 0; i < 5; i++ {
+		mods = append(mods, module.Module{
+			Path:    fmt.Sprintf("github.com/foo/mod%d", i),
+			Version: "v1.0.0",
+		})
+	}
+
+	// The only slot is held by a lookup that waits for the cancellation,
+	// so the others must give up without it.
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{}, len(mods))
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	out := &countListener{}
+	results := lookupAll(ctx, mods, out, newSemaphore(1),
+		func(ctx context.Context, m *module.Module) (*license.License, error) {
+			started <- struct{}{}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+
+	require.Len(t, out.Finished, len(mods))
+	require.Len(t, started, 0)
+	for i, r := range results {
+		require.Equal(t, mods[i], r.Module)
+		require.Equal(t, context.Canceled, r.Err)
+	}
+}
+
+func TestLookupTimeout(t *testing.T) {
+	t.Run("no timeout", func(t *testing.T) {
+		lic, err := lookupTimeout(context.Background(), 0,
+			func(ctx context.Context) (*license.License, error) {
+				_, ok := ctx.Deadline()
+				require.False(t, ok)
+				return &license.License{SPDX: "MIT"}, nil
+			})
+		require.NoError(t, err)
+		require.Equal(t, "MIT", lic.SPDX)
+	})
+
+	t.Run("cancellation propagates", func(t *testing.T) {
+		cancelled := make(chan struct{})
+		_, err := lookupTimeout(context.Background(), 10*time.Millisecond,
+			func(ctx context.Context) (*license.License, error) {
+				<-ctx.Done()
+				close(cancelled)
+				return nil, ctx.Err()
+			})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "timed out")
+
+		select {
+		case <-cancelled:
+		case <-time.After(5 * time.Second):
+			t.Fatal("finder context wasn't cancelled")
+		}
+	})
+
+	t.Run("finder ignores context", func(t *testing.T) {
+		block := make(chan struct{})
+		defer close(block)
+
+		_, err := lookupTimeout(context.Background(), 10*time.Millisecond,
+			func(ctx context.Context) (*license.License, error) {
+				<-block
+				return nil, nil
+			})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "timed out")
+	})
+}
+
+func TestLookupAll_timeout(t *testing.T) {
+	mods := []module.Module{
+		{Path: "github.com/foo/fast", Version: "v1.0.0"},
+		{Path: "github.com/foo/hung", Version: "v1.0.0"},
+	}
+
+	block := make(chan struct{})
+	updated := make(chan struct{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	out := &countListener{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lookupAll(ctx, mods, out, newSemaphore(2),
+			func(ctx context.Context, m *module.Module) (*license.License, error) {
+				return lookupTimeout(ctx, 0, func(ctx context.Context) (*license.License, error) {
+					if m.Path == "github.com/foo/hung" {
+						<-block
+						license.UpdateStatus(ctx, license.StatusNormal, "still looking")
+						close(updated)
+					}
+
+					return &license.License{SPDX: "MIT"}, nil
+				})
+			})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("lookups didn't complete after the timeout")
+	}
+
+	require.Len(t, out.Finished, len(mods))
+	require.Error(t, out.Errors["github.com/foo/hung"])
+	require.NoError(t, out.Errors["github.com/foo/fast"])
+
+	// The hung finder updating its status after the lookup has finished
+	// must not reach the listener.
+	close(block)
+	select {
+	case <-updated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("hung finder didn't resume")
+	}
+
+	out.Lock()
+	defer out.Unlock()
+	require.Zero(t, out.Late["github.com/foo/hung"])
+}
+
+// countListener is a Listener that counts the calls for every module.
+type countListener struct {
+	sync.Mutex
+	Started  map[string]int
+	Finished map[string]int
+	Errors   map[string]error
+	Late     map[string]int // updates after Finish
+}
+
+func (o *countListener) Start(m *module.Module) {
+	o.Lock()
+	defer o.Unlock()
+	if o.Started == nil {
+		o.Started = map[string]int{}
+	}
+	o.Started[m.Path]++
+}
+
+func (o *countListener) Update(m *module.Module, _ license.StatusType, _ string) {
+	o.Lock()
+	defer o.Unlock()
+	if o.Finished[m.Path] == 0 {
+		return
+	}
+	if o.Late == nil {
+		o.Late = map[string]int{}
+	}
+	o.Late[m.Path]++
+}
+
+func (o *countListener) Finish(m *module.Module, l *license.License, err error) {
+	o.Lock()
+	defer o.Unlock()
+	if o.Finished == nil {
+		o.Finished = map[string]int{}
+		o.Errors = map[string]error{}
+	}
+	o.Finished[m.Path]++
+	if err != nil {
+		o.Errors[m.Path] = err
+	}
+}
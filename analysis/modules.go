@@ -0,0 +1,197 @@
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/mitchellh/golicense/module"
+)
+
+// ErrNoModuleInfo is returned, wrapped in a *ReadError, for a binary that
+// was compiled without Go modules or has no dependencies. We can't tell
+// the two apart, so this is treated as an error.
+var ErrNoModuleInfo = errors.New("binary has no module information")
+
+// ReadError is returned by ReadModules if the dependencies of a path can't
+// be read.
+type ReadError struct {
+	Path string // binary or go.mod file
+	Err  error
+}
+
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("error reading %q: %s", e.Path, e.Err)
+}
+
+func (e *ReadError) Unwrap() error {
+	return e.Err
+}
+
+// ReadModules reads the dependencies of the given binaries. A directory or
+// go.mod file is analyzed from source instead. Modules that several paths
+// depend on are only returned once, and the modules are sorted by path and
+// then version.
+//
+// The paths are read concurrently since reading large binaries is mostly
+// I/O. If several paths can't be read, the error of the first one is
+// returned.
+func ReadModules(paths []string) ([]module.Module, error) {
+	mods, _, err := readAllModules(paths, runtime.NumCPU(), false)
+	return mods, err
+}
+
+// ReadModulesSkipping is like ReadModules, but a binary without module
+// information (ErrNoModuleInfo) is skipped instead of failing the whole
+// batch, as long as another path could be read. The errors of the skipped
+// binaries are returned in the order of the paths so that they can be
+// reported.
+func ReadModulesSkipping(paths []string) ([]module.Module, []*ReadError, error) {
+	return readAllModules(paths, runtime.NumCPU(), true)
+}
+
+// readAllModules implements ReadModules and ReadModulesSkipping, reading up
+// to parallel paths at the same time.
+func readAllModules(paths []string, parallel int, skipNoInfo bool) ([]module.Module, []*ReadError, error) {
+	var (
+		all  = map[module.Module]struct{}{}
+		errs = make([]error, len(paths))
+		lock sync.Mutex
+		wg   sync.WaitGroup
+	)
+	sem := newSemaphore(parallel)
+	for i, path := range paths {
+		sem.Acquire()
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+			defer sem.Release()
+
+			mods, err := readModules(path)
+
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			for _, mod := range mods {
+				all[mod] = struct{}{}
+			}
+		}(i, path)
+	}
+	wg.Wait()
+
+	// Binaries without module information are skipped, unless no path
+	// could be read at all since then there is nothing to check.
+	var skipped []*ReadError
+	if skipNoInfo {
+		for i, err := range errs {
+			if rerr, ok := err.(*ReadError); ok && errors.Is(rerr.Err, ErrNoModuleInfo) {
+				skipped = append(skipped, rerr)
+				errs[i] = nil
+			}
+		}
+		if len(skipped) == len(paths) {
+			return nil, nil, skipped[0]
+		}
+	}
+
+	// Report errors in the order of the paths, not in the order they
+	// happened to be read.
+	for _, err := range errs {
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	result := make([]module.Module, 0, len(all))
+	for mod := range all {
+		result = append(result, mod)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Path != result[j].Path {
+			return result[i].Path < result[j].Path
+		}
+
+		return result[i].Version < result[j].Version
+	})
+
+	return result, skipped, nil
+}
+
+// readModules reads the dependencies of a single binary or module.
+func readModules(path string) ([]module.Module, error) {
+	if goMod, ok := GoModPath(path); ok {
+		mods, err := readGoMod(goMod)
+		if err != nil {
+			return nil, &ReadError{Path: goMod, Err: err}
+		}
+
+		return mods, nil
+	}
+
+	// Read the dependencies from the binary itself
+	mods, err := readExeModules(path)
+	if err != nil {
+		return nil, &ReadError{Path: path, Err: err}
+	}
+
+	return mods, nil
+}
+
+// GoModPath returns the path to the go.mod file if the given path is a
+// go.mod file or a directory, meaning that the module source should be
+// analyzed rather than a binary.
+func GoModPath(path string) (string, bool) {
+	if filepath.Base(path) == "go.mod" {
+		return path, true
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil || !fi.IsDir() {
+		return "", false
+	}
+
+	return filepath.Join(path, "go.mod"), true
+}
+
+// readGoMod reads the modules required by the given go.mod file, with the
+// hashes from the go.sum file next to it if it exists. Modules replaced by
+// a local directory have the absolute path of the directory as their path,
+// so that its license files can be found regardless of the working
+// directory.
+func readGoMod(path string) ([]module.Module, error) {
+	gomod, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	gosum, err := ioutil.ReadFile(filepath.Join(dir, "go.sum"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	mods, err := module.ParseGoMod(string(gomod), string(gosum))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, m := range mods {
+		if m.IsLocal() && !filepath.IsAbs(m.Path) {
+			mods[i].Path = filepath.Join(dir, filepath.FromSlash(m.Path))
+		}
+	}
+
+	return mods, nil
+}
@@ -0,0 +1,50 @@
+package analysis
+
+import "context"
+
+// semaphore is a thin wrapper around a channel for using it as a semaphore.
+type semaphore chan struct{}
+
+// newSemaphore creates a semaphore that allows up  to a given limit of
+// simultaneous acquisitions
+func newSemaphore(n int) semaphore {
+	if n == 0 {
+		panic("semaphore with limit 0")
+	}
+
+	ch := make(chan struct{}, n)
+	return semaphore(ch)
+}
+
+// Acquire is used to acquire an available slot. Blocks until available.
+func (s semaphore) Acquire() {
+	s <- struct{}{}
+}
+
+// AcquireContext is like Acquire but returns the error of the context
+// instead if it is done before a slot is available, so that a cancelled run
+// doesn't wait for the slots of lookups that are still running.
+func (s semaphore) AcquireContext(ctx context.Context) error {
+	select {
+	case s <- struct{}{}:
+		// A done context wins even if a slot was available too, since
+		// select picks either at random.
+		if err := ctx.Err(); err != nil {
+			s.Release()
+			return err
+		}
+		return nil
+
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release is used to return a slot. Acquire must be called as a pre-condition.
+func (s semaphore) Release() {
+	select {
+	case <-s:
+	default:
+		panic("release without an acquire")
+	}
+}
@@ -0,0 +1,40 @@
+package analysis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemaphoreAcquireContext(t *testing.T) {
+	sem := newSemaphore(1)
+	require.NoError(t, sem.AcquireContext(context.Background()))
+
+	// Cancelling the context unblocks a waiting acquirer
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- sem.AcquireContext(ctx) }()
+
+	select {
+	case err := <-errCh:
+		t.Fatalf("acquired a full semaphore: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	cancel()
+	select {
+	case err := <-errCh:
+		require.Equal(t, context.Canceled, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("acquirer not unblocked")
+	}
+
+	// The slot is still held, and a done context never takes it
+	require.Equal(t, context.Canceled, sem.AcquireContext(ctx))
+	sem.Release()
+	require.Equal(t, context.Canceled, sem.AcquireContext(ctx))
+	require.NoError(t, sem.AcquireContext(context.Background()))
+	sem.Release()
+}
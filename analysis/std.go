@@ -0,0 +1,71 @@
+package analysis
+
+import (
+	"debug/buildinfo"
+	"sort"
+	"strings"
+
+	"github.com/rsc/goversion/version"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+// StdPath is the module path of the synthetic module for the Go standard
+// library. The standard library isn't a dependency in the module
+// information of a binary, but it is compiled into every binary.
+const StdPath = "std"
+
+// StdLicense is the license of the Go standard library.
+var StdLicense = license.License{
+	Name:   `BSD 3-Clause "New" or "Revised" License`,
+	SPDX:   "BSD-3-Clause",
+	URL:    "https://go.dev/LICENSE",
+	Source: "std",
+}
+
+// StdModules returns a synthetic module for the standard library of each
+// Go version that the binaries were built with, such as "std" at version
+// "go1.21.0", sorted by version. Paths without a Go version, such as
+// go.mod files and module directories, are ignored.
+func StdModules(paths []string) []module.Module {
+	seen := map[string]struct{}{}
+	var result []module.Module
+	for _, path := range paths {
+		if _, ok := GoModPath(path); ok {
+			continue
+		}
+
+		v := goVersion(path)
+		if _, ok := seen[v]; ok || v == "" {
+			continue
+		}
+		seen[v] = struct{}{}
+		result = append(result, module.Module{Path: StdPath, Version: v})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		// Go versions compare like semantic versions, apart from the prefix
+		c, ok := module.CompareVersions(
+			"v"+strings.TrimPrefix(result[i].Version, "go"),
+			"v"+strings.TrimPrefix(result[j].Version, "go"))
+		if !ok {
+			return result[i].Version < result[j].Version
+		}
+		return c < 0
+	})
+
+	return result
+}
+
+// goVersion returns the Go version a binary was built with, such as
+// "go1.21.0", or "" if it can't be read.
+func goVersion(path string) string {
+	if vsn, err := version.ReadExe(path); err == nil && vsn.Release != "" {
+		return vsn.Release
+	}
+	if bi, err := buildinfo.ReadFile(path); err == nil {
+		return bi.GoVersion
+	}
+
+	return ""
+}
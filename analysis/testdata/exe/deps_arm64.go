@@ -0,0 +1,9 @@
+package main
+
+import "github.com/pmezard/go-difflib/difflib"
+
+// deps depends on a different module on arm64, so that the architectures
+// of a universal binary have different dependencies.
+func deps() interface{} {
+	return difflib.SplitLines("foo\nbar")
+}
@@ -0,0 +1,9 @@
+//go:build !arm64
+
+package main
+
+import "github.com/davecgh/go-spew/spew"
+
+func deps() interface{} {
+	return spew.Sdump("foo")
+}
@@ -0,0 +1,9 @@
+// Command exe is built by the tests for every binary format. It only
+// exists to depend on a module, which differs between architectures.
+package main
+
+import "fmt"
+
+func main() {
+	fmt.Println(deps())
+}
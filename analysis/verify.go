@@ -0,0 +1,44 @@
+package analysis
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/mitchellh/golicense/module"
+)
+
+// SumMismatch is a module whose hash doesn't match the hash in go.sum. This
+// means that the module was changed after go.sum was written, such as by a
+// tampered proxy or a replaced module.
+type SumMismatch struct {
+	Module module.Module
+	Sum    string // hash in go.sum
+}
+
+func (m SumMismatch) String() string {
+	return fmt.Sprintf("%s has hash %s, go.sum has %s", m.Module.String(), m.Module.Hash, m.Sum)
+}
+
+// VerifySum compares the hashes of the modules with the hashes in the given
+// go.sum file and returns the modules that don't match. Modules without a
+// hash, such as local replacements, and modules that aren't in go.sum are
+// skipped since there is nothing to compare.
+func VerifySum(mods []module.Module, path string) ([]SumMismatch, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sums := module.ParseGoSum(string(data))
+	var result []SumMismatch
+	for _, m := range mods {
+		sum, ok := sums[m.SumKey()]
+		if !ok || m.Hash == "" || m.Hash == sum {
+			continue
+		}
+
+		result = append(result, SumMismatch{Module: m, Sum: sum})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,65 @@
+package analysis
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mitchellh/golicense/module"
+)
+
+func TestVerifySum(t *testing.T) {
+	mods := []module.Module{
+		{
+			Path:    "github.com/fatih/color",
+			Version: "v1.7.0",
+			Hash:    "h1:DkWD4oS2D8LGGgTQ6IvwJJXSL5Vp2ffcQg58nFV38Ys=",
+		},
+		{Path: "github.com/foo/local"},
+	}
+
+	cases := []struct {
+		Name     string
+		GoSum    string
+		Expected []SumMismatch
+	}{
+		{
+			"matching",
+			"github.com/fatih/color v1.7.0 h1:DkWD4oS2D8LGGgTQ6IvwJJXSL5Vp2ffcQg58nFV38Ys=\n",
+			nil,
+		},
+
+		{
+			"mismatch",
+			"github.com/fatih/color v1.7.0 h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=\n",
+			[]SumMismatch{{
+				Module: mods[0],
+				Sum:    "h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=",
+			}},
+		},
+
+		{
+			"not in go.sum",
+			"github.com/foo/bar v1.0.0 h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=\n",
+			nil,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "go.sum")
+			require.NoError(t, ioutil.WriteFile(path, []byte(tt.GoSum), 0644))
+
+			actual, err := VerifySum(mods, path)
+			require.NoError(t, err)
+			require.Equal(t, tt.Expected, actual)
+		})
+	}
+}
+
+func TestVerifySum_missing(t *testing.T) {
+	_, err := VerifySum(nil, filepath.Join(t.TempDir(), "go.sum"))
+	require.Error(t, err)
+}
@@ -0,0 +1,577 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+type moduleVersionLicense struct {
+	Version    string    `json:"version,omitempty"`
+	License    string    `json:"license,omitempty"`
+	SPDX       string    `json:"spdx,omitempty"`
+	URL        string    `json:"url,omitempty"`
+	Source     string    `json:"source,omitempty"`
+	Confidence float64   `json:"confidence,omitempty"`
+	Hash       string    `json:"hash,omitempty"`
+	Created    time.Time `json:"created,omitempty"`
+	LastUsed   time.Time `json:"used,omitempty"`
+
+	// Negative is true if no license was found for the version. These
+	// entries expire after the Cache's NegativeTTL.
+	Negative bool `json:"negative,omitempty"`
+}
+type cachedModule struct {
+	Path   string                 `json:"path,omitempty"`
+	VerLic []moduleVersionLicense `json:"verlic,omitempty"`
+}
+
+// cacheVersion is the version of the cache file format that is written.
+// It must be incremented whenever the meaning of the fields changes, with
+// a migration added to migrateCache.
+//
+// Version 0 files were written before the format was versioned. They
+// didn't record modules without a license.
+const cacheVersion = 1
+
+type cacheFile struct {
+	Version int `json:"version"`
+	Modules []cachedModule
+}
+
+// Cache records the license found for every module version, so that
+// modules aren't looked up again on the next run. It is safe for
+// concurrent use by the license lookups.
+//
+// The cache can be layered on top of read-only base caches. These are
+// consulted after the cache itself and are never written.
+type Cache struct {
+	// TTL is how long cached licenses are valid. Older licenses are
+	// looked up again. Zero means they never expire.
+	TTL time.Duration
+
+	// Retention is how long licenses are kept in the cache without being
+	// used. Zero means they are kept forever.
+	Retention time.Duration
+
+	// NegativeTTL is how long it is cached that no license was found for
+	// a module, which is usually shorter than TTL since a license may be
+	// added upstream. Zero means these modules are always looked up again.
+	NegativeTTL time.Duration
+
+	// Log, if set, receives diagnostic messages about reading the cache
+	// files.
+	Log *Logger
+
+	lock  sync.Mutex
+	data  cacheFile
+	index map[string]int          // module path to index in data.Modules
+	base  map[string]cachedModule // union of the base layers
+}
+
+// Load reads the cache from the given file, replacing its contents. A
+// missing file results in an empty cache that will be created by Save. If
+// the file can't be read the cache is also empty and the error is returned.
+func (c *Cache) Load(fn string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	cf, err := readCacheFile(c.Log, fn)
+	c.data = mergeCache(cf)
+	c.index = map[string]int{}
+	for i, cm := range c.data.Modules {
+		c.index[cm.Path] = i
+	}
+
+	return err
+}
+
+// LoadBase reads the given cache files as read-only base layers. Files
+// that can't be read are skipped and their errors are returned.
+func (c *Cache) LoadBase(fns []string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.base == nil {
+		c.base = map[string]cachedModule{}
+	}
+
+	var rerr error
+	for _, fn := range fns {
+		cf, err := readCacheFile(c.Log, fn)
+		if err != nil {
+			rerr = multierror.Append(rerr, err)
+			continue
+		}
+
+		for _, cc := range cf.Modules {
+			base := c.base[cc.Path]
+			base.Path = cc.Path
+			for _, vv := range cc.VerLic {
+				base.VerLic = mergeVersion(base.VerLic, vv)
+			}
+			c.base[cc.Path] = base
+		}
+	}
+
+	return rerr
+}
+
+// Save writes the cache to the given file, leaving out everything that is
+// already covered by a base layer and everything that wasn't used within
+// the retention. The same entries always result in the same file, see
+// sortCache.
+func (c *Cache) Save(fn string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	cf := sortCache(c.withoutBase(c.prune(c.data, time.Now())))
+	cf.Version = cacheVersion
+	content, err := json.Marshal(cf)
+	if err != nil {
+		return err
+	}
+	if isGzipCache(fn) {
+		if content, err = gzipData(content); err != nil {
+			return err
+		}
+	}
+
+	return ioutil.WriteFile(fn, content, 0644)
+}
+
+// hashMismatchError is returned when the hash of a cached module version
+// differs from the hash of the module in the binary. The cached license
+// can't be trusted in that case.
+type hashMismatchError struct {
+	Module module.Module
+	Cached string
+}
+
+func (e *hashMismatchError) Error() string {
+	return fmt.Sprintf("hash mismatch for %s: cache has %q, binary has %q",
+		e.Module.String(), e.Cached, e.Module.Hash)
+}
+
+// Find returns the license of the module from the cache or a base layer.
+// If it isn't cached, or has expired, find is called and the license it
+// returns is stored in the cache. If find returns license.ErrNotFound, or
+// neither a license nor an error, that is stored too with a NegativeTTL.
+// Other errors are never cached.
+func (c *Cache) Find(m module.Module, find func() (*license.License, error)) (*license.License, error) {
+	lic, ok, err := c.Lookup(m)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return lic, nil
+	}
+
+	lic, err = find()
+	switch {
+	case lic != nil && err == nil:
+		c.Store(m, lic)
+
+	case lic == nil && (err == nil || errors.Is(err, license.ErrNotFound)) && c.NegativeTTL > 0:
+		c.Store(m, nil)
+	}
+
+	return lic, err
+}
+
+// Lookup returns the cached license of the module, from the cache itself
+// or a base layer, and marks it as used. Expired licenses aren't returned.
+// If the module version is cached with a different hash, a
+// *hashMismatchError is returned. For a module that is cached without a
+// license, the license is nil and ok is true.
+func (c *Cache) Lookup(m module.Module) (*license.License, bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if i, ok := c.index[m.Path]; ok {
+		vls := c.data.Modules[i].VerLic
+		for j, vv := range vls {
+			if vv.Version != m.Version {
+				continue
+			}
+
+			if vv.Hash != m.Hash {
+				return nil, false, &hashMismatchError{Module: m, Cached: vv.Hash}
+			}
+			if c.expired(vv) {
+				// A base layer may have been refreshed more recently
+				break
+			}
+
+			vls[j].LastUsed = time.Now()
+			return vv.license(), true, nil
+		}
+	}
+
+	for _, vv := range c.base[m.Path].VerLic {
+		if vv.Version != m.Version {
+			continue
+		}
+
+		if vv.Hash != m.Hash {
+			return nil, false, &hashMismatchError{Module: m, Cached: vv.Hash}
+		}
+		if c.expired(vv) {
+			return nil, false, nil
+		}
+
+		return vv.license(), true, nil
+	}
+
+	return nil, false, nil
+}
+
+// Store adds the license found for the module to the cache, replacing the
+// license cached for the same version. A nil license records that no
+// license was found.
+func (c *Cache) Store(m module.Module, lic *license.License) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+	vl := moduleVersionLicense{
+		Version:  m.Version,
+		Hash:     m.Hash,
+		Created:  now,
+		LastUsed: now,
+		Negative: lic == nil,
+	}
+	if lic != nil {
+		vl.License = lic.Name
+		vl.SPDX = lic.SPDX
+		vl.URL = lic.URL
+		vl.Source = lic.Source
+		vl.Confidence = lic.Confidence
+	}
+
+	if c.index == nil {
+		c.index = map[string]int{}
+	}
+	i, ok := c.index[m.Path]
+	if !ok {
+		i = len(c.data.Modules)
+		c.index[m.Path] = i
+		c.data.Modules = append(c.data.Modules, cachedModule{Path: m.Path})
+	}
+	c.data.Modules[i].VerLic = mergeVersion(c.data.Modules[i].VerLic, vl)
+}
+
+// readonlyCache is the cache for -cache-readonly. It records the modules
+// that had to be looked up because they weren't cached.
+type readonlyCache struct {
+	*Cache
+
+	lock    sync.Mutex
+	missing []module.Module
+}
+
+// Find implements analysis.Cache
+func (c *readonlyCache) Find(m module.Module, find func() (*license.License, error)) (*license.License, error) {
+	return c.Cache.Find(m, func() (*license.License, error) {
+		c.lock.Lock()
+		c.missing = append(c.missing, m)
+		c.lock.Unlock()
+
+		return find()
+	})
+}
+
+// Missing returns the modules that weren't cached. This is safe to call
+// on a nil cache.
+func (c *readonlyCache) Missing() []module.Module {
+	if c == nil {
+		return nil
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.missing
+}
+
+// expired returns true if the cached license is older than the TTL, or
+// the NegativeTTL if no license was found.
+func (c *Cache) expired(vl moduleVersionLicense) bool {
+	if vl.Negative {
+		return time.Since(vl.Created) > c.NegativeTTL
+	}
+
+	return c.TTL > 0 && time.Since(vl.Created) > c.TTL
+}
+
+// license returns the cached license, nil if no license was found.
+func (vl moduleVersionLicense) license() *license.License {
+	if vl.Negative {
+		return nil
+	}
+
+	return &license.License{
+		Name:       vl.License,
+		SPDX:       vl.SPDX,
+		URL:        vl.URL,
+		Source:     vl.Source,
+		Confidence: vl.Confidence,
+	}
+}
+
+// prune returns the cache without the versions that haven't been used
+// within the retention of now. Versions without a recorded last use are
+// kept since their age is unknown.
+func (c *Cache) prune(cf cacheFile, now time.Time) cacheFile {
+	if c.Retention <= 0 {
+		return cf
+	}
+
+	var result cacheFile
+	for _, cm := range cf.Modules {
+		next := cachedModule{Path: cm.Path}
+		for _, vv := range cm.VerLic {
+			if vv.LastUsed.IsZero() || now.Sub(vv.LastUsed) <= c.Retention {
+				next.VerLic = append(next.VerLic, vv)
+			}
+		}
+
+		if len(next.VerLic) > 0 {
+			result.Modules = append(result.Modules, next)
+		}
+	}
+
+	return result
+}
+
+// withoutBase returns the cache with all versions removed that are already
+// covered by a base layer, so that the writable layer only holds the delta.
+func (c *Cache) withoutBase(cf cacheFile) cacheFile {
+	var result cacheFile
+	for _, cm := range cf.Modules {
+		base := c.base[cm.Path]
+
+		next := cachedModule{Path: cm.Path}
+		for _, vv := range cm.VerLic {
+			covered := false
+			for _, bv := range base.VerLic {
+				if bv.Version == vv.Version && !c.expired(bv) {
+					covered = true
+					break
+				}
+			}
+
+			if !covered {
+				next.VerLic = append(next.VerLic, vv)
+			}
+		}
+
+		if len(next.VerLic) > 0 {
+			result.Modules = append(result.Modules, next)
+		}
+	}
+
+	return result
+}
+
+// sortCache returns a copy of the cache sorted by module path and then by
+// version, with all times in UTC and truncated to the second. Modules are
+// stored in the order their lookups finish, which differs between runs, so
+// this keeps committed cache files from changing when their entries don't.
+func sortCache(cf cacheFile) cacheFile {
+	result := cacheFile{Version: cf.Version, Modules: make([]cachedModule, len(cf.Modules))}
+	for i, cm := range cf.Modules {
+		vls := make([]moduleVersionLicense, len(cm.VerLic))
+		for j, vv := range cm.VerLic {
+			vv.Created = cacheTime(vv.Created)
+			vv.LastUsed = cacheTime(vv.LastUsed)
+			vls[j] = vv
+		}
+		sort.Slice(vls, func(a, b int) bool {
+			if c, ok := module.CompareVersions(vls[a].Version, vls[b].Version); ok && c != 0 {
+				return c < 0
+			}
+
+			return vls[a].Version < vls[b].Version
+		})
+
+		result.Modules[i] = cachedModule{Path: cm.Path, VerLic: vls}
+	}
+	sort.Slice(result.Modules, func(i, j int) bool {
+		return result.Modules[i].Path < result.Modules[j].Path
+	})
+
+	return result
+}
+
+// cacheTime returns the time as it is written to a cache file: in UTC and
+// truncated to the second, so that it is always formatted the same way.
+func cacheTime(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+
+	return t.UTC().Truncate(time.Second)
+}
+
+// readCacheFile reads a cache file. A missing file is an empty cache.
+func readCacheFile(log *Logger, fn string) (cacheFile, error) {
+	var result cacheFile
+	data, err := ioutil.ReadFile(fn)
+	if os.IsNotExist(err) {
+		log.Debugf("No cache file %s, a new one will be created", fn)
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+
+	result, err = decodeCacheFile(log, fn, data)
+	if err != nil {
+		return cacheFile{}, err
+	}
+
+	log.Debugf("Loaded %d modules from cache file %s", len(result.Modules), fn)
+	return result, nil
+}
+
+// decodeCacheFile parses the contents of a cache file and migrates it to
+// the current version. Files written by a newer version of golicense are
+// an error since their fields may mean something else.
+func decodeCacheFile(log *Logger, fn string, data []byte) (cacheFile, error) {
+	var result cacheFile
+	if isGzipCache(fn) {
+		var err error
+		if data, err = gunzipData(data); err != nil {
+			return result, fmt.Errorf("%s: %s", fn, err)
+		}
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, fmt.Errorf("%s: %s", fn, err)
+	}
+	if result.Version > cacheVersion {
+		return cacheFile{}, fmt.Errorf(
+			"%s: cache file version %d is newer than the supported version %d",
+			fn, result.Version, cacheVersion)
+	}
+
+	if result.Version < cacheVersion {
+		log.Infof("Migrating cache file %s from version %d to %d",
+			fn, result.Version, cacheVersion)
+		result = migrateCache(result)
+	}
+
+	return result, nil
+}
+
+// isGzipCache returns true if the cache file is compressed with gzip,
+// which is the case if its name ends in ".gz".
+func isGzipCache(fn string) bool {
+	return strings.HasSuffix(strings.ToLower(fn), ".gz")
+}
+
+// gzipData compresses data with gzip. The header has no name or time, so
+// the same data always results in the same bytes.
+func gzipData(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// gunzipData decompresses gzip data.
+func gunzipData(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ioutil.ReadAll(r)
+}
+
+// migrateCache migrates a cache file to the current version, one version
+// at a time.
+func migrateCache(cf cacheFile) cacheFile {
+	for cf.Version < cacheVersion {
+		switch cf.Version {
+		case 0:
+			// Version 0 had no negative entries, so a version without a
+			// license was a license without a name that would never be
+			// looked up again. Treat it as not found so it expires.
+			for i := range cf.Modules {
+				for j, vv := range cf.Modules[i].VerLic {
+					if vv.License == "" && vv.SPDX == "" {
+						cf.Modules[i].VerLic[j].Negative = true
+					}
+				}
+			}
+		}
+
+		cf.Version++
+	}
+
+	return cf
+}
+
+// mergeCache merges all the modules with the same path into a single
+// module, keeping the order in which paths first appear. Duplicate versions
+// are merged with mergeVersion.
+func mergeCache(cf cacheFile) cacheFile {
+	result := cacheFile{Version: cf.Version}
+	index := map[string]int{}
+	for _, cm := range cf.Modules {
+		i, ok := index[cm.Path]
+		if !ok {
+			i = len(result.Modules)
+			index[cm.Path] = i
+			result.Modules = append(result.Modules, cachedModule{Path: cm.Path})
+		}
+
+		for _, vv := range cm.VerLic {
+			result.Modules[i].VerLic = mergeVersion(result.Modules[i].VerLic, vv)
+		}
+	}
+
+	return result
+}
+
+// mergeVersion adds the version to the list. If the version is already
+// present, the most recently created entry is kept and the latest LastUsed
+// of the two is used.
+func mergeVersion(vls []moduleVersionLicense, vl moduleVersionLicense) []moduleVersionLicense {
+	for i, v := range vls {
+		if v.Version != vl.Version {
+			continue
+		}
+
+		lastUsed := v.LastUsed
+		if vl.LastUsed.After(lastUsed) {
+			lastUsed = vl.LastUsed
+		}
+		if vl.Created.After(v.Created) {
+			vls[i] = vl
+		}
+		vls[i].LastUsed = lastUsed
+		return vls
+	}
+
+	return append(vls, vl)
+}
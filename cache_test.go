@@ -0,0 +1,469 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/golicense/analysis"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheLoad_duplicates(t *testing.T) {
+	var c Cache
+	require.NoError(t, c.Load(filepath.Join("testdata", "cache-duplicates.json")))
+
+	// The duplicate paths are merged into a single module
+	require.Len(t, c.data.Modules, 2)
+	require.Equal(t, "github.com/foo/bar", c.data.Modules[0].Path)
+	require.Equal(t, "github.com/foo/baz", c.data.Modules[1].Path)
+
+	// No version is lost and the duplicate version is merged
+	bar := c.data.Modules[c.index["github.com/foo/bar"]]
+	require.Len(t, bar.VerLic, 2)
+	require.Equal(t, "v1.0.0", bar.VerLic[0].Version)
+	require.Equal(t, "v1.1.0", bar.VerLic[1].Version)
+
+	// The most recent creation wins, using the latest last use
+	created, _ := time.Parse(time.RFC3339, "2022-09-19T19:17:08Z")
+	used, _ := time.Parse(time.RFC3339, "2022-09-22T10:00:00Z")
+	require.True(t, created.Equal(bar.VerLic[0].Created))
+	require.True(t, used.Equal(bar.VerLic[0].LastUsed))
+}
+
+func TestCacheLoad_missing(t *testing.T) {
+	// Capture stdout, which must stay clean for machine-readable output
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	var c Cache
+	loadErr := c.Load(filepath.Join(t.TempDir(), "cache.json"))
+	os.Stdout = stdout
+	require.NoError(t, w.Close())
+	out, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+
+	require.NoError(t, loadErr)
+	require.Empty(t, c.data.Modules)
+	require.Empty(t, string(out))
+}
+
+func TestCacheLoad_corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte("{not json"), 0644))
+
+	var log bytes.Buffer
+	c := Cache{Log: &Logger{Out: &log, Level: LogDebug}}
+	require.Error(t, c.Load(path))
+	require.Empty(t, c.data.Modules)
+
+	// The cache is still usable and is replaced on save
+	c.Store(module.Module{Path: "github.com/foo/bar", Version: "v1.0.0"},
+		&license.License{Name: "MIT License", SPDX: "MIT"})
+	require.NoError(t, c.Save(path))
+	require.NoError(t, c.Load(path))
+	require.Len(t, c.data.Modules, 1)
+	require.Contains(t, log.String(), "Loaded 1 modules")
+}
+
+func TestCacheLoad_v0(t *testing.T) {
+	var log bytes.Buffer
+	c := Cache{Log: &Logger{Out: &log, Level: LogInfo}}
+	require.NoError(t, c.Load(filepath.Join("testdata", "cache-v0.json")))
+	require.Equal(t, cacheVersion, c.data.Version)
+	require.Contains(t, log.String(), "from version 0 to 1")
+
+	// Licenses are kept as they are
+	lic, ok, err := c.Lookup(module.Module{Path: "github.com/foo/bar", Version: "v1.0.0", Hash: "h1:bar="})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, &license.License{Name: "MIT License", SPDX: "MIT"}, lic)
+
+	// A version without a license is not found, so it expires
+	m := module.Module{Path: "github.com/foo/bar", Version: "v1.1.0", Hash: "h1:bar11="}
+	_, ok, err = c.Lookup(m)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.True(t, c.data.Modules[0].VerLic[1].Negative)
+
+	// The migrated cache is saved with the current version
+	path := filepath.Join(t.TempDir(), "cache.json")
+	require.NoError(t, c.Save(path))
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), fmt.Sprintf(`"version":%d`, cacheVersion))
+
+	log.Reset()
+	require.NoError(t, c.Load(path))
+	require.NotContains(t, log.String(), "Migrating")
+}
+
+func TestCacheLoad_newerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(fmt.Sprintf(
+		`{"version":%d,"Modules":[{"path":"github.com/foo/bar"}]}`, cacheVersion+1)), 0644))
+
+	var c Cache
+	err := c.Load(path)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "newer than the supported version")
+	require.Empty(t, c.data.Modules)
+}
+
+func TestCache_storeSave(t *testing.T) {
+	var c Cache
+	m := module.Module{Path: "github.com/foo/bar", Version: "v1.0.0", Hash: "h1:bar="}
+
+	_, ok, err := c.Lookup(m)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	c.Store(m, &license.License{Name: "MIT License", SPDX: "MIT"})
+	lic, ok, err := c.Lookup(m)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, &license.License{Name: "MIT License", SPDX: "MIT"}, lic)
+
+	// Storing the same version again replaces the license
+	c.Store(m, &license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0"})
+	path := filepath.Join(t.TempDir(), "cache.json")
+	require.NoError(t, c.Save(path))
+
+	var loaded Cache
+	loaded.Load(path)
+	require.Len(t, loaded.data.Modules, 1)
+	require.Len(t, loaded.data.Modules[0].VerLic, 1)
+	lic, ok, err = loaded.Lookup(m)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "Apache-2.0", lic.SPDX)
+}
+
+func TestCache_concurrent(t *testing.T) {
+	// Start with half the modules already in the cache
+	var c Cache
+	c.Load(filepath.Join("testdata", "cache-duplicates.json"))
+
+	mods := []module.Module{
+		{Path: "github.com/foo/bar", Version: "v1.0.0", Hash: "h1:one="},
+		{Path: "github.com/foo/baz", Version: "v0.1.0", Hash: "h1:baz="},
+	}
+	for i := 0; i < 50; i++ {
+		mods = append(mods, module.Module{
+			Path:    fmt.Sprintf("github.com/foo/mod%d", i),
+			Version: "v1.0.0",
+		})
+	}
+
+	var f license.MockFinder
+	f.On("License", mock.Anything, mock.Anything).Return(
+		&license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+	analysis.Resolve(context.Background(), mods, analysis.Options{
+		Finders:     []license.Finder{&f},
+		Translators: []license.Translator{},
+		Cache:       &c,
+		Parallel:    10,
+	})
+
+	// Every module must have made it to the cache file
+	path := filepath.Join(t.TempDir(), "cache.json")
+	require.NoError(t, c.Save(path))
+
+	var loaded Cache
+	loaded.Load(path)
+	for _, m := range mods {
+		_, ok, err := loaded.Lookup(m)
+		require.NoError(t, err)
+		require.True(t, ok, m.Path)
+	}
+}
+
+func TestCacheLookup_hashMismatch(t *testing.T) {
+	var c Cache
+	c.Load(filepath.Join("testdata", "cache-duplicates.json"))
+
+	_, ok, err := c.Lookup(module.Module{
+		Path:    "github.com/foo/bar",
+		Version: "v1.0.0",
+		Hash:    "h1:other=",
+	})
+	require.False(t, ok)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `cache has "h1:one=", binary has "h1:other="`)
+
+	lic, ok, err := c.Lookup(module.Module{
+		Path:    "github.com/foo/bar",
+		Version: "v1.0.0",
+		Hash:    "h1:one=",
+	})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "MIT", lic.SPDX)
+}
+
+func TestCacheLookup_base(t *testing.T) {
+	var c Cache
+	c.LoadBase([]string{filepath.Join("testdata", "cache-duplicates.json")})
+
+	m := module.Module{Path: "github.com/foo/baz", Version: "v0.1.0", Hash: "h1:baz="}
+	_, ok, err := c.Lookup(m)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// Modules found in a base layer aren't written
+	c.Store(m, &license.License{Name: "MIT License", SPDX: "MIT"})
+	c.Store(module.Module{Path: "github.com/foo/new", Version: "v1.0.0"},
+		&license.License{Name: "MIT License", SPDX: "MIT"})
+	path := filepath.Join(t.TempDir(), "cache.json")
+	require.NoError(t, c.Save(path))
+
+	var loaded Cache
+	loaded.Load(path)
+	require.Len(t, loaded.data.Modules, 1)
+	require.Equal(t, "github.com/foo/new", loaded.data.Modules[0].Path)
+}
+
+func TestCacheFind_ttl(t *testing.T) {
+	var c Cache
+	c.Load(filepath.Join("testdata", "cache-duplicates.json"))
+
+	m := module.Module{Path: "github.com/foo/bar", Version: "v1.0.0", Hash: "h1:one="}
+	calls := 0
+	find := func() (*license.License, error) {
+		calls++
+		return &license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0"}, nil
+	}
+
+	// Without a TTL the cached license is used
+	lic, err := c.Find(m, find)
+	require.NoError(t, err)
+	require.Equal(t, "MIT", lic.SPDX)
+	require.Equal(t, 0, calls)
+
+	// The cached license is older than the TTL so it is looked up again
+	c.TTL = 24 * time.Hour
+	lic, err = c.Find(m, find)
+	require.NoError(t, err)
+	require.Equal(t, "Apache-2.0", lic.SPDX)
+	require.Equal(t, 1, calls)
+
+	// The fresh license replaces the expired one
+	lic, err = c.Find(m, find)
+	require.NoError(t, err)
+	require.Equal(t, "Apache-2.0", lic.SPDX)
+	require.Equal(t, 1, calls)
+}
+
+func TestCacheFind_negative(t *testing.T) {
+	m := module.Module{Path: "github.com/foo/none", Version: "v1.0.0"}
+
+	calls := 0
+	find := func() (*license.License, error) {
+		calls++
+		return nil, nil
+	}
+
+	// Without a negative TTL the module is always looked up again
+	var c Cache
+	for i := 0; i < 2; i++ {
+		lic, err := c.Find(m, find)
+		require.NoError(t, err)
+		require.Nil(t, lic)
+	}
+	require.Equal(t, 2, calls)
+
+	// The negative result is cached until it expires
+	calls = 0
+	c = Cache{NegativeTTL: time.Hour}
+	for i := 0; i < 2; i++ {
+		lic, err := c.Find(m, find)
+		require.NoError(t, err)
+		require.Nil(t, lic)
+	}
+	require.Equal(t, 1, calls)
+
+	// It survives a save and load
+	path := filepath.Join(t.TempDir(), "cache.json")
+	require.NoError(t, c.Save(path))
+	loaded := Cache{NegativeTTL: time.Hour}
+	loaded.Load(path)
+	lic, ok, err := loaded.Lookup(m)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Nil(t, lic)
+
+	// After it expires the module is looked up again
+	c.data.Modules[0].VerLic[0].Created = time.Now().Add(-2 * time.Hour)
+	_, err = c.Find(m, find)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+
+	// Errors aren't cached
+	calls = 0
+	failing := module.Module{Path: "github.com/foo/failing", Version: "v1.0.0"}
+	for i := 0; i < 2; i++ {
+		_, err := c.Find(failing, func() (*license.License, error) {
+			calls++
+			return nil, fmt.Errorf("rate limited")
+		})
+		require.Error(t, err)
+	}
+	require.Equal(t, 2, calls)
+
+	// ErrNotFound from the finders is a negative result, not an error
+	calls = 0
+	notFound := module.Module{Path: "github.com/foo/notfound", Version: "v1.0.0"}
+	for i := 0; i < 2; i++ {
+		lic, err := c.Find(notFound, func() (*license.License, error) {
+			calls++
+			return nil, license.ErrNotFound
+		})
+		require.Nil(t, lic)
+		if i == 0 {
+			require.True(t, errors.Is(err, license.ErrNotFound), "%v", err)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+	require.Equal(t, 1, calls)
+}
+
+func TestCachePrune(t *testing.T) {
+	now := time.Now()
+	cf := cacheFile{Modules: []cachedModule{
+		{Path: "github.com/foo/bar", VerLic: []moduleVersionLicense{
+			{Version: "v1.0.0", LastUsed: now.Add(-48 * time.Hour)},
+			{Version: "v1.1.0", LastUsed: now.Add(-time.Hour)},
+		}},
+		{Path: "github.com/foo/baz", VerLic: []moduleVersionLicense{
+			{Version: "v0.1.0", LastUsed: now.Add(-48 * time.Hour)},
+		}},
+		{Path: "github.com/foo/qux", VerLic: []moduleVersionLicense{
+			{Version: "v0.1.0"},
+		}},
+	}}
+
+	// Nothing is pruned without a retention
+	var c Cache
+	require.Equal(t, cf, c.prune(cf, now))
+
+	c.Retention = 24 * time.Hour
+	result := c.prune(cf, now)
+	require.Len(t, result.Modules, 2)
+	require.Equal(t, "github.com/foo/bar", result.Modules[0].Path)
+	require.Len(t, result.Modules[0].VerLic, 1)
+	require.Equal(t, "v1.1.0", result.Modules[0].VerLic[0].Version)
+	require.Equal(t, "github.com/foo/qux", result.Modules[1].Path)
+}
+
+func TestCacheSave_deterministic(t *testing.T) {
+	now := time.Now()
+	created := time.Date(2024, 1, 2, 3, 4, 5, 678, time.UTC)
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	bar := []moduleVersionLicense{
+		{Version: "v1.10.0", SPDX: "MIT", Created: created, LastUsed: now},
+		{Version: "v1.9.0", SPDX: "MIT", Created: created, LastUsed: now.Add(-time.Hour)},
+		{Version: "v1.0.0", SPDX: "MIT", Created: created, LastUsed: now.Add(-48 * time.Hour)},
+	}
+	baz := []moduleVersionLicense{
+		{Version: "v0.1.0", Negative: true, Created: created, LastUsed: now},
+	}
+
+	// The same entries, stored in a different order and time zone
+	inZone := func(vls []moduleVersionLicense) []moduleVersionLicense {
+		var result []moduleVersionLicense
+		for i := len(vls) - 1; i >= 0; i-- {
+			vv := vls[i]
+			vv.Created = vv.Created.In(zone)
+			vv.LastUsed = vv.LastUsed.In(zone)
+			result = append(result, vv)
+		}
+		return result
+	}
+	files := []cacheFile{
+		{Modules: []cachedModule{
+			{Path: "github.com/foo/bar", VerLic: bar},
+			{Path: "github.com/foo/baz", VerLic: baz},
+		}},
+		{Modules: []cachedModule{
+			{Path: "github.com/foo/baz", VerLic: inZone(baz)},
+			{Path: "github.com/foo/bar", VerLic: inZone(bar)},
+		}},
+	}
+
+	var saved [][]byte
+	for i, cf := range files {
+		c := Cache{Retention: 24 * time.Hour, data: cf}
+		path := filepath.Join(t.TempDir(), fmt.Sprintf("cache%d.json", i))
+		require.NoError(t, c.Save(path))
+
+		data, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		saved = append(saved, data)
+	}
+	require.Equal(t, string(saved[0]), string(saved[1]))
+
+	// Versions are in semantic version order and times in UTC
+	var cf cacheFile
+	require.NoError(t, json.Unmarshal(saved[0], &cf))
+	require.Len(t, cf.Modules, 2)
+	require.Equal(t, "github.com/foo/bar", cf.Modules[0].Path)
+	require.Len(t, cf.Modules[0].VerLic, 2)
+	require.Equal(t, "v1.9.0", cf.Modules[0].VerLic[0].Version)
+	require.Equal(t, "v1.10.0", cf.Modules[0].VerLic[1].Version)
+	require.Contains(t, string(saved[0]), `"created":"2024-01-02T03:04:05Z"`)
+}
+
+func TestCacheSave_gzip(t *testing.T) {
+	var c Cache
+	c.Store(module.Module{Path: "github.com/foo/bar", Version: "v1.0.0", Hash: "h1:bar="},
+		&license.License{Name: "MIT License", SPDX: "MIT"})
+	c.Store(module.Module{Path: "github.com/foo/baz", Version: "v0.1.0"}, nil)
+
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "cache.json")
+	compressed := filepath.Join(dir, "cache.json.gz")
+	require.NoError(t, c.Save(plain))
+	require.NoError(t, c.Save(compressed))
+
+	// The compressed file is the plain JSON in gzip
+	plainData, err := ioutil.ReadFile(plain)
+	require.NoError(t, err)
+	gzData, err := ioutil.ReadFile(compressed)
+	require.NoError(t, err)
+	require.NotEqual(t, plainData, gzData)
+	data, err := gunzipData(gzData)
+	require.NoError(t, err)
+	require.Equal(t, string(plainData), string(data))
+
+	// Both read back the same, including for -diff
+	var loaded Cache
+	require.NoError(t, loaded.Load(compressed))
+	lic, ok, err := loaded.Lookup(module.Module{Path: "github.com/foo/bar", Version: "v1.0.0", Hash: "h1:bar="})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "MIT", lic.SPDX)
+
+	cfPlain, err := loadCacheFile(plain)
+	require.NoError(t, err)
+	cfGzip, err := loadCacheFile(compressed)
+	require.NoError(t, err)
+	require.Equal(t, cfPlain, cfGzip)
+
+	// A plain file named .gz is an error, not an empty cache
+	require.NoError(t, ioutil.WriteFile(compressed, plainData, 0644))
+	require.Error(t, loaded.Load(compressed))
+}
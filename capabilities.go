@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// capability describes a finder, output format or exit code for the
+// -list-finders, -list-formats and -list-exit-codes flags.
+type capability struct {
+	Name        string
+	Description string
+}
+
+// finders are the license finders, in the order they are consulted unless
+// "finders" is set in the configuration file.
+var finders = []capability{
+	{"override", "licenses set with \"override\" in the configuration file"},
+	{"local", "license file in a directory given with -license-dirs"},
+	{"exec", "SPDX ID printed by the \"exec\" command in the configuration file"},
+	{"pkggodev", "license of the exact module version detected by pkg.go.dev"},
+	{"goproxy", "license file in the module zip downloaded from GOPROXY"},
+	{"github", "license detected by the GitHub API for the repository"},
+	{"bitbucket", "license file on the main branch of a Bitbucket repository"},
+	{"raw", "license file downloaded from a \"raw\" URL in the configuration file"},
+}
+
+// formats are the supported report formats.
+var formats = []capability{
+	{"terminal", "live terminal output, always enabled (-plain for plain text)"},
+	{"xlsx", "Excel workbook (-out-xlsx)"},
+	{"json", "JSON report (-out-json)"},
+	{"jsonl", "JSON Lines streamed as each module finishes (-out-jsonl)"},
+	{"cyclonedx", "CycloneDX 1.4 SBOM in JSON format (-out-cyclonedx)"},
+	{"spdx", "SPDX 2.3 document in tag-value format (-out-spdx)"},
+	{"junit", "JUnit XML report (-out-junit)"},
+	{"html", "HTML page with a color-coded table (-out-html)"},
+	{"markdown", "GitHub-flavored Markdown table for PR comments (-out-md)"},
+	{"template", "custom report from a Go text/template (-out-template)"},
+	{"attest", "JSON report with checksum and optional in-toto statement (-attest)"},
+	{"github-actions", "GitHub Actions workflow commands (-github-actions)"},
+	{"metrics", "JSON lookup metrics: requests, cache hits, finder latency (-out-metrics)"},
+}
+
+// exitCodes are the exit codes, in order of priority if several apply.
+var exitCodes = []capability{
+	{fmt.Sprint(ExitCodeUsage), "invalid flags, arguments, configuration or input files, or the check couldn't be completed"},
+	{fmt.Sprint(ExitCodeSumMismatch), "a module hash doesn't match the -verify-sum go.sum file or the cache"},
+	{fmt.Sprint(ExitCodePolicy), "a license is denied or isn't in the allow list, or has no SPDX ID with -require-spdx or -strict-spdx"},
+	{fmt.Sprint(ExitCodeUnknown), "no license was found or the lookup failed with -fail-unknown, or a binary was skipped with -strict"},
+	{"0", "every module passes the check"},
+}
+
+// printCapabilities writes the name and description of each capability
+// to w as aligned columns.
+func printCapabilities(w io.Writer, cs []capability) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, c := range cs {
+		fmt.Fprintf(tw, "%s\t%s\n", c.Name, c.Description)
+	}
+
+	return tw.Flush()
+}
@@ -4,6 +4,8 @@ import (
 	"strings"
 
 	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/license/mapper"
+	"github.com/mitchellh/golicense/module"
 )
 
 // Config is the configuration structure for the license checker.
@@ -12,22 +14,136 @@ type Config struct {
 	// respectively. The string value here can be either the license name
 	// (case insensitive) or the SPDX ID (case insensitive).
 	//
-	// If a license is found that isn't in either list, then a warning is
-	// emitted. If a license is in both deny and allow, then deny takes
-	// priority.
-	Allow []string `hcl:"allow,optional"`
-	Deny  []string `hcl:"deny,optional"`
+	// If either list isn't empty, a license that isn't in either of them,
+	// including an unknown one, is not allowed and fails the check. If a
+	// license is in both deny and allow, then deny takes priority.
+	//
+	// If the SPDX ID of a license is a compound expression such as
+	// "MIT OR Apache-2.0" and isn't itself listed, each license in it is
+	// checked: an OR expression is allowed if any of its licenses is
+	// allowed, and an AND expression is denied if any of them is denied.
+	//
+	// An entry with a "/" or an "@" is a module instead of a license, which
+	// is allowed or denied regardless of its license. It is an import path
+	// or glob pattern as in Exclude, optionally followed by "@" and a
+	// version or a version range, such as "github.com/foo/bar@v2.0.0" or
+	// "github.com/foo/bar@>=v2.0.0". The most specific matching entry wins
+	// with the precedence of the Override keys, so a module can be allowed
+	// except for some versions. Module entries don't make Allow an allow
+	// list of licenses.
+	Allow []string `hcl:"allow,optional" yaml:"allow,omitempty"`
+	Deny  []string `hcl:"deny,optional" yaml:"deny,omitempty"`
 
 	// Override is a map that explicitly sets the license for the given
-	// import path. The key is an import path (exact) and the value is
-	// the name or SPDX ID of the license. Regardless, the value will
-	// be set as both the name and SPDX ID, so SPDX IDs are recommended.
-	Override map[string]string `hcl:"override,optional"`
+	// import path. The key is an import path or glob pattern, optionally
+	// followed by "@" and a version or a version range such as
+	// "github.com/foo/bar@>=v2.0.0", and the value is the name or SPDX ID
+	// of the license. An entry with a version takes precedence over the
+	// entry for the path alone. Regardless, the value will be set as both
+	// the name and SPDX ID, so SPDX IDs are recommended.
+	Override map[string]string `hcl:"override,optional" yaml:"override,omitempty"`
 
 	// Translate is a map that translates one import source into another.
 	// For example, "gopkg.in/(.*)" => "github.com/\1" would translate
 	// gopkg into github (incorrectly, but the example would work).
-	Translate map[string]string `hcl:"translate,optional"`
+	Translate map[string]string `hcl:"translate,optional" yaml:"translate,omitempty"`
+
+	// Ref is a map that sets the git ref (tag, branch, or SHA) used to look
+	// up the license of the given import path (exact). This is useful when
+	// the license on the default branch doesn't apply to the version in use.
+	Ref map[string]string `hcl:"ref,optional" yaml:"ref,omitempty"`
+
+	// Exceptions is a map of import paths (exact) to the justification for
+	// accepting the module regardless of its license, such as a denied
+	// license that was approved for this one module. These modules are
+	// always allowed and the justification is included in the reports.
+	Exceptions map[string]string `hcl:"exceptions,optional" yaml:"exceptions,omitempty"`
+
+	// Exclude is a list of import paths or glob patterns, such as
+	// "github.com/myorg/*", of modules that are left out entirely. As in
+	// Override, an entry can be limited to some versions, such as
+	// "github.com/foo/bar@<v2.0.0". They
+	// aren't looked up, aren't in any report and don't affect the exit
+	// code. Exclude takes precedence over everything else, including
+	// Override and Exceptions.
+	Exclude []string `hcl:"exclude,optional" yaml:"exclude,omitempty"`
+
+	// Finders is the list of license finders to use in order of priority,
+	// by the names printed by -list-finders. If empty, all finders are
+	// used in their default order. Names separated by "|", such as
+	// "pkggodev|goproxy", are finders of equal priority.
+	Finders []string `hcl:"finders,optional" yaml:"finders,omitempty"`
+
+	// Exec is the command and arguments of the "exec" finder, which looks
+	// up licenses with an external tool such as a client for an internal
+	// license service. The module path and version are written to its
+	// stdin and it prints the SPDX ID of the license, or nothing if it
+	// doesn't know the module.
+	Exec []string `hcl:"exec,optional" yaml:"exec,omitempty"`
+
+	// Raw is a map of hosts to the URL template of the raw license file of
+	// a repository for the "raw" finder, for hosts without a license API
+	// such as Gitea. The placeholders {host}, {owner}, {repo}, {ref} and
+	// {file} are replaced, such as in
+	// "https://{host}/{owner}/{repo}/raw/{ref}/{file}".
+	Raw map[string]string `hcl:"raw,optional" yaml:"raw,omitempty"`
+
+	// Private is a list of patterns of internal modules, in addition to
+	// the GOPRIVATE and GONOSUMDB environment variables and with the same
+	// syntax, such as "git.corp.example.com/*". These modules are only
+	// looked up with the finders that don't use the network.
+	Private []string `hcl:"private,optional" yaml:"private,omitempty"`
+
+	// AllowPrivate, if true, allows internal modules regardless of their
+	// license, since their license is set by the organization anyway.
+	AllowPrivate bool `hcl:"allow_private,optional" yaml:"allow_private,omitempty"`
+}
+
+// AllowedModule returns the allowed state of the license of a module. This
+// is the same as Allowed, except that modules with an exception, and
+// internal modules with AllowPrivate, are always allowed, and modules
+// listed in Allow or Deny are allowed or denied regardless of the license.
+func (c *Config) AllowedModule(m *module.Module, l *license.License) AllowState {
+	if _, ok := c.Exceptions[m.Path]; ok {
+		return StateAllowed
+	}
+	if m.Private && c.AllowPrivate {
+		return StateAllowed
+	}
+	if state := c.allowedModule(m); state != StateUnknown {
+		return state
+	}
+
+	return c.Allowed(l)
+}
+
+// allowedModule returns the state of the most specific module entry of
+// Allow or Deny that matches the module, with the precedence of the
+// Override keys. Deny takes priority if the same entry is in both.
+func (c *Config) allowedModule(m *module.Module) AllowState {
+	var entries []string
+	denied := map[string]bool{}
+	for _, v := range c.Deny {
+		if isModuleEntry(v) {
+			entries = append(entries, v)
+			denied[v] = true
+		}
+	}
+	for _, v := range c.Allow {
+		if isModuleEntry(v) {
+			entries = append(entries, v)
+		}
+	}
+
+	v, ok := mapper.MostSpecific(entries, *m)
+	switch {
+	case !ok:
+		return StateUnknown
+	case denied[v]:
+		return StateDenied
+	}
+
+	return StateAllowed
 }
 
 // Allowed returns the allowed state of a license given the configuration.
@@ -36,12 +152,34 @@ func (c *Config) Allowed(l *license.License) AllowState {
 		return StateDenied // no license is never allowed
 	}
 
-	name := strings.ToLower(l.Name)
-	spdx := strings.ToLower(l.SPDX)
+	state := c.allowed(l.Name, l.SPDX)
+	if state == StateUnknown {
+		// Dual or compound licenses are checked by their parts
+		if e, err := license.ParseExpression(l.SPDX); err == nil && e.Compound() {
+			state = c.allowedExpression(e)
+		}
+	}
+
+	// With any list, everything that isn't in it fails
+	if state == StateUnknown && c.hasLicenseLists() {
+		return StateNotAllowed
+	}
+
+	return state
+}
+
+// allowed returns the state of a license with the given name and SPDX ID
+// based only on exact matches in the lists.
+func (c *Config) allowed(name, spdx string) AllowState {
+	name = strings.ToLower(name)
+	spdx = strings.ToLower(spdx)
 
 	// Deny takes priority
 	for _, v := range c.Deny {
 		v = strings.ToLower(v)
+		if isModuleEntry(v) {
+			continue
+		}
 		if name == v || spdx == v {
 			return StateDenied
 		}
@@ -49,6 +187,9 @@ func (c *Config) Allowed(l *license.License) AllowState {
 
 	for _, v := range c.Allow {
 		v = strings.ToLower(v)
+		if isModuleEntry(v) {
+			continue
+		}
 		if name == v || spdx == v {
 			return StateAllowed
 		}
@@ -57,10 +198,88 @@ func (c *Config) Allowed(l *license.License) AllowState {
 	return StateUnknown
 }
 
+// hasLicenseLists returns true if Allow or Deny lists any license, rather
+// than only modules.
+func (c *Config) hasLicenseLists() bool {
+	for _, list := range [][]string{c.Allow, c.Deny} {
+		for _, v := range list {
+			if !isModuleEntry(v) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isModuleEntry returns true if an entry of Allow or Deny is a module
+// rather than a license. License names and SPDX IDs never contain a "/"
+// or an "@".
+func isModuleEntry(v string) bool {
+	return strings.ContainsAny(v, "/@")
+}
+
+// allowedExpression returns the state of an SPDX license expression. For
+// OR the most permissive state of the operands is used since any of the
+// licenses can be chosen, and for AND the most restrictive one since all
+// of them apply.
+func (c *Config) allowedExpression(e *license.Expression) AllowState {
+	switch e.Op {
+	case "OR":
+		result := StateDenied
+		for _, arg := range e.Args {
+			switch c.allowedExpression(arg) {
+			case StateAllowed:
+				return StateAllowed
+			case StateUnknown:
+				result = StateUnknown
+			}
+		}
+
+		return result
+
+	case "AND":
+		result := StateAllowed
+		for _, arg := range e.Args {
+			switch c.allowedExpression(arg) {
+			case StateDenied:
+				return StateDenied
+			case StateUnknown:
+				result = StateUnknown
+			}
+		}
+
+		return result
+	}
+
+	// A license with an exception can be listed as a whole, otherwise
+	// the exception doesn't change the state of the license.
+	if e.Exception != "" {
+		if state := c.allowed("", e.String()); state != StateUnknown {
+			return state
+		}
+	}
+
+	return c.allowed("", e.ID)
+}
+
+// AllowState is the result of checking a license against the allow and
+// deny lists.
 type AllowState int
 
 const (
+	// StateUnknown means the license isn't in either list and there are
+	// no lists to enforce.
 	StateUnknown AllowState = iota
+
+	// StateAllowed means the license is in the allow list.
 	StateAllowed
+
+	// StateDenied means the license is in the deny list, or there is no
+	// license.
 	StateDenied
+
+	// StateNotAllowed means the license isn't in either list, and at
+	// least one of them isn't empty.
+	StateNotAllowed
 )
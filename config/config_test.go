@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
 	"github.com/stretchr/testify/require"
 )
 
@@ -58,6 +59,149 @@ func TestConfigAllowed(t *testing.T) {
 			&license.License{SPDX: "FOO"},
 			StateDenied,
 		},
+
+		{
+			"spdx denied",
+			&Config{
+				Deny: []string{"GPL-3.0"},
+			},
+			&license.License{Name: "GNU General Public License v3.0", SPDX: "GPL-3.0"},
+			StateDenied,
+		},
+
+		{
+			"name allowed and spdx denied",
+			&Config{
+				Allow: []string{"GNU General Public License v3.0"},
+				Deny:  []string{"GPL-3.0"},
+			},
+			&license.License{Name: "GNU General Public License v3.0", SPDX: "GPL-3.0"},
+			StateDenied,
+		},
+
+		{
+			"allowed with unrelated deny",
+			&Config{
+				Allow: []string{"MIT"},
+				Deny:  []string{"GPL-3.0"},
+			},
+			&license.License{Name: "MIT License", SPDX: "MIT"},
+			StateAllowed,
+		},
+
+		{
+			"in neither list",
+			&Config{
+				Allow: []string{"MIT"},
+				Deny:  []string{"GPL-3.0"},
+			},
+			&license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0"},
+			StateNotAllowed,
+		},
+
+		{
+			"allow only with unlisted license",
+			&Config{
+				Allow: []string{"MIT"},
+			},
+			&license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0"},
+			StateNotAllowed,
+		},
+
+		{
+			"deny only with unlisted license",
+			&Config{
+				Deny: []string{"GPL-3.0"},
+			},
+			&license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0"},
+			StateNotAllowed,
+		},
+
+		{
+			"allow only with unknown license",
+			&Config{
+				Allow: []string{"MIT"},
+			},
+			&license.License{Name: "Custom License"},
+			StateNotAllowed,
+		},
+
+		{
+			"dual license with one allowed",
+			&Config{
+				Allow: []string{"MIT"},
+				Deny:  []string{"GPL-3.0"},
+			},
+			&license.License{SPDX: "MIT OR GPL-3.0"},
+			StateAllowed,
+		},
+
+		{
+			"dual license with none allowed",
+			&Config{
+				Allow: []string{"MIT"},
+				Deny:  []string{"GPL-3.0"},
+			},
+			&license.License{SPDX: "Apache-2.0 OR GPL-3.0"},
+			StateNotAllowed,
+		},
+
+		{
+			"dual license with all denied",
+			&Config{
+				Deny: []string{"GPL-2.0", "GPL-3.0"},
+			},
+			&license.License{SPDX: "GPL-2.0 OR GPL-3.0"},
+			StateDenied,
+		},
+
+		{
+			"compound license with one denied",
+			&Config{
+				Allow: []string{"MIT"},
+				Deny:  []string{"GPL-3.0"},
+			},
+			&license.License{SPDX: "MIT AND GPL-3.0"},
+			StateDenied,
+		},
+
+		{
+			"compound license with all allowed",
+			&Config{
+				Allow: []string{"MIT", "BSD-3-Clause"},
+			},
+			&license.License{SPDX: "MIT AND (BSD-3-Clause OR GPL-3.0)"},
+			StateAllowed,
+		},
+
+		{
+			"compound license listed as a whole",
+			&Config{
+				Allow: []string{"MIT"},
+				Deny:  []string{"mit or gpl-3.0"},
+			},
+			&license.License{SPDX: "MIT OR GPL-3.0"},
+			StateDenied,
+		},
+
+		{
+			"license with allowed exception",
+			&Config{
+				Allow: []string{"GPL-2.0 WITH Classpath-exception-2.0"},
+				Deny:  []string{"GPL-2.0"},
+			},
+			&license.License{SPDX: "GPL-2.0 WITH Classpath-exception-2.0"},
+			StateAllowed,
+		},
+
+		{
+			"license with exception",
+			&Config{
+				Deny: []string{"GPL-2.0"},
+			},
+			&license.License{SPDX: "GPL-2.0 WITH Classpath-exception-2.0"},
+			StateDenied,
+		},
 	}
 
 	for _, tt := range cases {
@@ -67,3 +211,68 @@ func TestConfigAllowed(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigAllowedModule(t *testing.T) {
+	c := &Config{
+		Allow: []string{"MIT"},
+		Deny:  []string{"GPL-3.0"},
+		Exceptions: map[string]string{
+			"github.com/foo/gpl": "Only used by internal tooling",
+		},
+	}
+	gpl := &license.License{SPDX: "GPL-3.0"}
+
+	require.Equal(t, StateAllowed, c.AllowedModule(&module.Module{Path: "github.com/foo/gpl"}, gpl))
+	require.Equal(t, StateAllowed, c.AllowedModule(&module.Module{Path: "github.com/foo/gpl"}, nil))
+	require.Equal(t, StateDenied, c.AllowedModule(&module.Module{Path: "github.com/foo/other"}, gpl))
+
+	// Internal modules are only allowed regardless of their license with
+	// AllowPrivate
+	private := &module.Module{Path: "git.corp.example.com/lib", Private: true}
+	require.Equal(t, StateDenied, c.AllowedModule(private, nil))
+	c.AllowPrivate = true
+	require.Equal(t, StateAllowed, c.AllowedModule(private, nil))
+	require.Equal(t, StateDenied, c.AllowedModule(&module.Module{Path: "github.com/foo/other"}, gpl))
+}
+
+func TestConfigAllowedModule_version(t *testing.T) {
+	c := &Config{
+		Allow: []string{"github.com/foo/bar", "github.com/foo/bar@v2.1.0", "github.com/foo/*@<v1.0.0"},
+		Deny:  []string{"MIT", "github.com/foo/bar@>=v2.0.0", "github.com/foo/baz", "github.com/foo/baz@v0.3.0"},
+	}
+	mit := &license.License{SPDX: "MIT"}
+
+	cases := []struct {
+		Path    string
+		Version string
+		State   AllowState
+	}{
+		// The path alone is the fallback
+		{"github.com/foo/bar", "v1.0.0", StateAllowed},
+
+		// A range takes precedence over the path alone
+		{"github.com/foo/bar", "v2.0.0", StateDenied},
+
+		// An exact version takes precedence over a range
+		{"github.com/foo/bar", "v2.1.0", StateAllowed},
+
+		// A versioned pattern takes precedence over the path alone
+		{"github.com/foo/baz", "v0.2.0", StateAllowed},
+		{"github.com/foo/baz", "v0.3.0", StateDenied},
+		{"github.com/foo/baz", "v1.0.0", StateDenied},
+
+		// Other modules are checked by their license
+		{"github.com/foo/qux", "v1.0.0", StateDenied},
+		{"github.com/other/qux", "v0.1.0", StateDenied},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Path+"@"+tt.Version, func(t *testing.T) {
+			m := &module.Module{Path: tt.Path, Version: tt.Version}
+			require.Equal(t, tt.State, c.AllowedModule(m, mit))
+		})
+	}
+
+	// Module entries don't make Allow an allow list of licenses
+	require.Equal(t, StateUnknown, (&Config{Allow: c.Allow}).Allowed(&license.License{SPDX: "ISC"}))
+}
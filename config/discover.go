@@ -0,0 +1,35 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DiscoverNames are the names of the configuration files that Discover
+// looks for, in order of preference within a directory.
+var DiscoverNames = []string{".golicense.hcl", ".golicense.yaml", ".golicense.yml"}
+
+// Discover looks for a configuration file in dir and then in each of its
+// parent directories, like git looks for its repository. It returns the
+// path of the first file found, or "" if there is none up to the root.
+func Discover(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		for _, name := range DiscoverNames {
+			fn := filepath.Join(dir, name)
+			if fi, err := os.Stat(fn); err == nil && fi.Mode().IsRegular() {
+				return fn, nil
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
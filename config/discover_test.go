@@ -0,0 +1,53 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	require.NoError(t, os.MkdirAll(sub, 0755))
+
+	// Nothing to find, at least not below the temporary directory
+	fn, err := Discover(sub)
+	require.NoError(t, err)
+	if fn != "" {
+		require.False(t, strings.HasPrefix(fn, root), fn)
+	}
+
+	// A file in a parent directory is found
+	parent := filepath.Join(root, "a", ".golicense.yaml")
+	require.NoError(t, ioutil.WriteFile(parent, []byte("allow: [MIT]\n"), 0644))
+	fn, err = Discover(sub)
+	require.NoError(t, err)
+	require.Equal(t, parent, fn)
+
+	// The closest directory wins
+	cwd := filepath.Join(sub, ".golicense.hcl")
+	require.NoError(t, ioutil.WriteFile(cwd, []byte("allow = [\"MIT\"]\n"), 0644))
+	fn, err = Discover(sub)
+	require.NoError(t, err)
+	require.Equal(t, cwd, fn)
+
+	// HCL is preferred over YAML in the same directory
+	require.NoError(t, ioutil.WriteFile(filepath.Join(sub, ".golicense.yaml"), []byte("allow: [MIT]\n"), 0644))
+	fn, err = Discover(sub)
+	require.NoError(t, err)
+	require.Equal(t, cwd, fn)
+
+	// Directories with the name are ignored
+	dir := filepath.Join(root, "c")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".golicense.hcl"), 0755))
+	fn, err = Discover(dir)
+	require.NoError(t, err)
+	if fn != "" {
+		require.False(t, strings.HasPrefix(fn, root), fn)
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// ParseListFile parses a file with a list of licenses, such as a shared
+// allow or deny list, with one name or SPDX ID per line. See ParseList.
+func ParseListFile(filename string) ([]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseList(f)
+}
+
+// ParseList parses a newline separated list. Everything after a "#" is a
+// comment, and blank lines are ignored.
+func ParseList(r io.Reader) ([]string, error) {
+	var result []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.IndexByte(line, '#'); idx >= 0 {
+			line = line[:idx]
+		}
+
+		if line = strings.TrimSpace(line); line != "" {
+			result = append(result, line)
+		}
+	}
+
+	return result, scanner.Err()
+}
+
+// MergeList appends the values of b to a that aren't already in it,
+// compared case insensitively like the entries of Allow and Deny.
+func MergeList(a, b []string) []string {
+	seen := make(map[string]struct{}, len(a))
+	for _, v := range a {
+		seen[strings.ToLower(v)] = struct{}{}
+	}
+
+	for _, v := range b {
+		k := strings.ToLower(v)
+		if _, ok := seen[k]; ok {
+			continue
+		}
+
+		seen[k] = struct{}{}
+		a = append(a, v)
+	}
+
+	return a
+}
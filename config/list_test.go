@@ -0,0 +1,47 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseList(t *testing.T) {
+	actual, err := ParseList(strings.NewReader(`# Licenses approved by legal
+
+MIT
+  Apache-2.0  
+BSD-3-Clause # only with attribution
+   # indented comment
+`))
+	require.NoError(t, err)
+	require.Equal(t, []string{"MIT", "Apache-2.0", "BSD-3-Clause"}, actual)
+}
+
+func TestParseListFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deny.txt")
+	require.NoError(t, ioutil.WriteFile(path, []byte("# Copyleft\r\nGPL-3.0\r\nAGPL-3.0\r\n"), 0644))
+
+	actual, err := ParseListFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"GPL-3.0", "AGPL-3.0"}, actual)
+
+	_, err = ParseListFile(filepath.Join(t.TempDir(), "missing.txt"))
+	require.Error(t, err)
+}
+
+func TestMergeList(t *testing.T) {
+	c := &Config{Allow: []string{"MIT", "ISC"}}
+	c.Allow = MergeList(c.Allow, []string{"mit", "Apache-2.0", "ISC", "Apache-2.0"})
+	require.Equal(t, []string{"MIT", "ISC", "Apache-2.0"}, c.Allow)
+
+	// The merged list is used like one from the configuration
+	require.Equal(t, StateAllowed, c.Allowed(&license.License{SPDX: "Apache-2.0"}))
+	require.Equal(t, StateNotAllowed, c.Allowed(&license.License{SPDX: "GPL-3.0"}))
+
+	require.Equal(t, []string{"GPL-3.0"}, MergeList(nil, []string{"GPL-3.0"}))
+}
@@ -1,41 +1,77 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/hashicorp/hcl2/gohcl"
 	"github.com/hashicorp/hcl2/hcl"
 	"github.com/hashicorp/hcl2/hcl/hclsyntax"
 	"github.com/hashicorp/hcl2/hcl/json"
+	"gopkg.in/yaml.v3"
 )
 
 // ParseFile parses the given file for a configuration. The syntax of the
 // file is determined based on the filename extension: "hcl" for HCL,
-// "json" for JSON, other is an error.
+// "json" for JSON, "yaml" or "yml" for YAML. For other extensions the
+// syntax is detected from the contents.
 func ParseFile(filename string) (*Config, error) {
-	f, err := os.Open(filename)
+	src, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
 
 	ext := filepath.Ext(filename)
 	if len(ext) > 0 {
 		ext = ext[1:]
 	}
 
-	return Parse(f, filename, ext)
+	switch ext {
+	case "hcl", "json", "yaml":
+	case "yml":
+		ext = "yaml"
+	default:
+		ext = sniffFormat(src)
+	}
+
+	return Parse(bytes.NewReader(src), filename, ext)
+}
+
+// sniffFormat detects the syntax of a configuration without a known
+// extension. JSON is an object, HCL assigns attributes with "=" while
+// YAML uses ":".
+func sniffFormat(src []byte) string {
+	src = bytes.TrimSpace(src)
+	if bytes.HasPrefix(src, []byte("{")) {
+		return "json"
+	}
+
+	for _, line := range strings.Split(string(src), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		eq, colon := strings.Index(line, "="), strings.Index(line, ":")
+		if eq >= 0 && (colon < 0 || eq < colon) {
+			return "hcl"
+		}
+
+		return "yaml"
+	}
+
+	return "hcl"
 }
 
 // Parse parses the configuration from the given reader. The reader will be
 // read to completion (EOF) before returning so ensure that the reader
 // does not block forever.
 //
-// format is either "hcl" or "json"
+// format is either "hcl", "json" or "yaml"
 func Parse(r io.Reader, filename, format string) (*Config, error) {
 	switch format {
 	case "hcl":
@@ -44,8 +80,11 @@ func Parse(r io.Reader, filename, format string) (*Config, error) {
 	case "json":
 		return parseJSON(r, filename)
 
+	case "yaml":
+		return parseYAML(r, filename)
+
 	default:
-		return nil, fmt.Errorf("Format must be either 'hcl' or 'json'")
+		return nil, fmt.Errorf("Format must be either 'hcl', 'json' or 'yaml'")
 	}
 }
 
@@ -88,3 +127,20 @@ func parseJSON(r io.Reader, filename string) (*Config, error) {
 
 	return &config, nil
 }
+
+func parseYAML(r io.Reader, filename string) (*Config, error) {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reject unknown keys like the HCL decoder does, so typos are noticed.
+	var config Config
+	dec := yaml.NewDecoder(bytes.NewReader(src))
+	dec.KnownFields(true)
+	if err := dec.Decode(&config); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("%s: %s", filename, err)
+	}
+
+	return &config, nil
+}
@@ -13,6 +13,7 @@ import (
 func init() {
 	goldie.FixtureDir = "testdata"
 	spew.Config.DisablePointerAddresses = true
+	spew.Config.SortKeys = true
 }
 
 func TestParseFile(t *testing.T) {
@@ -38,3 +39,32 @@ func TestParseFile(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFile_yaml(t *testing.T) {
+	expected, err := ParseFile(filepath.Join("testdata", "full.hcl"))
+	require.NoError(t, err)
+	require.Equal(t, "github.com/example/\\1", expected.Translate["/^example\\.com/(.*)$/"])
+
+	actual, err := ParseFile(filepath.Join("testdata", "full.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func TestSniffFormat(t *testing.T) {
+	cases := []struct {
+		Input  string
+		Output string
+	}{
+		{`{"allow": ["MIT"]}`, "json"},
+		{"# comment\nallow = [\"MIT\"]", "hcl"},
+		{"allow = [\"a:b\"]", "hcl"},
+		{"allow:\n  - MIT", "yaml"},
+		{"override:\n  github.com/foo/bar: a=b", "yaml"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Input, func(t *testing.T) {
+			require.Equal(t, tt.Output, sniffFormat([]byte(tt.Input)))
+		})
+	}
+}
@@ -0,0 +1,31 @@
+allow = ["MIT", "Apache-2.0"]
+deny  = ["GPL-3.0"]
+
+override = {
+  "github.com/foo/bar" = "MIT"
+}
+
+translate = {
+  "gopkg.in/foo/bar.v2"   = "github.com/foo/bar"
+  "/^example\\.com/(.*)$/" = "github.com/example/\\1"
+}
+
+ref = {
+  "github.com/foo/bar" = "v1.2.3"
+}
+
+exceptions = {
+  "github.com/foo/gpl" = "Approved by legal for internal tooling only"
+}
+
+exclude = ["github.com/myorg/*"]
+
+finders = ["override", "exec", "github"]
+exec    = ["license-lookup", "--server", "https://licenses.example.com"]
+
+raw = {
+  "git.corp.example.com" = "https://{host}/{owner}/{repo}/raw/{ref}/{file}"
+}
+
+private       = ["git.corp.example.com/*"]
+allow_private = true
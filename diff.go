@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+)
+
+// cacheDiff is the difference between the licenses of two cache files,
+// such as the caches of two releases.
+type cacheDiff struct {
+	Added   []diffModule `json:"added"`
+	Removed []diffModule `json:"removed"`
+	Changed []diffChange `json:"changed"`
+}
+
+// diffModule is the license of a module in a cache file.
+type diffModule struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	License string `json:"license,omitempty"`
+	SPDX    string `json:"spdx,omitempty"`
+}
+
+// diffChange is a module whose license changed.
+type diffChange struct {
+	Path string     `json:"path"`
+	Old  diffModule `json:"old"`
+	New  diffModule `json:"new"`
+}
+
+// loadCacheFile reads a cache file for a diff. Unlike Cache.Load, a missing
+// or invalid file is an error since there is nothing to compare.
+func loadCacheFile(fn string) (cacheFile, error) {
+	data, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return cacheFile{}, err
+	}
+
+	result, err := decodeCacheFile(nil, fn, data)
+	if err != nil {
+		return cacheFile{}, err
+	}
+
+	return mergeCache(result), nil
+}
+
+// diffCaches compares the modules of two cache files. A cache holds every
+// version of a module that was looked up, so each module is compared using
+// its most recently used version.
+func diffCaches(old, new cacheFile) cacheDiff {
+	oldMods := latestVersions(old)
+	newMods := latestVersions(new)
+
+	var d cacheDiff
+	for path, nm := range newMods {
+		om, ok := oldMods[path]
+		if !ok {
+			d.Added = append(d.Added, nm)
+			continue
+		}
+
+		if om.License != nm.License || om.SPDX != nm.SPDX {
+			d.Changed = append(d.Changed, diffChange{Path: path, Old: om, New: nm})
+		}
+	}
+	for path, om := range oldMods {
+		if _, ok := newMods[path]; !ok {
+			d.Removed = append(d.Removed, om)
+		}
+	}
+
+	sort.Slice(d.Added, func(i, j int) bool { return d.Added[i].Path < d.Added[j].Path })
+	sort.Slice(d.Removed, func(i, j int) bool { return d.Removed[i].Path < d.Removed[j].Path })
+	sort.Slice(d.Changed, func(i, j int) bool { return d.Changed[i].Path < d.Changed[j].Path })
+	return d
+}
+
+// latestVersions returns the most recently used version of each module,
+// using the most recently created version if they were used at the same
+// time.
+func latestVersions(cf cacheFile) map[string]diffModule {
+	result := map[string]diffModule{}
+	for _, cm := range cf.Modules {
+		var latest *moduleVersionLicense
+		for i, vv := range cm.VerLic {
+			if latest == nil || vv.LastUsed.After(latest.LastUsed) ||
+				(vv.LastUsed.Equal(latest.LastUsed) && vv.Created.After(latest.Created)) {
+				latest = &cm.VerLic[i]
+			}
+		}
+		if latest == nil {
+			continue
+		}
+
+		result[cm.Path] = diffModule{
+			Path:    cm.Path,
+			Version: latest.Version,
+			License: latest.License,
+			SPDX:    latest.SPDX,
+		}
+	}
+
+	return result
+}
+
+// WriteText writes the diff in a human readable format.
+func (d cacheDiff) WriteText(w io.Writer) error {
+	if len(d.Added)+len(d.Removed)+len(d.Changed) == 0 {
+		_, err := fmt.Fprintln(w, "No license changes.")
+		return err
+	}
+
+	var err error
+	printf := func(format string, args ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+	for _, m := range d.Added {
+		printf("+ %s %s: %s\n", m.Path, m.Version, m.licenseString())
+	}
+	for _, m := range d.Removed {
+		printf("- %s %s: %s\n", m.Path, m.Version, m.licenseString())
+	}
+	for _, c := range d.Changed {
+		printf("~ %s %s -> %s: %s -> %s\n", c.Path, c.Old.Version, c.New.Version,
+			c.Old.licenseString(), c.New.licenseString())
+	}
+
+	return err
+}
+
+// WriteJSON writes the diff as a JSON object.
+func (d cacheDiff) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// licenseString returns the license name with the SPDX ID if known.
+func (m diffModule) licenseString() string {
+	switch {
+	case m.License == "" && m.SPDX == "":
+		return "unknown"
+
+	case m.SPDX == "" || m.SPDX == m.License:
+		return m.License
+
+	default:
+		return fmt.Sprintf("%s (%s)", m.License, m.SPDX)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffCaches(t *testing.T) {
+	old, err := loadCacheFile(filepath.Join("testdata", "diff-old.json"))
+	require.NoError(t, err)
+	new, err := loadCacheFile(filepath.Join("testdata", "diff-new.json"))
+	require.NoError(t, err)
+
+	d := diffCaches(old, new)
+
+	var text bytes.Buffer
+	require.NoError(t, d.WriteText(&text))
+	require.Equal(t, `+ github.com/foo/added v2.0.0: BSD 3-Clause "New" or "Revised" License (BSD-3-Clause)
+- github.com/foo/removed v0.1.0: Apache License 2.0 (Apache-2.0)
+~ github.com/foo/changed v1.0.0 -> v1.1.0: MIT License (MIT) -> GNU General Public License v3.0 (GPL-3.0)
+`, text.String())
+
+	var buf bytes.Buffer
+	require.NoError(t, d.WriteJSON(&buf))
+	var actual cacheDiff
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &actual))
+	require.Equal(t, d, actual)
+
+	// Comparing a cache with itself has no changes
+	text.Reset()
+	require.NoError(t, diffCaches(new, new).WriteText(&text))
+	require.Equal(t, "No license changes.\n", text.String())
+
+	_, err = loadCacheFile(filepath.Join("testdata", "missing.json"))
+	require.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// readBinariesFrom reads the newline separated paths of binaries to analyze
+// from the given file, or stdin if it is "-". Empty lines are ignored.
+func readBinariesFrom(path string) ([]string, error) {
+	if path == "-" {
+		return readPaths(os.Stdin)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return readPaths(f)
+}
+
+// readPaths reads newline separated paths, ignoring empty lines.
+func readPaths(r io.Reader) ([]string, error) {
+	var result []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			result = append(result, line)
+		}
+	}
+
+	return result, scanner.Err()
+}
+
+// mergePaths returns the union of the paths in order, without duplicates.
+// Paths are compared after cleaning so "./foo" and "foo" are the same.
+func mergePaths(lists ...[]string) []string {
+	var result []string
+	seen := map[string]struct{}{}
+	for _, list := range lists {
+		for _, p := range list {
+			key := filepath.Clean(p)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+
+			seen[key] = struct{}{}
+			result = append(result, p)
+		}
+	}
+
+	return result
+}
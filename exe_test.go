@@ -0,0 +1,24 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBinariesFrom(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "binaries.txt")
+	require.NoError(t, ioutil.WriteFile(path, []byte(
+		"dist/foo\n\n  dist/bar  \r\n./bin/baz\n"), 0644))
+
+	paths, err := readBinariesFrom(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"dist/foo", "dist/bar", "./bin/baz"}, paths)
+
+	// Positional arguments come first and duplicates are removed
+	require.Equal(t,
+		[]string{"bin/baz", "dist/qux", "dist/foo", "dist/bar"},
+		mergePaths([]string{"bin/baz", "dist/qux", "dist/qux"}, paths))
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/mitchellh/golicense/license"
+)
+
+// newHTTPTransport returns the transport used for all outbound requests.
+// Requests go through the given proxy URL if set, otherwise the proxy is
+// taken from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+// variables.
+func newHTTPTransport(proxy string) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyFromEnvironment
+	if proxy == "" {
+		return t, nil
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("proxy URL must include a scheme and host, such as http://proxy:8080")
+	}
+
+	t.Proxy = http.ProxyURL(u)
+	return t, nil
+}
+
+// offlineTransport is the transport with -offline. It fails every request
+// so that nothing reaches the network, even from libraries that don't
+// accept a client.
+type offlineTransport struct{}
+
+// RoundTrip implements http.RoundTripper
+func (offlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("%s: network requests are disabled with -offline", req.URL)
+}
+
+// metricsTransport counts every request with the Metrics of the request
+// context, so that requests are attributed to the finder making them.
+type metricsTransport struct {
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	license.MetricsFromContext(req.Context()).Request(req.Context())
+	return t.Base.RoundTrip(req)
+}
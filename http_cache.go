@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// httpCacheTransport caches the responses of GET requests on disk, so that
+// repeated runs don't fetch the same resources again. It is separate from
+// the license cache of -cache: it caches the raw responses of every finder,
+// including lookups that didn't find a license.
+//
+// A response is served from the cache without a request while it is fresh
+// according to its Cache-Control max-age. After that it is revalidated
+// with its ETag or Last-Modified date, and a 304 Not Modified response is
+// answered with the cached body. Responses with Cache-Control no-store or
+// without any way to check them again are not cached.
+type httpCacheTransport struct {
+	// Dir is the directory of the cache files. It is created when the
+	// first response is cached.
+	Dir string
+
+	// Base is the transport making the requests that can't be answered
+	// from the cache.
+	Base http.RoundTripper
+
+	// Log, if set, gets a warning for every response that can't be written
+	// to the cache. The cache is best effort, so this doesn't fail the
+	// request.
+	Log *Logger
+
+	// now returns the current time. If nil, time.Now is used.
+	now func() time.Time
+}
+
+// httpCacheEntry is a cached response, stored as JSON in a file named
+// after the hash of its URL.
+type httpCacheEntry struct {
+	URL    string      `json:"url"`
+	Stored time.Time   `json:"stored"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+
+	// Vary are the hashes of the request headers named by the Vary header
+	// of the response. The entry is only used for requests with the same
+	// headers, so that responses for different tokens aren't mixed up.
+	// Only hashes are stored so that tokens aren't written to disk.
+	Vary map[string]string `json:"vary,omitempty"`
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *httpCacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.Base.RoundTrip(req)
+	}
+
+	fn := t.path(req)
+	entry := t.read(fn, req)
+	if entry != nil && entry.fresh(t.time()) {
+		return entry.response(req), nil
+	}
+
+	outReq := req
+	if entry != nil {
+		outReq = req.Clone(req.Context())
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			outReq.Header.Set("If-None-Match", etag)
+		}
+		if lm := entry.Header.Get("Last-Modified"); lm != "" {
+			outReq.Header.Set("If-Modified-Since", lm)
+		}
+	}
+
+	resp, err := t.Base.RoundTrip(outReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		resp.Body.Close()
+
+		// The 304 response carries the new freshness of the entry
+		for _, name := range []string{"Cache-Control", "Date", "ETag", "Expires", "Last-Modified"} {
+			if v := resp.Header.Get(name); v != "" {
+				entry.Header.Set(name, v)
+			}
+		}
+		entry.Stored = t.time()
+		t.write(fn, entry)
+		return entry.response(req), nil
+	}
+
+	if resp.StatusCode != http.StatusOK || !cacheable(resp.Header) {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	t.write(fn, &httpCacheEntry{
+		URL:    req.URL.String(),
+		Stored: t.time(),
+		Status: resp.StatusCode,
+		Header: resp.Header,
+		Body:   body,
+		Vary:   varyValues(req, resp.Header),
+	})
+
+	return resp, nil
+}
+
+// path returns the path of the cache file of a request.
+func (t *httpCacheTransport) path(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.URL.String()))
+	return filepath.Join(t.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// read returns the cached entry for a request, or nil if there is none or
+// it was stored for different Vary headers.
+func (t *httpCacheTransport) read(fn string, req *http.Request) *httpCacheEntry {
+	data, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return nil
+	}
+
+	var entry httpCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != req.URL.String() {
+		return nil
+	}
+	for name, v := range varyValues(req, entry.Header) {
+		if entry.Vary[name] != v {
+			return nil
+		}
+	}
+
+	return &entry
+}
+
+// write stores an entry.
+func (t *httpCacheTransport) write(fn string, entry *httpCacheEntry) {
+	data, err := json.Marshal(entry)
+	if err == nil {
+		err = os.MkdirAll(t.Dir, 0755)
+	}
+	if err == nil {
+		err = writeFileAtomic(fn, data)
+	}
+	if err != nil {
+		t.Log.Warnf("Error writing HTTP cache for %s: %s", entry.URL, err)
+	}
+}
+
+// writeFileAtomic writes a file next to its final path and renames it, so
+// that concurrent requests and runs never read a partial entry.
+func writeFileAtomic(fn string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(fn), filepath.Base(fn)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), fn)
+}
+
+// time returns the current time.
+func (t *httpCacheTransport) time() time.Time {
+	if t.now != nil {
+		return t.now()
+	}
+
+	return time.Now()
+}
+
+// fresh returns true if the entry can be used without revalidating it.
+func (e *httpCacheEntry) fresh(now time.Time) bool {
+	cc := cacheControl(e.Header)
+	if _, ok := cc["no-cache"]; ok {
+		return false
+	}
+
+	maxAge, err := strconv.Atoi(cc["max-age"])
+	if err != nil {
+		return false
+	}
+
+	return now.Sub(e.Stored) < time.Duration(maxAge)*time.Second
+}
+
+// response returns the entry as a response to the request.
+func (e *httpCacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(e.Status) + " " + http.StatusText(e.Status),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// cacheable returns true if a response with the given header can be cached:
+// it isn't marked no-store and it is either fresh for a while or can be
+// revalidated.
+func cacheable(h http.Header) bool {
+	cc := cacheControl(h)
+	if _, ok := cc["no-store"]; ok {
+		return false
+	}
+	if h.Get("Vary") == "*" {
+		return false
+	}
+
+	_, maxAge := cc["max-age"]
+	return maxAge || h.Get("ETag") != "" || h.Get("Last-Modified") != ""
+}
+
+// cacheControl parses the Cache-Control header into its directives and
+// their values, which are empty for directives without one.
+func cacheControl(h http.Header) map[string]string {
+	cc := map[string]string{}
+	for _, part := range strings.Split(h.Get("Cache-Control"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, value := part, ""
+		if idx := strings.IndexByte(part, '='); idx >= 0 {
+			name, value = part[:idx], strings.Trim(part[idx+1:], `"`)
+		}
+		cc[strings.ToLower(name)] = value
+	}
+
+	return cc
+}
+
+// varyValues returns the hashes of the request headers named by the Vary
+// header of a response.
+func varyValues(req *http.Request, h http.Header) map[string]string {
+	var vary map[string]string
+	for _, name := range strings.Split(h.Get("Vary"), ",") {
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		if vary == nil {
+			vary = map[string]string{}
+		}
+		sum := sha256.Sum256([]byte(strings.Join(req.Header.Values(name), "\n")))
+		vary[name] = hex.EncodeToString(sum[:])
+	}
+
+	return vary
+}
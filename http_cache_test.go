@@ -0,0 +1,183 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countingTransport answers every request with the response of Handler and
+// records the requests it got.
+type countingTransport struct {
+	Handler  func(*http.Request) *http.Response
+	Requests []*http.Request
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.Requests = append(t.Requests, req)
+	return t.Handler(req), nil
+}
+
+func testHTTPResponse(status int, header http.Header, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func testHTTPGet(t *testing.T, rt http.RoundTripper, url string, header http.Header) string {
+	t.Helper()
+
+	req, err := http.NewRequest("GET", url, nil)
+	require.NoError(t, err)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	data, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestHTTPCacheTransport_fresh(t *testing.T) {
+	base := &countingTransport{Handler: func(*http.Request) *http.Response {
+		return testHTTPResponse(200, http.Header{"Cache-Control": {"max-age=60"}}, "MIT License")
+	}}
+	now := time.Now()
+	cache := &httpCacheTransport{Dir: t.TempDir(), Base: base, now: func() time.Time { return now }}
+
+	url := "https://api.github.com/repos/foo/bar/license"
+	require.Equal(t, "MIT License", testHTTPGet(t, cache, url, nil))
+	require.Len(t, base.Requests, 1)
+
+	// The second identical request is served from the cache
+	require.Equal(t, "MIT License", testHTTPGet(t, cache, url, nil))
+	require.Len(t, base.Requests, 1)
+
+	// So is a request of a new transport, such as in the next run
+	cache = &httpCacheTransport{Dir: cache.Dir, Base: base, now: cache.now}
+	require.Equal(t, "MIT License", testHTTPGet(t, cache, url, nil))
+	require.Len(t, base.Requests, 1)
+
+	// A different URL is not
+	testHTTPGet(t, cache, url+"?ref=main", nil)
+	require.Len(t, base.Requests, 2)
+
+	// Once expired, the response is fetched again
+	now = now.Add(time.Minute)
+	testHTTPGet(t, cache, url, nil)
+	require.Len(t, base.Requests, 3)
+}
+
+func TestHTTPCacheTransport_etag(t *testing.T) {
+	base := &countingTransport{Handler: func(req *http.Request) *http.Response {
+		if req.Header.Get("If-None-Match") == `"abc"` {
+			return testHTTPResponse(304, http.Header{}, "")
+		}
+
+		return testHTTPResponse(200, http.Header{
+			"Cache-Control": {"no-cache"},
+			"Etag":          {`"abc"`},
+		}, "MIT License")
+	}}
+	cache := &httpCacheTransport{Dir: t.TempDir(), Base: base}
+
+	url := "https://api.github.com/repos/foo/bar/license"
+	require.Equal(t, "MIT License", testHTTPGet(t, cache, url, nil))
+	require.Equal(t, "", base.Requests[0].Header.Get("If-None-Match"))
+
+	// The entry is revalidated and the 304 answered with the cached body
+	require.Equal(t, "MIT License", testHTTPGet(t, cache, url, nil))
+	require.Len(t, base.Requests, 2)
+	require.Equal(t, `"abc"`, base.Requests[1].Header.Get("If-None-Match"))
+}
+
+func TestHTTPCacheTransport_notCached(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Method string
+		Header http.Header
+		Req2   http.Header
+	}{
+		{
+			"no-store",
+			"GET",
+			http.Header{"Cache-Control": {"no-store, max-age=60"}},
+			nil,
+		},
+
+		{
+			"no validators",
+			"GET",
+			http.Header{},
+			nil,
+		},
+
+		{
+			"other method",
+			"HEAD",
+			http.Header{"Cache-Control": {"max-age=60"}},
+			nil,
+		},
+
+		{
+			"vary",
+			"GET",
+			http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"Authorization"}},
+			http.Header{"Authorization": {"token other"}},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			base := &countingTransport{Handler: func(*http.Request) *http.Response {
+				return testHTTPResponse(200, tt.Header.Clone(), "MIT License")
+			}}
+			cache := &httpCacheTransport{Dir: t.TempDir(), Base: base}
+
+			for _, h := range []http.Header{nil, tt.Req2} {
+				req, err := http.NewRequest(tt.Method, "https://api.github.com/repos/foo/bar/license", nil)
+				require.NoError(t, err)
+				req.Header.Set("Authorization", "token one")
+				for k, v := range h {
+					req.Header[k] = v
+				}
+
+				resp, err := cache.RoundTrip(req)
+				require.NoError(t, err)
+				resp.Body.Close()
+			}
+
+			require.Len(t, base.Requests, 2)
+		})
+	}
+}
+
+func TestHTTPCacheTransport_noSecrets(t *testing.T) {
+	base := &countingTransport{Handler: func(*http.Request) *http.Response {
+		return testHTTPResponse(200, http.Header{
+			"Cache-Control": {"max-age=60"},
+			"Vary":          {"Accept, Authorization"},
+		}, "MIT License")
+	}}
+	cache := &httpCacheTransport{Dir: t.TempDir(), Base: base}
+
+	url := "https://api.github.com/repos/foo/bar/license"
+	testHTTPGet(t, cache, url, http.Header{"Authorization": {"token secret"}})
+	require.Equal(t, "MIT License", testHTTPGet(t, cache, url, http.Header{"Authorization": {"token secret"}}))
+	require.Len(t, base.Requests, 1)
+
+	data, err := ioutil.ReadFile(cache.path(base.Requests[0]))
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "secret")
+}
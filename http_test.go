@@ -0,0 +1,34 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHTTPTransport(t *testing.T) {
+	req, err := http.NewRequest("GET", "https://api.github.com/repos/foo/bar", nil)
+	require.NoError(t, err)
+
+	t.Run("environment", func(t *testing.T) {
+		tr, err := newHTTPTransport("")
+		require.NoError(t, err)
+		require.NotNil(t, tr.Proxy)
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		tr, err := newHTTPTransport("http://proxy.example.com:8080")
+		require.NoError(t, err)
+		require.NotNil(t, tr.Proxy)
+
+		u, err := tr.Proxy(req)
+		require.NoError(t, err)
+		require.Equal(t, "http://proxy.example.com:8080", u.String())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := newHTTPTransport("proxy.example.com")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,44 @@
+// Package apache contains a translator for modules hosted by the Apache
+// Software Foundation.
+package apache
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/mitchellh/golicense/module"
+)
+
+// Translator converts module paths hosted on the ASF git servers to the
+// official GitHub mirror under github.com/apache so that the license can be
+// looked up (and confirmed) with the GitHub finder.
+type Translator struct{}
+
+func (t Translator) Translate(ctx context.Context, m module.Module) (module.Module, bool) {
+	if v, ok := known[m.Path]; ok {
+		m.Path = v
+		return m, true
+	}
+
+	ms := re.FindStringSubmatch(m.Path)
+	if ms == nil {
+		return module.Module{}, false
+	}
+
+	// Matches, convert to the GitHub mirror
+	m.Path = fmt.Sprintf("github.com/apache/%s", ms[1])
+	return m, true
+}
+
+// known is the mapping of common ASF-hosted Go modules to their GitHub
+// mirrors. This also covers legacy hosts that re below doesn't match.
+var known = map[string]string{
+	"git.apache.org/thrift.git":                  "github.com/apache/thrift",
+	"git-wip-us.apache.org/repos/asf/thrift.git": "github.com/apache/thrift",
+	"git.apache.org/arrow.git":                   "github.com/apache/arrow",
+}
+
+// re matches the git.apache.org and gitbox.apache.org module paths. The
+// repository name is the first capture group, without any ".git" suffix.
+var re = regexp.MustCompile(`^(?:git|gitbox)\.apache\.org/(?:repos/asf/)?([^/]+?)(?:\.git)?$`)
@@ -0,0 +1,58 @@
+package apache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslator(t *testing.T) {
+	cases := []struct {
+		Input  string
+		Output string
+	}{
+		{
+			"github.com/foo/bar",
+			"",
+		},
+
+		{
+			"git.apache.org/thrift.git",
+			"github.com/apache/thrift",
+		},
+
+		{
+			"git-wip-us.apache.org/repos/asf/thrift.git",
+			"github.com/apache/thrift",
+		},
+
+		{
+			"gitbox.apache.org/repos/asf/pulsar-client-go.git",
+			"github.com/apache/pulsar-client-go",
+		},
+
+		{
+			"git.apache.org/foo",
+			"github.com/apache/foo",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Input, func(t *testing.T) {
+			var tr Translator
+			actual, ok := tr.Translate(context.Background(), module.Module{
+				Path: tt.Input,
+			})
+
+			if tt.Output == "" {
+				require.False(t, ok)
+				return
+			}
+
+			require.True(t, ok)
+			require.Equal(t, tt.Output, actual.Path)
+		})
+	}
+}
@@ -0,0 +1,177 @@
+// Package bitbucket contains a license.Finder for repositories hosted on
+// Bitbucket Cloud.
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mitchellh/golicense/license"
+	textdetect "github.com/mitchellh/golicense/license/detect"
+	"github.com/mitchellh/golicense/module"
+)
+
+// DefaultURL is the Bitbucket API used if no URL is configured.
+const DefaultURL = "https://api.bitbucket.org"
+
+// Finder implements license.Finder and detects the license of modules
+// hosted on Bitbucket (bitbucket.org/owner/repo) from the license files
+// on the main branch of the repository, or at the commit of a
+// pseudo-version.
+//
+// Bitbucket doesn't detect licenses itself, so the license files are
+// classified the same way as by the local finder.
+type Finder struct {
+	// Client is the HTTP client to use. http.DefaultClient is used if nil.
+	Client *http.Client
+
+	// URL is the base URL of the Bitbucket API. DefaultURL is used if empty.
+	URL string
+
+	// Token is the access token to authenticate with. If it is empty,
+	// no lookups are done.
+	Token string
+}
+
+// License implements license.Finder
+func (f *Finder) License(ctx context.Context, m module.Module) (*license.License, error) {
+	r, ok := module.ParseRepo(m.Path)
+	if !ok || r.Host != "bitbucket.org" || f.Token == "" {
+		return nil, license.ErrNotFound
+	}
+
+	base := f.URL
+	if base == "" {
+		base = DefaultURL
+	}
+	repoURL := fmt.Sprintf("%s/2.0/repositories/%s/%s",
+		strings.TrimSuffix(base, "/"), url.PathEscape(r.Owner), url.PathEscape(r.Name))
+
+	license.UpdateStatus(ctx, license.StatusNormal, "querying Bitbucket API")
+	var repo repository
+	if ok, err := f.get(ctx, repoURL, &repo); !ok || err != nil {
+		return license.OrNotFound(nil, err)
+	}
+
+	// Mercurial repositories have a "default" branch rather than "master".
+	ref := repo.MainBranch.Name
+	if ref == "" {
+		ref = "master"
+		if repo.SCM == "hg" {
+			ref = "default"
+		}
+	}
+
+	// A pseudo-version is an untagged commit, whose license may differ
+	// from the one on the main branch
+	if commit := m.PseudoCommit(); commit != "" {
+		license.UpdateStatus(ctx, license.StatusNormal, fmt.Sprintf(
+			"pseudo-version, looking up license at commit %s", commit))
+		ref = commit
+	}
+
+	srcURL := fmt.Sprintf("%s/src/%s/", repoURL, url.PathEscape(ref))
+	var dir directory
+	if ok, err := f.get(ctx, srcURL+"?pagelen=100", &dir); !ok || err != nil {
+		return license.OrNotFound(nil, err)
+	}
+
+	files := map[string][]byte{}
+	for _, v := range dir.Values {
+		if v.Type != "commit_file" || !textdetect.IsLicenseFile(v.Path) {
+			continue
+		}
+
+		data, err := f.raw(ctx, srcURL+v.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		files[v.Path] = data
+	}
+	if len(files) == 0 {
+		return nil, license.ErrNotFound
+	}
+
+	license.UpdateStatus(ctx, license.StatusNormal, "detecting license")
+	lic, err := textdetect.DetectFiles(files)
+	if lic != nil {
+		lic.Source = "bitbucket"
+	}
+
+	return license.OrNotFound(lic, err)
+}
+
+// get requests the URL and decodes the JSON response into v. This returns
+// false without an error if the repository doesn't exist or we don't have
+// access to it, since private repositories are expected.
+func (f *Finder) get(ctx context.Context, u string, v interface{}) (bool, error) {
+	resp, err := f.do(ctx, u)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return false, nil
+	default:
+		return false, license.HTTPError(resp.StatusCode,
+			fmt.Errorf("Bitbucket returned %s for %s", resp.Status, u))
+	}
+
+	return true, json.NewDecoder(resp.Body).Decode(v)
+}
+
+// raw returns the content of the file at the URL.
+func (f *Finder) raw(ctx context.Context, u string) ([]byte, error) {
+	resp, err := f.do(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, license.HTTPError(resp.StatusCode,
+			fmt.Errorf("Bitbucket returned %s for %s", resp.Status, u))
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (f *Finder) do(ctx context.Context, u string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+f.Token)
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return client.Do(req.WithContext(ctx))
+}
+
+// repository is the part of the Bitbucket repository resource we use.
+type repository struct {
+	SCM        string `json:"scm"`
+	MainBranch struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+}
+
+// directory is a page of the Bitbucket source directory listing.
+type directory struct {
+	Values []struct {
+		Path string `json:"path"`
+		Type string `json:"type"`
+	} `json:"values"`
+}
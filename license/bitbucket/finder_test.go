@@ -0,0 +1,98 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinder(t *testing.T) {
+	mit, err := ioutil.ReadFile(filepath.Join("testdata", "LICENSE"))
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		switch r.URL.Path {
+		case "/2.0/repositories/foo/git":
+			w.Write([]byte(`{"scm": "git", "mainbranch": {"name": "main"}}`))
+		case "/2.0/repositories/foo/git/src/main/":
+			w.Write([]byte(`{"values": [
+				{"path": "LICENSE", "type": "commit_file"},
+				{"path": "license", "type": "commit_directory"},
+				{"path": "main.go", "type": "commit_file"}
+			]}`))
+		case "/2.0/repositories/foo/git/src/main/LICENSE":
+			w.Write(mit)
+
+		case "/2.0/repositories/foo/hg":
+			w.Write([]byte(`{"scm": "hg"}`))
+		case "/2.0/repositories/foo/hg/src/default/":
+			w.Write([]byte(`{"values": [{"path": "COPYING", "type": "commit_file"}]}`))
+		case "/2.0/repositories/foo/hg/src/default/COPYING":
+			w.Write(mit)
+
+		case "/2.0/repositories/foo/none":
+			w.Write([]byte(`{"scm": "git", "mainbranch": {"name": "master"}}`))
+		case "/2.0/repositories/foo/none/src/master/":
+			w.Write([]byte(`{"values": [{"path": "README.md", "type": "commit_file"}]}`))
+
+		case "/2.0/repositories/foo/private":
+			w.WriteHeader(http.StatusForbidden)
+
+		case "/2.0/repositories/foo/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	mitLicense := &license.License{Name: "MIT", SPDX: "MIT", Source: "bitbucket"}
+	cases := []struct {
+		Path   string
+		Token  string
+		Result *license.License
+		Err    bool
+	}{
+		{"bitbucket.org/foo/git", "secret", mitLicense, false},
+		{"bitbucket.org/foo/git/sub/pkg", "secret", mitLicense, false},
+		{"bitbucket.org/foo/hg", "secret", mitLicense, false},
+		{"bitbucket.org/foo/none", "secret", nil, false},
+		{"bitbucket.org/foo/private", "secret", nil, false},
+		{"bitbucket.org/foo/missing", "secret", nil, false},
+		{"bitbucket.org/foo/broken", "secret", nil, true},
+		{"bitbucket.org/foo/git", "", nil, false},
+		{"github.com/foo/git", "secret", nil, false},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Path, func(t *testing.T) {
+			f := &Finder{URL: srv.URL, Token: tt.Token}
+			actual, err := f.License(context.Background(), module.Module{
+				Path:    tt.Path,
+				Version: "v1.0.0",
+			})
+			// A module without a license isn't an error
+			if tt.Result == nil && !tt.Err {
+				require.True(t, errors.Is(err, license.ErrNotFound), "%v", err)
+				err = nil
+			}
+			require.Equal(t, tt.Err, err != nil)
+			if actual != nil {
+				require.GreaterOrEqual(t, actual.Confidence, 0.9)
+				actual.Confidence = 0
+			}
+			require.Equal(t, tt.Result, actual)
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package license
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/mitchellh/golicense/module"
+)
+
+// Breaker is a Finder that limits how long a lookup of its finder may take
+// and stops using the finder for the rest of the run once it failed
+// Threshold times in a row. This is a circuit breaker for the host the
+// finder queries: if it is down or unreachable, the remaining modules
+// aren't each delayed by a failing request or a timeout. The skipped
+// lookups fail with an UnavailableError.
+//
+// Finders that query a different host depending on the module, such as
+// github.com and a GitHub Enterprise instance, implement HostFinder. Their
+// failures are counted by host, so only the modules on a host that is down
+// are skipped. Other finders are counted as a single host.
+//
+// A license or ErrNotFound resets the count. Lookups cancelled with their
+// context, such as on interrupt, don't count.
+type Breaker struct {
+	Finder Finder
+
+	// Threshold is the number of consecutive failures after which the
+	// finder isn't used anymore for a host. Zero means it is always used.
+	Threshold int
+
+	// Timeout, if positive, is how long a single lookup of the finder may
+	// take. It fails with context.DeadlineExceeded after that.
+	Timeout time.Duration
+
+	lock     sync.Mutex
+	failures map[string]int   // by host
+	last     map[string]error // by host
+}
+
+// HostFinder is implemented by finders that query a host that depends on
+// the module, so that a Breaker counts their failures by host.
+type HostFinder interface {
+	Finder
+
+	// Host returns the host that the license of the module is looked up
+	// on, or "" if the finder doesn't look it up.
+	Host(module.Module) string
+}
+
+// finderHost returns the host that f looks up the license of m on, or ""
+// if f doesn't implement HostFinder.
+func finderHost(f Finder, m module.Module) string {
+	if nf, ok := f.(*namedFinder); ok {
+		f = nf.Finder
+	}
+	if hf, ok := f.(HostFinder); ok {
+		return hf.Host(m)
+	}
+
+	return ""
+}
+
+// License implements Finder
+func (b *Breaker) License(ctx context.Context, m module.Module) (*License, error) {
+	host := finderHost(b.Finder, m)
+	if err := b.open(host); err != nil {
+		return nil, err
+	}
+
+	fctx := ctx
+	if b.Timeout > 0 {
+		var cancel context.CancelFunc
+		fctx, cancel = context.WithTimeout(ctx, b.Timeout)
+		defer cancel()
+	}
+
+	lic, err := b.Finder.License(fctx, m)
+	if err != nil && b.Timeout > 0 && fctx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		err = Transient(fmt.Errorf("%s timed out after %s", FinderName(b.Finder), b.Timeout))
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	switch {
+	case err == nil || errors.Is(err, ErrNotFound):
+		delete(b.failures, host)
+
+	case errors.Is(err, context.Canceled):
+
+	default:
+		if b.failures == nil {
+			b.failures = map[string]int{}
+			b.last = map[string]error{}
+		}
+		b.failures[host]++
+		b.last[host] = err
+		if b.Threshold > 0 && b.failures[host] == b.Threshold {
+			UpdateStatus(ctx, StatusWarning, fmt.Sprintf(
+				"%s failed %d times in a row, skipping it from now on",
+				unavailableName(FinderName(b.Finder), host), b.failures[host]))
+		}
+	}
+
+	return lic, err
+}
+
+// open returns the error for a skipped lookup if the finder failed too
+// often for the host, or nil if it can be used.
+func (b *Breaker) open(host string) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.Threshold <= 0 || b.failures[host] < b.Threshold {
+		return nil
+	}
+
+	return Transient(&UnavailableError{
+		Finder:   FinderName(b.Finder),
+		Host:     host,
+		Failures: b.failures[host],
+		Err:      b.last[host],
+	})
+}
+
+// UnavailableError is the error of a lookup that a Breaker skipped since
+// its finder failed too often. The license may still exist, so it is
+// wrapped as a TransientError.
+type UnavailableError struct {
+	// Finder is the name of the finder that was skipped, and Host the
+	// host it was skipped for if it is a HostFinder.
+	Finder string
+	Host   string
+
+	// Failures is the number of consecutive failures, and Err the last one.
+	Failures int
+	Err      error
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("%s skipped after %d failures in a row, last: %s",
+		unavailableName(e.Finder, e.Host), e.Failures, e.Err)
+}
+
+// unavailableName returns the name of a finder that was skipped for a
+// host, such as "github at github.example.com".
+func unavailableName(finder, host string) string {
+	if host == "" {
+		return finder
+	}
+
+	return finder + " at " + host
+}
+
+// UnavailableFinders returns the names of the finders whose lookups were
+// skipped by a Breaker in err, followed by " at " and the host for a
+// HostFinder. For the combined errors returned by Find, these are the
+// names for all finders that were skipped.
+func UnavailableFinders(err error) []string {
+	if merr, ok := err.(*multierror.Error); ok {
+		var result []string
+		for _, err := range merr.Errors {
+			result = append(result, UnavailableFinders(err)...)
+		}
+
+		return result
+	}
+
+	var uerr *UnavailableError
+	if errors.As(err, &uerr) {
+		return []string{unavailableName(uerr.Finder, uerr.Host)}
+	}
+
+	return nil
+}
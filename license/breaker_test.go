@@ -0,0 +1,124 @@
+package license
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreaker(t *testing.T) {
+	up := module.Module{Path: "github.com/foo/up"}
+	down := module.Module{Path: "github.com/foo/down"}
+	missing := module.Module{Path: "github.com/foo/missing"}
+
+	var f MockFinder
+	f.On("License", mock.Anything, up).Return(&License{SPDX: "MIT"}, nil)
+	f.On("License", mock.Anything, down).Return(nil, Transient(errors.New("502 Bad Gateway")))
+	f.On("License", mock.Anything, missing).Return(nil, ErrNotFound)
+	b := &Breaker{Finder: &f, Threshold: 3}
+
+	// A success or a module without a license resets the count
+	for _, m := range []module.Module{down, down, up, down, down, missing, down, down} {
+		_, err := b.License(context.Background(), m)
+		require.Nil(t, UnavailableFinders(err))
+	}
+
+	// The third failure in a row opens the breaker
+	_, err := b.License(context.Background(), down)
+	require.Error(t, err)
+	require.Nil(t, UnavailableFinders(err))
+	f.AssertNumberOfCalls(t, "License", 9)
+
+	// From then on every module is skipped without a lookup
+	for _, m := range []module.Module{up, missing, down} {
+		lic, err := b.License(context.Background(), m)
+		require.Nil(t, lic)
+		require.True(t, IsTransient(err))
+		require.Equal(t, []string{"license.MockFinder"}, UnavailableFinders(err))
+		require.Contains(t, err.Error(), "502 Bad Gateway")
+	}
+	f.AssertNumberOfCalls(t, "License", 9)
+
+	// The skipped finder can be told apart in the errors of Find
+	var other MockFinder
+	other.On("License", mock.Anything, up).Return(nil, errors.New("failed"))
+	_, err = Find(context.Background(), up, []Finder{&other, b})
+	require.Equal(t, []string{"license.MockFinder"}, UnavailableFinders(err))
+	require.Nil(t, UnavailableFinders(multierror.Append(nil, errors.New("failed"))))
+}
+
+// hostFinder is a MockFinder that looks up each module on the host of
+// its path.
+type hostFinder struct {
+	*MockFinder
+}
+
+func (hostFinder) Host(m module.Module) string {
+	return strings.SplitN(m.Path, "/", 2)[0]
+}
+
+func TestBreaker_host(t *testing.T) {
+	up := module.Module{Path: "github.com/foo/up"}
+	down := module.Module{Path: "github.example.com/foo/down"}
+
+	var f MockFinder
+	f.On("License", mock.Anything, up).Return(&License{SPDX: "MIT"}, nil)
+	f.On("License", mock.Anything, down).Return(nil, Transient(errors.New("502 Bad Gateway")))
+	b := &Breaker{Finder: &namedFinder{Finder: hostFinder{&f}, Name: "github"}, Threshold: 2}
+
+	// Successes on one host don't reset the count of another
+	for _, m := range []module.Module{down, up, down, up} {
+		_, err := b.License(context.Background(), m)
+		require.Nil(t, UnavailableFinders(err))
+	}
+	f.AssertNumberOfCalls(t, "License", 4)
+
+	// Only the host that is down is skipped
+	_, err := b.License(context.Background(), down)
+	require.Equal(t, []string{"github at github.example.com"}, UnavailableFinders(err))
+	require.Contains(t, err.Error(), "github at github.example.com skipped after 2 failures")
+	lic, err := b.License(context.Background(), up)
+	require.NoError(t, err)
+	require.NotNil(t, lic)
+	f.AssertNumberOfCalls(t, "License", 5)
+}
+
+// blockingFinder is a finder that only returns when its context is done.
+type blockingFinder struct{}
+
+func (blockingFinder) License(ctx context.Context, m module.Module) (*License, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestBreaker_timeout(t *testing.T) {
+	b := &Breaker{Finder: blockingFinder{}, Threshold: 1, Timeout: 10 * time.Millisecond}
+
+	// A lookup that takes too long fails, and counts as a failure
+	_, err := b.License(context.Background(), module.Module{Path: "github.com/foo/bar"})
+	require.True(t, IsTransient(err))
+	require.Contains(t, err.Error(), "timed out after 10ms")
+
+	_, err = b.License(context.Background(), module.Module{Path: "github.com/foo/baz"})
+	require.Equal(t, []string{"license.blockingFinder"}, UnavailableFinders(err))
+}
+
+func TestBreaker_cancelled(t *testing.T) {
+	var f MockFinder
+	f.On("License", mock.Anything, mock.Anything).Return(nil, context.Canceled)
+	b := &Breaker{Finder: &f, Threshold: 1}
+
+	// Cancelled lookups don't count as failures of the finder
+	for i := 0; i < 3; i++ {
+		_, err := b.License(context.Background(), module.Module{Path: "github.com/foo/bar"})
+		require.True(t, errors.Is(err, context.Canceled))
+	}
+	f.AssertNumberOfCalls(t, "License", 3)
+}
@@ -0,0 +1,60 @@
+// Package command implements a license finder that runs an external
+// command, such as a client for an internal license service.
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/mitchellh/golicense/license"
+	spdxdb "github.com/mitchellh/golicense/license/spdx"
+	"github.com/mitchellh/golicense/module"
+)
+
+// Finder runs a command to look up the license of a module. The module
+// path and version are written to the standard input of the command
+// separated by a space, and the command prints the SPDX ID or expression
+// of the license to its standard output. If it prints nothing, the
+// license isn't known to the command and license.ErrNotFound is returned.
+type Finder struct {
+	// Command is the command to run followed by its arguments.
+	Command []string
+}
+
+// License implements license.Finder
+func (f *Finder) License(ctx context.Context, m module.Module) (*license.License, error) {
+	if len(f.Command) == 0 {
+		return nil, fmt.Errorf("no command to run")
+	}
+
+	license.UpdateStatus(ctx, license.StatusNormal, "running "+f.Command[0])
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, f.Command[0], f.Command[1:]...)
+	cmd.Stdin = strings.NewReader(m.Path + " " + m.Version + "\n")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s failed: %s: %s", f.Command[0], err, msg)
+		}
+
+		return nil, fmt.Errorf("%s failed: %s", f.Command[0], err)
+	}
+
+	id := strings.TrimSpace(stdout.String())
+	if id == "" {
+		return nil, license.ErrNotFound
+	}
+
+	// The command only prints the SPDX ID but we want the complete name
+	// if it is a single license.
+	lic := &license.License{Name: id, SPDX: id, Source: "exec"}
+	if l, ok := spdxdb.Get(id); ok {
+		lic.Name, lic.SPDX = l.Name, l.ID
+	}
+
+	return lic, nil
+}
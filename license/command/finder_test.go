@@ -0,0 +1,94 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinder(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub command is a shell script")
+	}
+
+	cases := []struct {
+		Path    string
+		Version string
+		Result  *license.License
+		Err     string
+	}{
+		{
+			"github.com/foo/mit",
+			"v1.0.0",
+			&license.License{Name: "MIT License", SPDX: "MIT", Source: "exec"},
+			"",
+		},
+
+		{
+			"github.com/foo/dual",
+			"v1.0.0",
+			&license.License{Name: "Apache-2.0 OR MIT", SPDX: "Apache-2.0 OR MIT", Source: "exec"},
+			"",
+		},
+
+		{
+			"github.com/foo/versioned",
+			"v2.0.0",
+			&license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0", Source: "exec"},
+			"",
+		},
+
+		{
+			"github.com/foo/versioned",
+			"v1.0.0",
+			nil,
+			"",
+		},
+
+		{
+			"github.com/foo/unknown",
+			"v1.0.0",
+			nil,
+			"",
+		},
+
+		{
+			"github.com/foo/broken",
+			"v1.0.0",
+			nil,
+			"license service unavailable",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Path+"@"+tt.Version, func(t *testing.T) {
+			f := &Finder{Command: []string{"sh", "testdata/license.sh"}}
+			actual, err := f.License(context.Background(), module.Module{
+				Path:    tt.Path,
+				Version: tt.Version,
+			})
+			switch {
+			case tt.Err != "":
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.Err)
+
+			case tt.Result == nil:
+				require.True(t, errors.Is(err, license.ErrNotFound), "%v", err)
+
+			default:
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.Result, actual)
+		})
+	}
+}
+
+func TestFinder_noCommand(t *testing.T) {
+	_, err := (&Finder{}).License(context.Background(), module.Module{Path: "github.com/foo/bar"})
+	require.Error(t, err)
+}
@@ -0,0 +1,21 @@
+#!/bin/sh
+# Stub license service for the tests. Reads "path version" from stdin.
+read path version
+
+case "$path" in
+github.com/foo/mit)
+	echo "mit"
+	;;
+github.com/foo/dual)
+	echo "Apache-2.0 OR MIT"
+	;;
+github.com/foo/versioned)
+	if [ "$version" = "v2.0.0" ]; then
+		echo "Apache-2.0"
+	fi
+	;;
+github.com/foo/broken)
+	echo "license service unavailable" >&2
+	exit 1
+	;;
+esac
@@ -0,0 +1,190 @@
+// Package detect classifies license text by comparing it against the
+// text of common licenses, and the license files of a module with
+// DetectFiles.
+package detect
+
+import (
+	"embed"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/golicense/license"
+)
+
+// Threshold is the confidence at or above which a match is considered
+// reliable.
+const Threshold = 0.9
+
+// Detect returns the license that best matches the given text, along with
+// a confidence between 0 and 1. The confidence is the portion of the
+// license template that is found in the text, so additional text such as
+// a copyright notice or a README around the license doesn't lower it. The
+// confidence is also set on the returned license.
+//
+// If the text contains multiple licenses, such as a dual-licensed project,
+// the result is an SPDX "OR" expression of all licenses matching above
+// Threshold and the confidence is the lowest of their confidences.
+//
+// nil is returned if the text has nothing in common with any template. The
+// caller should compare the confidence against Threshold.
+func Detect(text string) (*license.License, float64) {
+	words := shingles(text)
+
+	var best *template
+	var bestScore float64
+	var matches []*template
+	scores := map[*template]float64{}
+	for _, t := range templates {
+		s := containment(t.Shingles, words)
+		scores[t] = s
+		if s > bestScore {
+			best, bestScore = t, s
+		}
+		if s >= Threshold {
+			matches = append(matches, t)
+		}
+	}
+
+	if len(matches) <= 1 {
+		if best == nil {
+			return nil, 0
+		}
+
+		return &license.License{
+			Name:       best.Name,
+			SPDX:       best.ID,
+			Confidence: bestScore,
+		}, bestScore
+	}
+
+	// Some licenses contain most of the text of another, such as
+	// BSD-3-Clause and BSD-2-Clause. Drop the matches that are only the
+	// result of a larger license matching.
+	var result []*template
+	for _, t := range matches {
+		subsumed := false
+		for _, other := range matches {
+			if other != t && containment(t.Shingles, other.Shingles) >= Threshold {
+				subsumed = true
+				break
+			}
+		}
+
+		if !subsumed {
+			result = append(result, t)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	ids := make([]string, len(result))
+	names := make([]string, len(result))
+	confidence := 1.0
+	for i, t := range result {
+		ids[i] = t.ID
+		names[i] = t.Name
+		if scores[t] < confidence {
+			confidence = scores[t]
+		}
+	}
+
+	return &license.License{
+		Name:       strings.Join(names, " OR "),
+		SPDX:       strings.Join(ids, " OR "),
+		Confidence: confidence,
+	}, confidence
+}
+
+// template is the normalized text of a known license.
+type template struct {
+	ID       string
+	Name     string
+	Shingles map[string]struct{}
+}
+
+// names are the full names of the licenses with a template.
+var names = map[string]string{
+	"Apache-2.0":   "Apache License 2.0",
+	"BSD-2-Clause": "BSD 2-Clause \"Simplified\" License",
+	"BSD-3-Clause": "BSD 3-Clause \"New\" or \"Revised\" License",
+	"ISC":          "ISC License",
+	"MIT":          "MIT License",
+	"MPL-2.0":      "Mozilla Public License 2.0",
+}
+
+//go:embed templates/*.txt
+var templateFS embed.FS
+
+// templates are the known licenses, parsed once from the embedded files.
+var templates = loadTemplates()
+
+func loadTemplates() []*template {
+	entries, err := templateFS.ReadDir("templates")
+	if err != nil {
+		panic(err)
+	}
+
+	result := make([]*template, 0, len(entries))
+	for _, e := range entries {
+		data, err := templateFS.ReadFile(path.Join("templates", e.Name()))
+		if err != nil {
+			panic(err)
+		}
+
+		id := strings.TrimSuffix(e.Name(), ".txt")
+		result = append(result, &template{
+			ID:       id,
+			Name:     names[id],
+			Shingles: shingles(string(data)),
+		})
+	}
+
+	return result
+}
+
+// shingles returns the set of all runs of three consecutive words in the
+// normalized text. Copyright lines are ignored since they differ for every
+// project.
+func shingles(text string) map[string]struct{} {
+	var words []string
+	for _, line := range strings.Split(strings.ToLower(text), "\n") {
+		if strings.Contains(line, "copyright (c)") ||
+			strings.HasPrefix(strings.TrimSpace(line), "copyright ") {
+			continue
+		}
+
+		for _, w := range wordRe.FindAllString(line, -1) {
+			if w == "licence" {
+				w = "license"
+			}
+			words = append(words, w)
+		}
+	}
+
+	result := map[string]struct{}{}
+	for i := 0; i+2 < len(words); i++ {
+		result[words[i]+" "+words[i+1]+" "+words[i+2]] = struct{}{}
+	}
+
+	return result
+}
+
+// containment returns the portion of a that is also in b.
+func containment(a, b map[string]struct{}) float64 {
+	if len(a) == 0 {
+		return 0
+	}
+
+	n := 0
+	for k := range a {
+		if _, ok := b[k]; ok {
+			n++
+		}
+	}
+
+	return float64(n) / float64(len(a))
+}
+
+// wordRe matches a single word of license text.
+var wordRe = regexp.MustCompile(`[a-z0-9]+`)
@@ -0,0 +1,62 @@
+package detect
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	cases := []struct {
+		File     string
+		Expected *license.License
+	}{
+		{
+			"mit.txt",
+			&license.License{Name: "MIT License", SPDX: "MIT"},
+		},
+
+		{
+			"apache-2.0.txt",
+			&license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0"},
+		},
+
+		{
+			"bsd-3-clause.txt",
+			&license.License{Name: "BSD 3-Clause \"New\" or \"Revised\" License", SPDX: "BSD-3-Clause"},
+		},
+
+		{
+			"dual.txt",
+			&license.License{Name: "Apache License 2.0 OR MIT License", SPDX: "Apache-2.0 OR MIT"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.File, func(t *testing.T) {
+			data, err := ioutil.ReadFile(filepath.Join("testdata", tt.File))
+			require.NoError(t, err)
+
+			actual, confidence := Detect(string(data))
+			require.GreaterOrEqual(t, confidence, Threshold)
+			require.Equal(t, confidence, actual.Confidence)
+			actual.Confidence = 0
+			require.Equal(t, tt.Expected, actual)
+		})
+	}
+}
+
+func TestDetect_noMatch(t *testing.T) {
+	actual, confidence := Detect("All rights reserved. Do not copy this software in any form.")
+	require.Less(t, confidence, Threshold)
+	if actual != nil {
+		require.NotEqual(t, "MIT", actual.SPDX)
+	}
+
+	actual, confidence = Detect("")
+	require.Nil(t, actual)
+	require.Zero(t, confidence)
+}
@@ -0,0 +1,106 @@
+package detect
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/golicense/license"
+	"gopkg.in/src-d/go-license-detector.v2/licensedb"
+	"gopkg.in/src-d/go-license-detector.v2/licensedb/filer"
+)
+
+// DetectFiles classifies the license files of a module, given by their
+// names in the module root and their contents. go-license-detector is tried
+// first, and Detect on the combined text of all files if that doesn't find
+// a license, so that projects with a license file per license are detected
+// as dual-licensed. nil is returned if no license is found.
+func DetectFiles(files map[string][]byte) (*license.License, error) {
+	ms, err := licensedb.Detect(&filesFiler{Files: files})
+	if err != nil && err != licensedb.ErrNoLicenseFound {
+		return nil, err
+	}
+
+	// Find the highest matching license
+	var highest float32
+	current := ""
+	for id, v := range ms {
+		if v > 0.90 && v > highest {
+			highest = v
+			current = id
+		}
+	}
+
+	if current == "" {
+		names := make([]string, 0, len(files))
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var text strings.Builder
+		for _, name := range names {
+			text.Write(files[name])
+			text.WriteString("\n")
+		}
+
+		if lic, confidence := Detect(text.String()); confidence >= Threshold {
+			return lic, nil
+		}
+
+		return nil, nil
+	}
+
+	return &license.License{
+		Name:       current,
+		SPDX:       current,
+		Confidence: float64(highest),
+	}, nil
+}
+
+// IsLicenseFile returns true if the file name looks like a license file,
+// such as LICENSE, LICENSE.md or COPYING.
+func IsLicenseFile(name string) bool {
+	name = strings.ToLower(name)
+	for _, prefix := range licensePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// licensePrefixes are the lowercase prefixes of license file names.
+var licensePrefixes = []string{"license", "licence", "copying", "unlicense"}
+
+// filesFiler implements filer.Filer to return the license files given to
+// DetectFiles.
+type filesFiler struct {
+	Files map[string][]byte
+}
+
+func (f *filesFiler) ReadFile(name string) ([]byte, error) {
+	data, ok := f.Files[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown file: %s", name)
+	}
+
+	return data, nil
+}
+
+func (f *filesFiler) ReadDir(dir string) ([]filer.File, error) {
+	// We only support root
+	if dir != "" {
+		return nil, nil
+	}
+
+	result := make([]filer.File, 0, len(f.Files))
+	for name := range f.Files {
+		result = append(result, filer.File{Name: name})
+	}
+
+	return result, nil
+}
+
+func (f *filesFiler) Close() {}
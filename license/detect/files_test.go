@@ -0,0 +1,33 @@
+package detect
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectFiles(t *testing.T) {
+	mit, err := ioutil.ReadFile(filepath.Join("testdata", "mit.txt"))
+	require.NoError(t, err)
+
+	actual, err := DetectFiles(map[string][]byte{"LICENSE": mit})
+	require.NoError(t, err)
+	require.NotNil(t, actual)
+	require.Equal(t, "MIT", actual.SPDX)
+
+	// Nothing to detect
+	actual, err = DetectFiles(map[string][]byte{"LICENSE": []byte("All rights reserved.")})
+	require.NoError(t, err)
+	require.Nil(t, actual)
+}
+
+func TestIsLicenseFile(t *testing.T) {
+	for _, name := range []string{"LICENSE", "license.md", "LICENCE.txt", "COPYING", "UNLICENSE"} {
+		require.True(t, IsLicenseFile(name), name)
+	}
+	for _, name := range []string{"README.md", "go.mod", "NOTICE"} {
+		require.False(t, IsLicenseFile(name), name)
+	}
+}
@@ -0,0 +1,195 @@
+package license
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Expression is a parsed SPDX license expression such as "MIT",
+// "MIT OR Apache-2.0" or "GPL-2.0 WITH Classpath-exception-2.0".
+//
+// Compound expressions have an Op of "AND" or "OR" and their operands in
+// Args. Otherwise the expression is a single license ID, optionally with
+// an exception.
+type Expression struct {
+	Op   string
+	Args []*Expression
+
+	ID        string
+	Exception string
+}
+
+// ParseExpression parses an SPDX license expression. Operators are
+// matched case insensitively. WITH binds tighter than AND, which binds
+// tighter than OR, and parentheses can be used for grouping.
+func ParseExpression(s string) (*Expression, error) {
+	p := &exprParser{tokens: tokenize(s)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty license expression")
+	}
+
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid license expression %q: %s", s, err)
+	}
+	if tok := p.peek(); tok != "" {
+		return nil, fmt.Errorf("invalid license expression %q: unexpected %q", s, tok)
+	}
+
+	return e, nil
+}
+
+// Compound returns true if the expression is more than a single license
+// ID, meaning it combines licenses or has an exception.
+func (e *Expression) Compound() bool {
+	return e.Op != "" || e.Exception != ""
+}
+
+// String returns the expression in SPDX syntax.
+func (e *Expression) String() string {
+	if e.Op == "" {
+		if e.Exception != "" {
+			return e.ID + " WITH " + e.Exception
+		}
+
+		return e.ID
+	}
+
+	parts := make([]string, len(e.Args))
+	for i, arg := range e.Args {
+		parts[i] = arg.String()
+		if arg.Op != "" && arg.Op != e.Op {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+
+	return strings.Join(parts, " "+e.Op+" ")
+}
+
+type exprParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *exprParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+// parseOr parses "and-expression [OR and-expression]..."
+func (p *exprParser) parseOr() (*Expression, error) {
+	return p.parseOp("OR", p.parseAnd)
+}
+
+// parseAnd parses "with-expression [AND with-expression]..."
+func (p *exprParser) parseAnd() (*Expression, error) {
+	return p.parseOp("AND", p.parseWith)
+}
+
+func (p *exprParser) parseOp(op string, operand func() (*Expression, error)) (*Expression, error) {
+	e, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for strings.EqualFold(p.peek(), op) {
+		p.next()
+		arg, err := operand()
+		if err != nil {
+			return nil, err
+		}
+
+		if e.Op != op {
+			e = &Expression{Op: op, Args: []*Expression{e}}
+		}
+		e.Args = append(e.Args, arg)
+	}
+
+	return e, nil
+}
+
+// parseWith parses "license [WITH exception]" or a parenthesized
+// expression.
+func (p *exprParser) parseWith() (*Expression, error) {
+	tok := p.next()
+	switch {
+	case tok == "":
+		return nil, fmt.Errorf("unexpected end")
+
+	case tok == "(":
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing \")\"")
+		}
+
+		return e, nil
+
+	case isOperator(tok) || tok == ")":
+		return nil, fmt.Errorf("unexpected %q", tok)
+	}
+
+	e := &Expression{ID: tok}
+	if strings.EqualFold(p.peek(), "WITH") {
+		p.next()
+		exception := p.next()
+		if exception == "" || exception == "(" || exception == ")" || isOperator(exception) {
+			return nil, fmt.Errorf("missing exception after WITH")
+		}
+
+		e.Exception = exception
+	}
+
+	return e, nil
+}
+
+func isOperator(tok string) bool {
+	switch strings.ToUpper(tok) {
+	case "AND", "OR", "WITH":
+		return true
+	}
+
+	return false
+}
+
+// tokenize splits the expression into license IDs, operators and
+// parentheses.
+func tokenize(s string) []string {
+	var result []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			result = append(result, cur.String())
+			cur.Reset()
+		}
+	}
+
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')':
+			flush()
+			result = append(result, string(r))
+
+		case unicode.IsSpace(r):
+			flush()
+
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+
+	return result
+}
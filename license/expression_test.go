@@ -0,0 +1,47 @@
+package license
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpression(t *testing.T) {
+	cases := []struct {
+		Input    string
+		Output   string
+		Compound bool
+		Err      bool
+	}{
+		{"MIT", "MIT", false, false},
+		{"  Apache-2.0 ", "Apache-2.0", false, false},
+		{"GPL-2.0+", "GPL-2.0+", false, false},
+		{"MIT OR Apache-2.0", "MIT OR Apache-2.0", true, false},
+		{"MIT or Apache-2.0", "MIT OR Apache-2.0", true, false},
+		{"MIT AND BSD-3-Clause AND ISC", "MIT AND BSD-3-Clause AND ISC", true, false},
+		{"GPL-2.0 WITH Classpath-exception-2.0", "GPL-2.0 WITH Classpath-exception-2.0", true, false},
+		{"(GPL-2.0 WITH Classpath-exception-2.0)", "GPL-2.0 WITH Classpath-exception-2.0", true, false},
+		{"MIT OR Apache-2.0 AND BSD-3-Clause", "MIT OR (Apache-2.0 AND BSD-3-Clause)", true, false},
+		{"(MIT OR Apache-2.0) AND BSD-3-Clause", "(MIT OR Apache-2.0) AND BSD-3-Clause", true, false},
+		{"", "", false, true},
+		{"MIT OR", "", false, true},
+		{"OR MIT", "", false, true},
+		{"(MIT", "", false, true},
+		{"MIT)", "", false, true},
+		{"MIT Apache-2.0", "", false, true},
+		{"GPL-2.0 WITH", "", false, true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Input, func(t *testing.T) {
+			e, err := ParseExpression(tt.Input)
+			if tt.Err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.Output, e.String())
+			require.Equal(t, tt.Compound, e.Compound())
+		})
+	}
+}
@@ -2,17 +2,39 @@ package license
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/mitchellh/golicense/module"
 )
 
+// ErrNotFound is returned by a Finder, and by Find, if the module has no
+// license that it knows of. Unlike other errors this isn't a failure: the
+// lookup worked but there is no license. Use errors.Is to check for it.
+var ErrNotFound = errors.New("license not found")
+
 // Finder implementations can find a license for a given module.
 type Finder interface {
-	// License looks up the license for a given module.
+	// License looks up the license for a given module. If the finder
+	// has no license for the module it returns ErrNotFound. A nil license
+	// without an error is treated the same way.
 	License(context.Context, module.Module) (*License, error)
 }
 
+// OrNotFound returns the result of a lookup, with ErrNotFound in place
+// of a nil error if there is no license either. This is for finders whose
+// helpers return nil for both.
+func OrNotFound(l *License, err error) (*License, error) {
+	if l == nil && err == nil {
+		return nil, ErrNotFound
+	}
+
+	return l, err
+}
+
 // Translator implementations can convert one module path to another
 // module path that is more suitable for license lookup.
 type Translator interface {
@@ -41,10 +63,19 @@ func Translate(ctx context.Context, m module.Module, ts []Translator) module.Mod
 // a non-nil License without an error is returned. If a finder returns
 // an error, other finders are still attempted. It is possible for a non-nil
 // license to be returned WITH a non-nil error meaning a different lookup
-// failed.
+// failed. If no finder has a license and none failed, ErrNotFound is
+// returned, so a module without a license can be told apart from a failed
+// lookup.
+//
+// The order of the finders is their priority, so if two finders would
+// return different licenses the earlier one always wins. Finders of equal
+// priority can be grouped with EqualPriority.
 func Find(ctx context.Context, m module.Module, fs []Finder) (r *License, rerr error) {
 	for _, f := range fs {
-		lic, err := f.License(ctx, m)
+		lic, err := lookup(ctx, f, m)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
 		if err != nil {
 			rerr = multierror.Append(rerr, err)
 			continue
@@ -55,5 +86,93 @@ func Find(ctx context.Context, m module.Module, fs []Finder) (r *License, rerr e
 		}
 	}
 
-	return
+	return OrNotFound(r, rerr)
+}
+
+// EqualPriority is a Finder that queries all of the given finders and
+// deterministically picks one result if they disagree.
+//
+// If the finders return licenses with different SPDX IDs, the license with
+// the lexicographically smallest SPDX ID is returned with the other IDs in
+// its Conflicts, and a warning status is emitted noting the conflict. The
+// license returned by the finder isn't modified. Licenses without an SPDX
+// ID are only
+// used if no finder returned one with an SPDX ID, in which case the first
+// such license (by finder order) is returned. ErrNotFound is returned if
+// no finder has a license and none failed.
+type EqualPriority []Finder
+
+// License implements Finder
+func (fs EqualPriority) License(ctx context.Context, m module.Module) (*License, error) {
+	var rerr error
+	var first *License
+	found := map[string]*License{}
+	for _, f := range fs {
+		lic, err := lookup(ctx, f, m)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			rerr = multierror.Append(rerr, err)
+			continue
+		}
+		if lic == nil {
+			continue
+		}
+
+		if first == nil {
+			first = lic
+		}
+		if lic.SPDX != "" {
+			if _, ok := found[lic.SPDX]; !ok {
+				found[lic.SPDX] = lic
+			}
+		}
+	}
+
+	if len(found) == 0 {
+		return OrNotFound(first, rerr)
+	}
+
+	ids := make([]string, 0, len(found))
+	for id := range found {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	if len(ids) == 1 {
+		return found[ids[0]], rerr
+	}
+
+	UpdateStatus(ctx, StatusWarning, fmt.Sprintf(
+		"conflicting licenses %s, using %s", strings.Join(ids, ", "), ids[0]))
+	lic := *found[ids[0]]
+	lic.Conflicts = ids[1:]
+	return &lic, rerr
+}
+
+// MapFinders calls f for each finder and returns the finders it returns,
+// leaving out those for which it returns nil. The finders of an
+// EqualPriority group are mapped one by one, and a group left with a
+// single finder is replaced by it.
+func MapFinders(fs []Finder, f func(Finder) Finder) []Finder {
+	result := make([]Finder, 0, len(fs))
+	for _, finder := range fs {
+		if group, ok := finder.(EqualPriority); ok {
+			switch group = MapFinders(group, f); len(group) {
+			case 0:
+			case 1:
+				result = append(result, group[0])
+			default:
+				result = append(result, group)
+			}
+			continue
+		}
+
+		if finder = f(finder); finder != nil {
+			result = append(result, finder)
+		}
+	}
+
+	return result
 }
@@ -0,0 +1,193 @@
+package license
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFind_priority(t *testing.T) {
+	m := module.Module{Path: "github.com/foo/bar"}
+
+	var f1, f2 MockFinder
+	f1.On("License", mock.Anything, m).Return(&License{SPDX: "MIT"}, nil)
+	f2.On("License", mock.Anything, m).Return(&License{SPDX: "Apache-2.0"}, nil)
+
+	// The earlier finder always wins, regardless of the result
+	lic, err := Find(context.Background(), m, []Finder{&f2, &f1})
+	require.NoError(t, err)
+	require.Equal(t, "Apache-2.0", lic.SPDX)
+	f1.AssertNotCalled(t, "License", mock.Anything, m)
+}
+
+func TestEqualPriority(t *testing.T) {
+	m := module.Module{Path: "github.com/foo/bar"}
+
+	cases := []struct {
+		Name     string
+		Results  []*License
+		Expected string
+		Conflict bool
+	}{
+		{
+			"agree",
+			[]*License{{SPDX: "MIT"}, {SPDX: "MIT"}},
+			"MIT",
+			false,
+		},
+
+		{
+			"conflict",
+			[]*License{{SPDX: "MIT"}, {SPDX: "Apache-2.0"}},
+			"Apache-2.0",
+			true,
+		},
+
+		{
+			"conflict reversed",
+			[]*License{{SPDX: "Apache-2.0"}, {SPDX: "MIT"}},
+			"Apache-2.0",
+			true,
+		},
+
+		{
+			"prefers SPDX",
+			[]*License{{Name: "Custom"}, {SPDX: "MIT"}},
+			"MIT",
+			false,
+		},
+
+		{
+			"not found",
+			[]*License{nil, nil},
+			"",
+			false,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			var sl MockStatusListener
+			if tt.Conflict {
+				sl.On("UpdateStatus", StatusWarning, mock.Anything).Once()
+			}
+			ctx := StatusWithContext(context.Background(), &sl)
+
+			var fs EqualPriority
+			for _, r := range tt.Results {
+				var f MockFinder
+				f.On("License", mock.Anything, m).Return(r, nil)
+				fs = append(fs, &f)
+			}
+
+			lic, err := fs.License(ctx, m)
+			if tt.Expected == "" {
+				require.Nil(t, lic)
+				require.True(t, errors.Is(err, ErrNotFound), "%v", err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.Expected, lic.SPDX)
+				require.Equal(t, tt.Conflict, len(lic.Conflicts) > 0)
+			}
+			sl.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEqualPriority_error(t *testing.T) {
+	m := module.Module{Path: "github.com/foo/bar"}
+
+	var f1, f2 MockFinder
+	f1.On("License", mock.Anything, m).Return(nil, errors.New("failed"))
+	f2.On("License", mock.Anything, m).Return(&License{SPDX: "MIT"}, nil)
+
+	lic, err := EqualPriority{&f1, &f2}.License(context.Background(), m)
+	require.Error(t, err)
+	require.Equal(t, "MIT", lic.SPDX)
+}
+
+func TestEqualPriority_conflicts(t *testing.T) {
+	m := module.Module{Path: "github.com/foo/bar"}
+
+	mit := &License{SPDX: "MIT"}
+	var fs EqualPriority
+	for _, l := range []*License{mit, {SPDX: "Apache-2.0"}, {SPDX: "BSD-3-Clause"}} {
+		var f MockFinder
+		f.On("License", mock.Anything, m).Return(l, nil)
+		fs = append(fs, &f)
+	}
+
+	lic, err := fs.License(context.Background(), m)
+	require.NoError(t, err)
+	require.Equal(t, "Apache-2.0", lic.SPDX)
+	require.Equal(t, []string{"BSD-3-Clause", "MIT"}, lic.Conflicts)
+	require.Empty(t, mit.Conflicts)
+}
+
+func TestMapFinders(t *testing.T) {
+	a := &namedFinder{Finder: &MockFinder{}, Name: "a"}
+	b := &namedFinder{Finder: &MockFinder{}, Name: "b"}
+	c := &namedFinder{Finder: &MockFinder{}, Name: "c"}
+	keep := func(f Finder) Finder {
+		if f == b {
+			return nil
+		}
+		return f
+	}
+
+	// A group left with one finder is replaced by it
+	require.Equal(t, []Finder{a, c}, MapFinders([]Finder{EqualPriority{a, b}, c}, keep))
+	require.Equal(t, []Finder{EqualPriority{a, c}}, MapFinders([]Finder{EqualPriority{a, b, c}}, keep))
+	require.Empty(t, MapFinders([]Finder{EqualPriority{b}}, keep))
+}
+
+func TestFind_notFound(t *testing.T) {
+	m := module.Module{Path: "github.com/foo/bar"}
+
+	cases := []struct {
+		Name     string
+		Errors   []error
+		NotFound bool
+	}{
+		// Both the sentinel and a nil license mean the finder has none
+		{"not found", []error{ErrNotFound, nil}, true},
+
+		// A failed lookup isn't the same as no license
+		{"failed", []error{ErrNotFound, errors.New("failed")}, false},
+		{"transient", []error{Transient(errors.New("rate limited")), ErrNotFound}, false},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			var fs []Finder
+			for _, err := range tt.Errors {
+				var f MockFinder
+				f.On("License", mock.Anything, m).Return(nil, err)
+				fs = append(fs, &f)
+			}
+
+			lic, err := Find(context.Background(), m, fs)
+			require.Nil(t, lic)
+			require.Error(t, err)
+			require.Equal(t, tt.NotFound, errors.Is(err, ErrNotFound), "%v", err)
+		})
+	}
+}
+
+func TestOrNotFound(t *testing.T) {
+	lic, err := OrNotFound(nil, nil)
+	require.Nil(t, lic)
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	lic, err = OrNotFound(&License{SPDX: "MIT"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "MIT", lic.SPDX)
+
+	failed := errors.New("failed")
+	_, err = OrNotFound(nil, failed)
+	require.Equal(t, failed, err)
+}
@@ -7,14 +7,17 @@ import (
 	"github.com/google/go-github/v18/github"
 	"github.com/mitchellh/go-spdx"
 	"github.com/mitchellh/golicense/license"
+	textdetect "github.com/mitchellh/golicense/license/detect"
+	spdxdb "github.com/mitchellh/golicense/license/spdx"
 	"gopkg.in/src-d/go-license-detector.v2/licensedb"
 	"gopkg.in/src-d/go-license-detector.v2/licensedb/filer"
 )
 
-// detect uses go-license-detector as a fallback.
+// detect uses go-license-detector as a fallback, and our own license
+// templates if that doesn't find a license either.
 func detect(rl *github.RepositoryLicense) (*license.License, error) {
 	ms, err := licensedb.Detect(&filerImpl{License: rl})
-	if err != nil {
+	if err != nil && err != licensedb.ErrNoLicenseFound {
 		return nil, err
 	}
 
@@ -29,18 +32,37 @@ func detect(rl *github.RepositoryLicense) (*license.License, error) {
 	}
 
 	if current == "" {
+		text, err := base64.StdEncoding.DecodeString(rl.GetContent())
+		if err != nil {
+			return nil, err
+		}
+
+		if lic, confidence := textdetect.Detect(string(text)); confidence >= textdetect.Threshold {
+			return lic, nil
+		}
+
 		return nil, nil
 	}
 
 	// License detection only returns SPDX IDs but we want the complete name.
+	// This is only looked up over the network if it isn't embedded.
+	if l, ok := spdxdb.Get(current); ok {
+		return &license.License{
+			Name:       l.Name,
+			SPDX:       l.ID,
+			Confidence: float64(highest),
+		}, nil
+	}
+
 	lic, err := spdx.License(current)
 	if err != nil {
 		return nil, fmt.Errorf("error looking up license %q: %s", current, err)
 	}
 
 	return &license.License{
-		Name: lic.Name,
-		SPDX: lic.ID,
+		Name:       lic.Name,
+		SPDX:       lic.ID,
+		Confidence: float64(highest),
 	}, nil
 }
 
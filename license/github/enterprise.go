@@ -0,0 +1,38 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-github/v18/github"
+)
+
+// NewEnterpriseClient returns a client for the GitHub Enterprise instance
+// at the given URL along with its host, for use as RepoAPI.EnterpriseClient
+// and RepoAPI.EnterpriseHost. The URL can either be the instance itself,
+// such as "https://github.example.com", or its API endpoint. If it is the
+// instance, the default "/api/v3/" API path is used.
+func NewEnterpriseClient(rawURL string, httpClient *http.Client) (*github.Client, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+	if u.Host == "" {
+		return nil, "", fmt.Errorf("GitHub Enterprise URL %q has no host", rawURL)
+	}
+
+	base, upload := *u, *u
+	if strings.Trim(u.Path, "/") == "" {
+		base.Path = "/api/v3/"
+		upload.Path = "/api/uploads/"
+	}
+
+	client, err := github.NewEnterpriseClient(base.String(), upload.String(), httpClient)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return client, u.Hostname(), nil
+}
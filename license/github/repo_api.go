@@ -3,7 +3,12 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
 	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/go-github/v18/github"
@@ -23,50 +28,379 @@ import (
 // [1]: https://developer.github.com/v3/licenses/#get-the-contents-of-a-repositorys-license
 type RepoAPI struct {
 	Client *github.Client
+
+	// Clients is an optional pool of clients, such as one per token, that
+	// is used in turn instead of Client to multiply the rate limit. A
+	// client that is rate limited is skipped until its rate limit resets.
+	Clients []*github.Client
+
+	// EnterpriseHost is the host of a GitHub Enterprise instance, such as
+	// "github.example.com". Modules under this host are looked up with
+	// EnterpriseClient, which should be created with
+	// github.NewEnterpriseClient. If empty, only github.com is supported.
+	EnterpriseHost   string
+	EnterpriseClient *github.Client
+
+	// Ref is an optional mapping of module path to the git ref (tag, branch
+	// or SHA) to look up the license at. Modules not in the map use the
+	// commit of their pseudo-version, if they have one, or the default
+	// branch of the repository.
+	Ref map[string]string
+
+	// MaxRetries is the number of times a request is retried after being
+	// rate limited. If zero, DefaultMaxRetries is used. If negative, rate
+	// limited requests aren't retried.
+	MaxRetries int
+
+	// MaxWait is the longest time to wait for a rate limit to reset before
+	// retrying. If zero, DefaultMaxWait is used.
+	MaxWait time.Duration
+
+	lock    sync.Mutex
+	next    int         // index of the next client in Clients to use
+	limited []time.Time // when each client in Clients is usable again
 }
 
+const (
+	// DefaultMaxRetries is the default for RepoAPI.MaxRetries.
+	DefaultMaxRetries = 5
+
+	// DefaultMaxWait is the default for RepoAPI.MaxWait. The primary rate
+	// limit resets every hour, so this waits for it in the worst case.
+	DefaultMaxWait = time.Hour
+
+	// abuseWait is the time to wait after hitting a secondary (abuse) rate
+	// limit if GitHub doesn't tell us how long to wait.
+	abuseWait = time.Minute
+)
+
 // License implements license.Finder
 func (f *RepoAPI) License(ctx context.Context, m module.Module) (*license.License, error) {
-	matches := githubRe.FindStringSubmatch(m.Path)
-	if matches == nil {
-		return nil, nil
-	}
-
-FETCH_RETRY:
-	license.UpdateStatus(ctx, license.StatusNormal, "querying license")
-	rl, _, err := f.Client.Repositories.License(ctx, matches[1], matches[2])
-	if rateErr, ok := err.(*github.RateLimitError); ok {
-		dur := time.Until(rateErr.Rate.Reset.Time)
-		timer := time.NewTimer(dur)
-		defer timer.Stop()
-		license.UpdateStatus(ctx, license.StatusWarning, fmt.Sprintf(
-			"rate limited by GitHub, waiting %s", dur))
+	enterprise, owner, repo, ok := f.repo(m.Path)
+	if !ok {
+		return nil, license.ErrNotFound
+	}
 
-		select {
-		case <-ctx.Done():
-			// Context cancelled or ended so return early
-			return nil, ctx.Err()
+	// A pseudo-version is an untagged commit, whose license may differ
+	// from the one on the default branch
+	ref := f.Ref[m.Path]
+	if commit := m.PseudoCommit(); ref == "" && commit != "" {
+		license.UpdateStatus(ctx, license.StatusNormal, fmt.Sprintf(
+			"pseudo-version, looking up license at commit %s", commit))
+		ref = commit
+	}
 
-		case <-timer.C:
-			// Rate limit should be up, retry
-			goto FETCH_RETRY
-		}
+	rl, err := f.query(ctx, enterprise, owner, repo, ref)
+	if newOwner, newRepo, ok := f.moved(ctx, enterprise, err); ok {
+		// The repository was renamed or transferred. The HTTP client
+		// usually follows the redirect to it, but not if it is configured
+		// to return redirects, so retry at the new location.
+		license.UpdateStatus(ctx, license.StatusWarning, fmt.Sprintf(
+			"repository moved to %s/%s", newOwner, newRepo))
+		rl, err = f.query(ctx, enterprise, newOwner, newRepo, ref)
+	}
+	if rerr, ok := err.(*github.ErrorResponse); ok && rerr.Response != nil &&
+		rerr.Response.StatusCode == http.StatusNotFound {
+		// The repository doesn't exist or has no license file
+		return nil, license.ErrNotFound
 	}
 	if err != nil {
-		return nil, err
+		return nil, transient(err)
 	}
 
 	// If the license type is "other" then we try to use go-license-detector
 	// to determine the license, which seems to be accurate in these cases.
+	lic := &license.License{
+		Name: rl.GetLicense().GetName(),
+		SPDX: rl.GetLicense().GetSPDXID(),
+	}
 	if rl.GetLicense().GetKey() == "other" {
-		return detect(rl)
+		lic, err = detect(rl)
+		if err != nil {
+			return nil, err
+		}
+		if lic == nil {
+			return nil, license.ErrNotFound
+		}
 	}
 
-	return &license.License{
-		Name: rl.GetLicense().GetName(),
-		SPDX: rl.GetLicense().GetSPDXID(),
-	}, nil
+	// The license file that GitHub detected the license from
+	lic.URL = rl.GetHTMLURL()
+	lic.Source = "github"
+	return lic, nil
+}
+
+// Host implements license.HostFinder. Modules on github.com and on the
+// GitHub Enterprise instance are looked up on different hosts, which fail
+// independently.
+func (f *RepoAPI) Host(m module.Module) string {
+	enterprise, _, _, ok := f.repo(m.Path)
+	switch {
+	case !ok:
+		return ""
+	case enterprise:
+		return strings.ToLower(f.EnterpriseHost)
+	}
+
+	return "github.com"
+}
+
+// query fetches the license of a repository, retrying while it is rate
+// limited.
+func (f *RepoAPI) query(ctx context.Context, enterprise bool, owner, repo, ref string) (*github.RepositoryLicense, error) {
+	maxRetries := f.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	}
+
+	for attempt := 0; ; {
+		client := f.EnterpriseClient
+		if !enterprise {
+			client = f.client()
+		}
+
+		license.UpdateStatus(ctx, license.StatusNormal, "querying license")
+		rl, _, err := f.license(ctx, client, owner, repo, ref)
+
+		if reset, limited := rateLimit(err); limited && !enterprise && f.setLimited(client, reset) {
+			// Another client isn't rate limited so retry with it right away.
+			license.UpdateStatus(ctx, license.StatusWarning,
+				"rate limited by GitHub, switching token")
+			continue
+		}
+
+		dur, limited := f.retryAfter(err)
+		if !limited || attempt >= maxRetries {
+			return rl, err
+		}
+
+		attempt++
+		license.UpdateStatus(ctx, license.StatusWarning, fmt.Sprintf(
+			"rate limited by GitHub, waiting %s (retry %d of %d)",
+			dur, attempt, maxRetries))
+		if err := sleep(ctx, dur); err != nil {
+			// Context cancelled or ended so return early
+			return nil, err
+		}
+	}
+}
+
+// moved returns the new owner and name of a repository that was renamed or
+// transferred, if err is the redirect GitHub answers requests for its old
+// name with. The redirect points at the repository by its ID, such as
+// /repositories/1234/license, which is looked up for the new name.
+func (f *RepoAPI) moved(ctx context.Context, enterprise bool, err error) (string, string, bool) {
+	rerr, ok := err.(*github.ErrorResponse)
+	if !ok || rerr.Response == nil {
+		return "", "", false
+	}
+	switch rerr.Response.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return "", "", false
+	}
+
+	loc, lerr := rerr.Response.Location()
+	if lerr != nil {
+		return "", "", false
+	}
+	match := repositoryIDRe.FindStringSubmatch(loc.Path)
+	if match == nil {
+		return "", "", false
+	}
+	id, perr := strconv.ParseInt(match[1], 10, 64)
+	if perr != nil {
+		return "", "", false
+	}
+
+	client := f.EnterpriseClient
+	if !enterprise {
+		client = f.client()
+	}
+	r, _, gerr := client.Repositories.GetByID(ctx, id)
+	if gerr != nil || r.GetOwner().GetLogin() == "" || r.GetName() == "" {
+		return "", "", false
+	}
+
+	return r.GetOwner().GetLogin(), r.GetName(), true
+}
+
+// repositoryIDRe matches the path of a repository by its ID, which GitHub
+// redirects to for renamed and transferred repositories.
+var repositoryIDRe = regexp.MustCompile(`/repositories/([0-9]+)(?:/|$)`)
+
+// repo returns the owner and name of the GitHub repository of the module
+// path, and whether it is hosted on the configured GitHub Enterprise
+// instance rather than github.com. The path may be in a subdirectory of the
+// repository or be a gopkg.in path, see module.ParseRepo. This returns
+// false if the module isn't hosted on either.
+func (f *RepoAPI) repo(path string) (bool, string, string, bool) {
+	r, ok := module.ParseRepo(path)
+	switch {
+	case !ok:
+		return false, "", "", false
+
+	case r.Host == "github.com":
+		return false, r.Owner, r.Name, true
+
+	case f.EnterpriseHost != "" && strings.EqualFold(r.Host, f.EnterpriseHost):
+		return true, r.Owner, r.Name, true
+
+	default:
+		return false, "", "", false
+	}
+}
+
+// client returns the client to use for github.com. With a pool of
+// Clients, this is the next one that isn't rate limited. If all of them
+// are, the one whose rate limit resets first is used.
+func (f *RepoAPI) client() *github.Client {
+	if len(f.Clients) == 0 {
+		return f.Client
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if len(f.limited) != len(f.Clients) {
+		f.limited = make([]time.Time, len(f.Clients))
+	}
+
+	now := time.Now()
+	earliest := f.next % len(f.Clients)
+	for i := range f.Clients {
+		idx := (f.next + i) % len(f.Clients)
+		if !f.limited[idx].After(now) {
+			earliest = idx
+			break
+		}
+		if f.limited[idx].Before(f.limited[earliest]) {
+			earliest = idx
+		}
+	}
+
+	f.next = earliest + 1
+	return f.Clients[earliest]
+}
+
+// setLimited records that the client is rate limited for the given
+// duration. This returns true if another client in the pool isn't rate
+// limited and can be used instead.
+func (f *RepoAPI) setLimited(client *github.Client, dur time.Duration) bool {
+	if len(f.Clients) < 2 {
+		return false
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if len(f.limited) != len(f.Clients) {
+		f.limited = make([]time.Time, len(f.Clients))
+	}
+
+	now := time.Now()
+	available := false
+	for i, c := range f.Clients {
+		if c == client {
+			f.limited[i] = now.Add(dur)
+			continue
+		}
+		if !f.limited[i].After(now) {
+			available = true
+		}
+	}
+
+	return available
+}
+
+// retryAfter returns how long to wait before retrying if the error is
+// caused by a rate limit. The wait is capped at MaxWait.
+func (f *RepoAPI) retryAfter(err error) (time.Duration, bool) {
+	dur, ok := rateLimit(err)
+	if !ok {
+		return 0, false
+	}
+
+	max := f.MaxWait
+	if max == 0 {
+		max = DefaultMaxWait
+	}
+	if dur > max {
+		dur = max
+	}
+	if dur < 0 {
+		dur = 0
+	}
+
+	return dur, true
+}
+
+// rateLimit returns how long until the rate limit resets if the error is
+// caused by a rate limit.
+func rateLimit(err error) (time.Duration, bool) {
+	switch err := err.(type) {
+	case *github.RateLimitError:
+		return time.Until(err.Rate.Reset.Time), true
+
+	case *github.AbuseRateLimitError:
+		if err.RetryAfter != nil {
+			return *err.RetryAfter, true
+		}
+
+		return abuseWait, true
+
+	default:
+		return 0, false
+	}
+}
+
+// transient wraps the error as a license.TransientError if it is caused
+// by a rate limit or a server error, so that the lookup isn't repeated
+// with a translated module.
+func transient(err error) error {
+	if _, limited := rateLimit(err); limited {
+		return license.Transient(err)
+	}
+	if rerr, ok := err.(*github.ErrorResponse); ok && rerr.Response != nil {
+		return license.HTTPError(rerr.Response.StatusCode, err)
+	}
+
+	return err
 }
 
-// githubRe is the regexp matching the package for a GitHub import.
-var githubRe = regexp.MustCompile(`^github\.com/([^/]+)/([^/]+)$`)
+// sleep waits for the given duration or until the context is done.
+func sleep(ctx context.Context, dur time.Duration) error {
+	timer := time.NewTimer(dur)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+
+	case <-timer.C:
+		return nil
+	}
+}
+
+// license fetches the repository license, optionally at the given ref.
+// go-github doesn't expose the ref parameter so we build the request
+// ourselves in that case.
+func (f *RepoAPI) license(ctx context.Context, client *github.Client, owner, repo, ref string) (*github.RepositoryLicense, *github.Response, error) {
+	if ref == "" {
+		return client.Repositories.License(ctx, owner, repo)
+	}
+
+	license.UpdateStatus(ctx, license.StatusNormal, fmt.Sprintf(
+		"querying license at ref %q", ref))
+	u := fmt.Sprintf("repos/%s/%s/license?ref=%s", owner, repo, url.QueryEscape(ref))
+	req, err := client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rl github.RepositoryLicense
+	resp, err := client.Do(ctx, req, &rl)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &rl, resp, nil
+}
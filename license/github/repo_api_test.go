@@ -0,0 +1,400 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v18/github"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+// testClient returns a GitHub client that talks to the given handler.
+func testClient(t *testing.T, h http.HandlerFunc) *github.Client {
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL + "/")
+	require.NoError(t, err)
+	c := github.NewClient(nil)
+	c.BaseURL = u
+	return c
+}
+
+const testLicenseJSON = `{"license":{"key":"mit","name":"MIT License","spdx_id":"MIT"}}`
+
+func TestRepoAPI_ref(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Ref     map[string]string
+		Version string
+		Want    string
+	}{
+		{
+			"no ref",
+			nil,
+			"v1.0.0",
+			"",
+		},
+
+		{
+			"ref",
+			map[string]string{"github.com/foo/bar": "v1.2.3"},
+			"v1.0.0",
+			"v1.2.3",
+		},
+
+		{
+			"pseudo-version",
+			nil,
+			"v0.0.0-20190312203227-4b39c73a6495",
+			"4b39c73a6495",
+		},
+
+		{
+			"ref takes precedence over pseudo-version",
+			map[string]string{"github.com/foo/bar": "v1.2.3"},
+			"v0.0.0-20190312203227-4b39c73a6495",
+			"v1.2.3",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			var ref string
+			f := &RepoAPI{
+				Client: testClient(t, func(w http.ResponseWriter, r *http.Request) {
+					require.Equal(t, "/repos/foo/bar/license", r.URL.Path)
+					ref = r.URL.Query().Get("ref")
+					w.Write([]byte(testLicenseJSON))
+				}),
+				Ref: tt.Ref,
+			}
+
+			lic, err := f.License(context.Background(), module.Module{
+				Path:    "github.com/foo/bar",
+				Version: tt.Version,
+			})
+			require.NoError(t, err)
+			require.Equal(t, "MIT", lic.SPDX)
+			require.Equal(t, tt.Want, ref)
+		})
+	}
+}
+
+func TestRepoAPI_url(t *testing.T) {
+	f := &RepoAPI{
+		Client: testClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{
+				"html_url": "https://github.com/foo/bar/blob/master/LICENSE",
+				"license": {"key": "mit", "name": "MIT License", "spdx_id": "MIT"}
+			}`))
+		}),
+	}
+
+	lic, err := f.License(context.Background(), module.Module{Path: "github.com/foo/bar"})
+	require.NoError(t, err)
+	require.Equal(t, &license.License{
+		Name:   "MIT License",
+		SPDX:   "MIT",
+		URL:    "https://github.com/foo/bar/blob/master/LICENSE",
+		Source: "github",
+	}, lic)
+}
+
+func TestRepoAPI_rateLimit(t *testing.T) {
+	rateLimited := func(w http.ResponseWriter) {
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(-time.Second).Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"API rate limit exceeded for 127.0.0.1."}`))
+	}
+
+	abuse := func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"You have triggered an abuse detection mechanism.","documentation_url":"https://developer.github.com/v3/#abuse-rate-limits"}`))
+	}
+
+	cases := []struct {
+		Name       string
+		Limited    int
+		Limit      func(http.ResponseWriter)
+		MaxRetries int
+		Requests   int
+		Err        bool
+	}{
+		{
+			"primary rate limit once",
+			1,
+			rateLimited,
+			0,
+			2,
+			false,
+		},
+
+		{
+			"secondary rate limit once",
+			1,
+			abuse,
+			0,
+			2,
+			false,
+		},
+
+		{
+			"retries exhausted",
+			10,
+			rateLimited,
+			2,
+			3,
+			true,
+		},
+
+		{
+			"retries disabled",
+			1,
+			rateLimited,
+			-1,
+			1,
+			true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			requests := 0
+			f := &RepoAPI{
+				Client: testClient(t, func(w http.ResponseWriter, r *http.Request) {
+					requests++
+					if requests <= tt.Limited {
+						tt.Limit(w)
+						return
+					}
+
+					w.Write([]byte(testLicenseJSON))
+				}),
+				MaxRetries: tt.MaxRetries,
+				MaxWait:    time.Millisecond,
+			}
+
+			lic, err := f.License(context.Background(), module.Module{
+				Path: "github.com/foo/bar",
+			})
+			require.Equal(t, tt.Requests, requests)
+			if tt.Err {
+				// The rate limit may be over on a later run
+				require.Error(t, err)
+				require.True(t, license.IsTransient(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "MIT", lic.SPDX)
+		})
+	}
+}
+
+func TestRepoAPI_enterprise(t *testing.T) {
+	var public, enterprise []string
+	f := &RepoAPI{
+		Client: testClient(t, func(w http.ResponseWriter, r *http.Request) {
+			public = append(public, r.URL.Path)
+			w.Write([]byte(testLicenseJSON))
+		}),
+		EnterpriseHost: "github.example.com",
+		EnterpriseClient: testClient(t, func(w http.ResponseWriter, r *http.Request) {
+			enterprise = append(enterprise, r.URL.Path)
+			w.Write([]byte(testLicenseJSON))
+		}),
+	}
+
+	cases := []struct {
+		Path  string
+		Found bool
+		Host  string
+	}{
+		{"github.example.com/foo/bar", true, "github.example.com"},
+		{"github.com/foo/public", true, "github.com"},
+		{"github.example.com/foo/bar/sub", true, "github.example.com"},
+		{"gitlab.example.com/foo/bar", false, ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Path, func(t *testing.T) {
+			require.Equal(t, tt.Host, f.Host(module.Module{Path: tt.Path}))
+
+			lic, err := f.License(context.Background(), module.Module{Path: tt.Path})
+			if tt.Found {
+				require.NoError(t, err)
+			} else {
+				require.True(t, errors.Is(err, license.ErrNotFound), "%v", err)
+			}
+			require.Equal(t, tt.Found, lic != nil)
+		})
+	}
+
+	require.Equal(t, []string{"/repos/foo/bar/license", "/repos/foo/bar/license"}, enterprise)
+	require.Equal(t, []string{"/repos/foo/public/license"}, public)
+}
+
+func TestRepoAPI_subpath(t *testing.T) {
+	cases := []struct {
+		Path string
+		URL  string
+	}{
+		{"gopkg.in/yaml.v2", "/repos/go-yaml/yaml/license"},
+		{"github.com/foo/bar/v3", "/repos/foo/bar/license"},
+		{"github.com/foo/bar/subpkg", "/repos/foo/bar/license"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Path, func(t *testing.T) {
+			var paths []string
+			f := &RepoAPI{
+				Client: testClient(t, func(w http.ResponseWriter, r *http.Request) {
+					paths = append(paths, r.URL.Path)
+					w.Write([]byte(testLicenseJSON))
+				}),
+			}
+
+			lic, err := f.License(context.Background(), module.Module{Path: tt.Path})
+			require.NoError(t, err)
+			require.Equal(t, "MIT", lic.SPDX)
+			require.Equal(t, []string{tt.URL}, paths)
+		})
+	}
+}
+
+func TestRepoAPI_notFound(t *testing.T) {
+	f := &RepoAPI{
+		Client: testClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Not Found"}`))
+		}),
+	}
+
+	// A repository without a license isn't a failed lookup
+	lic, err := f.License(context.Background(), module.Module{Path: "github.com/foo/bar"})
+	require.Nil(t, lic)
+	require.True(t, errors.Is(err, license.ErrNotFound), "%v", err)
+	require.False(t, license.IsTransient(err))
+}
+
+func TestRepoAPI_moved(t *testing.T) {
+	// The old name redirects to the repository by ID, which now has the
+	// name newowner/baz
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/foo/bar/license":
+			w.Header().Set("Location", "/repositories/42/license")
+			w.WriteHeader(http.StatusMovedPermanently)
+			w.Write([]byte(`{"message": "Moved Permanently"}`))
+
+		case "/repositories/42":
+			w.Write([]byte(`{"id": 42, "name": "baz", "owner": {"login": "newowner"}}`))
+
+		case "/repositories/42/license", "/repos/newowner/baz/license":
+			w.Write([]byte(`{"license":{"key":"mit","name":"MIT License","spdx_id":"MIT"},` +
+				`"html_url":"https://github.com/newowner/baz/blob/master/LICENSE"}`))
+
+		default:
+			http.NotFound(w, r)
+		}
+	}
+
+	t.Run("followed", func(t *testing.T) {
+		f := &RepoAPI{Client: testClient(t, handler)}
+		lic, err := f.License(context.Background(), module.Module{Path: "github.com/foo/bar"})
+		require.NoError(t, err)
+		require.Equal(t, "MIT", lic.SPDX)
+	})
+
+	t.Run("returned", func(t *testing.T) {
+		// A client that doesn't follow redirects gets the redirect itself
+		srv := httptest.NewServer(http.HandlerFunc(handler))
+		defer srv.Close()
+		u, err := url.Parse(srv.URL + "/")
+		require.NoError(t, err)
+		c := github.NewClient(&http.Client{
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		})
+		c.BaseURL = u
+
+		f := &RepoAPI{Client: c}
+		lic, err := f.License(context.Background(), module.Module{Path: "github.com/foo/bar"})
+		require.NoError(t, err)
+		require.Equal(t, "MIT", lic.SPDX)
+		require.Equal(t, "https://github.com/newowner/baz/blob/master/LICENSE", lic.URL)
+	})
+}
+
+func TestNewEnterpriseClient(t *testing.T) {
+	cases := []struct {
+		URL  string
+		Base string
+		Err  bool
+	}{
+		{"https://github.example.com", "https://github.example.com/api/v3/", false},
+		{"https://github.example.com/", "https://github.example.com/api/v3/", false},
+		{"https://github.example.com/custom/api", "https://github.example.com/custom/api/", false},
+		{"github.example.com", "", true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.URL, func(t *testing.T) {
+			client, host, err := NewEnterpriseClient(tt.URL, nil)
+			if tt.Err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "github.example.com", host)
+			require.Equal(t, tt.Base, client.BaseURL.String())
+		})
+	}
+}
+
+func TestRepoAPI_clients(t *testing.T) {
+	var first, second int
+	f := &RepoAPI{
+		Clients: []*github.Client{
+			testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				first++
+				w.Header().Set("X-RateLimit-Limit", "5000")
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"message":"API rate limit exceeded for 127.0.0.1."}`))
+			}),
+			testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				second++
+				w.Write([]byte(testLicenseJSON))
+			}),
+		},
+		MaxWait: time.Millisecond,
+	}
+
+	// The first client is rate limited so the second one is used instead,
+	// without waiting for the rate limit to reset.
+	lic, err := f.License(context.Background(), module.Module{Path: "github.com/foo/bar"})
+	require.NoError(t, err)
+	require.Equal(t, "MIT", lic.SPDX)
+	require.Equal(t, 1, first)
+	require.Equal(t, 1, second)
+
+	// The first client is skipped until its rate limit resets
+	for i := 0; i < 3; i++ {
+		_, err := f.License(context.Background(), module.Module{Path: "github.com/foo/bar"})
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, first)
+	require.Equal(t, 4, second)
+}
@@ -2,8 +2,7 @@ package gopkg
 
 import (
 	"context"
-	"fmt"
-	"regexp"
+	"strings"
 
 	"github.com/mitchellh/golicense/module"
 )
@@ -11,22 +10,16 @@ import (
 type Translator struct{}
 
 func (t Translator) Translate(ctx context.Context, m module.Module) (module.Module, bool) {
-	ms := re.FindStringSubmatch(m.Path)
-	if ms == nil {
+	if !strings.HasPrefix(strings.ToLower(m.Path), "gopkg.in/") {
 		return module.Module{}, false
 	}
 
-	// URL case 1 with no user means it is go-<pkg>
-	if ms[1] == "" {
-		ms[1] = "go-" + ms[2]
+	// gopkg.in redirects to GitHub, so convert to the GitHub repository
+	r, ok := module.ParseRepo(m.Path)
+	if !ok {
+		return module.Module{}, false
 	}
 
-	// Matches, convert to github
-	m.Path = fmt.Sprintf("github.com/%s/%s", ms[1], ms[2])
+	m.Path = r.String()
 	return m, true
 }
-
-// re is the regexp matching the package for a GoPkg import. This is taken
-// almost directly from the GoPkg source code itself so it should match
-// perfectly.
-var re = regexp.MustCompile(`(?i)^gopkg\.in/(?:([a-zA-Z0-9][-a-zA-Z0-9]+)/)?([a-zA-Z][-.a-zA-Z0-9]*)\.((?:v0|v[1-9][0-9]*)(?:\.0|\.[1-9][0-9]*){0,2}(?:-unstable)?)(?:\.git)?((?:/[a-zA-Z0-9][-.a-zA-Z0-9]*)*)$`)
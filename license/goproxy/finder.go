@@ -0,0 +1,236 @@
+package goproxy
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/mitchellh/golicense/license"
+	textdetect "github.com/mitchellh/golicense/license/detect"
+	"github.com/mitchellh/golicense/module"
+)
+
+// DefaultGOPROXY is the module proxy list used if GOPROXY isn't set, the
+// same as the go command's default.
+const DefaultGOPROXY = "https://proxy.golang.org,direct"
+
+// maxZipSize is the largest module zip that is downloaded. This is the
+// limit the go command enforces for module zips. Zips are downloaded to a
+// temporary file rather than into memory, so this doesn't limit memory.
+const maxZipSize = 500 << 20
+
+// Finder implements license.Finder and detects the license of a module
+// from the license files in its zip on the module proxy. This works for
+// modules on any host, and for the exact module version.
+type Finder struct {
+	// Client is the HTTP client to use. http.DefaultClient is used if nil.
+	Client *http.Client
+
+	// GOPROXY is the list of module proxies in the format of the GOPROXY
+	// environment variable. If empty, the GOPROXY environment variable is
+	// used, or DefaultGOPROXY if that isn't set either.
+	GOPROXY string
+}
+
+// License implements license.Finder
+func (f *Finder) License(ctx context.Context, m module.Module) (*license.License, error) {
+	if m.Version == "" {
+		return nil, license.ErrNotFound
+	}
+
+	goproxy := f.GOPROXY
+	if goproxy == "" {
+		goproxy = os.Getenv("GOPROXY")
+	}
+	if goproxy == "" {
+		goproxy = DefaultGOPROXY
+	}
+
+	for _, p := range m.Paths() {
+		zf, size, err := f.download(ctx, goproxy, p, m.Version)
+		if err != nil {
+			return nil, err
+		}
+		if zf == nil {
+			continue
+		}
+		defer removeTemp(zf)
+
+		license.UpdateStatus(ctx, license.StatusNormal, "detecting license in module zip")
+		lic, err := detectZip(zf, size)
+		if lic != nil {
+			lic.Source = "goproxy"
+		}
+
+		return license.OrNotFound(lic, err)
+	}
+
+	return nil, license.ErrNotFound
+}
+
+// download fetches the module zip, trying each proxy in the list the way
+// the go command does. Proxies separated by a comma are only skipped if
+// they don't have the module, while proxies separated by a pipe are also
+// skipped on errors. "direct" and "off" end the list since we can't fetch
+// from version control. The zip is written to a temporary file, which the
+// caller must remove with removeTemp, along with its size. This returns
+// nil if no proxy has the module.
+func (f *Finder) download(ctx context.Context, goproxy, modPath, version string) (*os.File, int64, error) {
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var lastErr error
+	for _, entry := range parseGOPROXY(goproxy) {
+		if entry.URL == "direct" || entry.URL == "off" {
+			break
+		}
+
+		u := fmt.Sprintf("%s/%s/@v/%s.zip",
+			strings.TrimSuffix(entry.URL, "/"), escapePath(modPath), escapePath(version))
+		license.UpdateStatus(ctx, license.StatusNormal, "downloading module zip")
+		zf, size, err := get(ctx, client, u)
+		if err == nil {
+			return zf, size, nil
+		}
+		if err == errNotFound {
+			lastErr = nil
+			continue
+		}
+
+		lastErr = err
+		if !entry.FallbackOnError {
+			break
+		}
+	}
+
+	return nil, 0, lastErr
+}
+
+// errNotFound is returned by get if the proxy doesn't have the module.
+var errNotFound = fmt.Errorf("module not found")
+
+// get downloads the module zip at u to a temporary file and returns it
+// with its size.
+func get(ctx context.Context, client *http.Client, u string) (*os.File, int64, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusGone:
+		return nil, 0, errNotFound
+	default:
+		return nil, 0, license.HTTPError(resp.StatusCode,
+			fmt.Errorf("module proxy returned %s for %s", resp.Status, u))
+	}
+
+	zf, err := ioutil.TempFile("", "golicense-*.zip")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	size, err := io.Copy(zf, io.LimitReader(resp.Body, maxZipSize+1))
+	if err == nil && size > maxZipSize {
+		err = fmt.Errorf("module zip %s is larger than %d bytes", u, maxZipSize)
+	}
+	if err != nil {
+		removeTemp(zf)
+		return nil, 0, err
+	}
+
+	return zf, size, nil
+}
+
+// removeTemp closes and removes a temporary file created by get.
+func removeTemp(f *os.File) {
+	f.Close()
+	os.Remove(f.Name())
+}
+
+// proxyEntry is a single proxy in a GOPROXY list.
+type proxyEntry struct {
+	URL string
+
+	// FallbackOnError is true if the next proxy is tried on any error,
+	// not just if the module isn't found.
+	FallbackOnError bool
+}
+
+// parseGOPROXY parses a GOPROXY list, where proxies are separated by a
+// comma or a pipe.
+func parseGOPROXY(s string) []proxyEntry {
+	var result []proxyEntry
+	for s != "" {
+		i := strings.IndexAny(s, ",|")
+		if i < 0 {
+			result = append(result, proxyEntry{URL: strings.TrimSpace(s)})
+			break
+		}
+
+		if u := strings.TrimSpace(s[:i]); u != "" {
+			result = append(result, proxyEntry{URL: u, FallbackOnError: s[i] == '|'})
+		}
+		s = s[i+1:]
+	}
+
+	return result
+}
+
+// detectZip classifies the license files in the root of the module zip.
+func detectZip(r io.ReaderAt, size int64) (*license.License, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	for _, zf := range zr.File {
+		// Files are stored as "<module>@<version>/<name>"
+		i := strings.Index(zf.Name, "@")
+		if i < 0 {
+			continue
+		}
+		rest := zf.Name[i:]
+		j := strings.Index(rest, "/")
+		if j < 0 {
+			continue
+		}
+		name := rest[j+1:]
+		if name == "" || strings.Contains(name, "/") || !textdetect.IsLicenseFile(path.Base(name)) {
+			continue
+		}
+
+		rc, err := zf.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		files[name] = content
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	return textdetect.DetectFiles(files)
+}
@@ -0,0 +1,129 @@
+package goproxy
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinder(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		switch r.URL.Path {
+		case "/good/example.com/mit/v2/@v/v2.1.0.zip":
+			http.ServeFile(w, r, filepath.Join("testdata", "mit.zip"))
+
+		case "/broken/example.com/mit/v2/@v/v2.1.0.zip":
+			w.WriteHeader(http.StatusInternalServerError)
+
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	m := module.Module{Path: "example.com/mit", Version: "v2.1.0"}
+	mit := &license.License{Name: "MIT", SPDX: "MIT", Source: "goproxy"}
+
+	cases := []struct {
+		Name     string
+		GOPROXY  string
+		Result   *license.License
+		Err      bool
+		Requests []string
+	}{
+		{
+			"found",
+			srv.URL + "/good",
+			mit,
+			false,
+			[]string{"/good/example.com/mit/v2/@v/v2.1.0.zip"},
+		},
+
+		{
+			"not found falls through",
+			srv.URL + "/missing," + srv.URL + "/good",
+			mit,
+			false,
+			[]string{
+				"/missing/example.com/mit/v2/@v/v2.1.0.zip",
+				"/good/example.com/mit/v2/@v/v2.1.0.zip",
+			},
+		},
+
+		{
+			"error stops at comma",
+			srv.URL + "/broken," + srv.URL + "/good",
+			nil,
+			true,
+			[]string{"/broken/example.com/mit/v2/@v/v2.1.0.zip"},
+		},
+
+		{
+			"error falls through at pipe",
+			srv.URL + "/broken|" + srv.URL + "/good",
+			mit,
+			false,
+			[]string{
+				"/broken/example.com/mit/v2/@v/v2.1.0.zip",
+				"/good/example.com/mit/v2/@v/v2.1.0.zip",
+			},
+		},
+
+		{
+			"direct ends the list",
+			srv.URL + "/missing,direct," + srv.URL + "/good",
+			nil,
+			false,
+			[]string{
+				"/missing/example.com/mit/v2/@v/v2.1.0.zip",
+				"/missing/example.com/mit/@v/v2.1.0.zip",
+			},
+		},
+
+		{
+			"off",
+			"off",
+			nil,
+			false,
+			nil,
+		},
+	}
+
+	// The zips are downloaded to temporary files, which are removed again
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			requests = nil
+			f := &Finder{GOPROXY: tt.GOPROXY}
+			actual, err := f.License(context.Background(), m)
+			// A module without a license isn't an error
+			if tt.Result == nil && !tt.Err {
+				require.True(t, errors.Is(err, license.ErrNotFound), "%v", err)
+				err = nil
+			}
+			require.Equal(t, tt.Err, err != nil, "%v", err)
+			if actual != nil {
+				require.GreaterOrEqual(t, actual.Confidence, 0.9)
+				actual.Confidence = 0
+			}
+			require.Equal(t, tt.Result, actual)
+			require.Equal(t, tt.Requests, requests)
+
+			entries, err := ioutil.ReadDir(tmp)
+			require.NoError(t, err)
+			require.Empty(t, entries)
+		})
+	}
+}
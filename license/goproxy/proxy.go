@@ -0,0 +1,103 @@
+package goproxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultURL is the module proxy used if no URL is configured.
+const DefaultURL = "https://proxy.golang.org"
+
+// Proxy is a minimal client for the module proxy protocol.
+type Proxy struct {
+	// Client is the HTTP client to use. http.DefaultClient is used if nil.
+	Client *http.Client
+
+	// URL is the base URL of the module proxy. DefaultURL is used if empty.
+	URL string
+}
+
+// Info is the version metadata returned by the module proxy.
+type Info struct {
+	Version string
+	Time    time.Time
+
+	// Origin is only set for versions fetched by newer versions of the go
+	// tool, so it may be nil.
+	Origin *Origin
+}
+
+// Origin is where the module proxy fetched a module version from.
+type Origin struct {
+	VCS    string
+	URL    string
+	Subdir string
+}
+
+// Info requests the metadata for a module version. If version is empty,
+// the latest version is requested.
+func (p *Proxy) Info(ctx context.Context, path, version string) (*Info, error) {
+	base := p.URL
+	if base == "" {
+		base = DefaultURL
+	}
+	base = strings.TrimSuffix(base, "/")
+
+	u := fmt.Sprintf("%s/%s/@latest", base, escapePath(path))
+	if version != "" {
+		u = fmt.Sprintf("%s/%s/@v/%s.info", base, escapePath(path), escapePath(version))
+	}
+
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("module proxy returned %s for %s", resp.Status, u)
+	}
+
+	var result Info
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ZipURL returns the URL of the zip file of a module version on the
+// default module proxy.
+func ZipURL(path, version string) string {
+	return fmt.Sprintf("%s/%s/@v/%s.zip", DefaultURL, escapePath(path), escapePath(version))
+}
+
+// escapePath escapes a module path or version for use in a module proxy
+// URL. Upper case letters are replaced with "!" and the lower case letter
+// since the proxy may be backed by a case insensitive file system.
+func escapePath(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if 'A' <= r && r <= 'Z' {
+			b.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
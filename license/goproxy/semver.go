@@ -0,0 +1,17 @@
+package goproxy
+
+import (
+	"github.com/mitchellh/golicense/module"
+)
+
+// Newer reports whether version v is newer than current using semantic
+// versioning precedence. Versions that can't be parsed are only compared
+// for equality.
+func Newer(current, v string) bool {
+	c, ok := module.CompareVersions(v, current)
+	if !ok {
+		return current != v
+	}
+
+	return c > 0
+}
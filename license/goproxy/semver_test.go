@@ -0,0 +1,33 @@
+package goproxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewer(t *testing.T) {
+	cases := []struct {
+		Current string
+		Version string
+		Newer   bool
+	}{
+		{"v1.2.3", "v1.2.3", false},
+		{"v1.2.3", "v1.2.4", true},
+		{"v1.2.3", "v1.10.0", true},
+		{"v1.10.0", "v1.9.0", false},
+		{"v1.0.0-rc.1", "v1.0.0", true},
+		{"v1.0.0", "v1.0.0-rc.1", false},
+		{"v1.0.0-rc.2", "v1.0.0-rc.10", true},
+		{"v0.0.0-20190312203227-4b39c73a6495", "v0.1.0", true},
+		{"v0.1.0", "v0.0.0-20190312203227-4b39c73a6495", false},
+		{"v2.0.0+incompatible", "v2.0.0+incompatible", false},
+		{"(devel)", "v1.0.0", true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Current+" "+tt.Version, func(t *testing.T) {
+			require.Equal(t, tt.Newer, Newer(tt.Current, tt.Version))
+		})
+	}
+}
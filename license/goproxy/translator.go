@@ -0,0 +1,50 @@
+// Package goproxy contains helpers that use the Go module proxy, such as a
+// translator that finds the canonical repository of a module.
+package goproxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+// Translator resolves modules to their canonical repository using the
+// origin metadata in the module proxy's ".info" response. This is more
+// accurate than guessing from the import path since it is what the go
+// tool recorded when it fetched the module.
+//
+// Older module versions may not have origin metadata, in which case no
+// translation is done and the other translators are relied on.
+type Translator struct {
+	// Client is the HTTP client to use. http.DefaultClient is used if nil.
+	Client *http.Client
+
+	// URL is the base URL of the module proxy. DefaultURL is used if empty.
+	URL string
+}
+
+func (t Translator) Translate(ctx context.Context, m module.Module) (module.Module, bool) {
+	p := &Proxy{Client: t.Client, URL: t.URL}
+	i, err := p.Info(ctx, m.Path, m.Version)
+	if err != nil || i.Origin == nil || i.Origin.URL == "" {
+		return module.Module{}, false
+	}
+
+	path := strings.TrimSuffix(hostStripRe.ReplaceAllString(i.Origin.URL, ""), ".git")
+	if m.Path == path {
+		return module.Module{}, false
+	}
+
+	license.UpdateStatus(ctx, license.StatusNormal, fmt.Sprintf(
+		"translated %q to %q", m.Path, path))
+	m.Path = path
+	return m, true
+}
+
+// hostStripRe is a simple regexp to strip the schema from a URL.
+var hostStripRe = regexp.MustCompile(`^\w+:\/\/`)
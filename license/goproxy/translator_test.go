@@ -0,0 +1,78 @@
+package goproxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslator(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/foo/@v/v1.0.0.info":
+			w.Write([]byte(`{"Version":"v1.0.0","Origin":{"VCS":"git","URL":"https://github.com/foo/bar"}}`))
+
+		case "/example.com/!burnt!sushi/toml/@v/v1.2.0.info":
+			w.Write([]byte(`{"Version":"v1.2.0","Origin":{"VCS":"git","URL":"https://github.com/BurntSushi/toml.git"}}`))
+
+		case "/example.com/old/@v/v0.1.0.info":
+			w.Write([]byte(`{"Version":"v0.1.0"}`))
+
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		Input   string
+		Version string
+		Output  string
+	}{
+		{
+			"example.com/foo",
+			"v1.0.0",
+			"github.com/foo/bar",
+		},
+
+		{
+			"example.com/BurntSushi/toml",
+			"v1.2.0",
+			"github.com/BurntSushi/toml",
+		},
+
+		{
+			"example.com/old",
+			"v0.1.0",
+			"",
+		},
+
+		{
+			"example.com/missing",
+			"v1.0.0",
+			"",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Input, func(t *testing.T) {
+			tr := &Translator{URL: srv.URL}
+			actual, ok := tr.Translate(context.Background(), module.Module{
+				Path:    tt.Input,
+				Version: tt.Version,
+			})
+
+			if tt.Output == "" {
+				require.False(t, ok)
+				return
+			}
+
+			require.True(t, ok)
+			require.Equal(t, tt.Output, actual.Path)
+		})
+	}
+}
@@ -5,7 +5,24 @@ package license
 // License represents a software license.
 type License struct {
 	Name string // Name is a human-friendly name like "MIT License"
-	SPDX string // SPDX ID of the license, blank if unknown or unavailable
+	SPDX string // SPDX ID or expression, blank if unknown or unavailable
+
+	// URL is where the license was determined from for manual review,
+	// such as the license file on GitHub. Blank if unknown.
+	URL string
+
+	// Source is the name of the finder that found the license, such as
+	// "github". Blank if unknown.
+	Source string
+
+	// Confidence is how closely the license text matched the detected
+	// license, between 0 and 1. Zero if the license wasn't detected from
+	// its text, such as a license reported by an API or an override.
+	Confidence float64
+
+	// Conflicts are the SPDX IDs of the licenses that other finders of
+	// equal priority found instead, if they disagreed. See EqualPriority.
+	Conflicts []string
 }
 
 func (l *License) String() string {
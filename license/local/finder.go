@@ -0,0 +1,133 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/golicense/license"
+	textdetect "github.com/mitchellh/golicense/license/detect"
+	"github.com/mitchellh/golicense/module"
+)
+
+// Finder implements license.Finder and detects the license of a module from
+// its license file on disk, such as in a vendor directory or the module
+// cache. This avoids network requests when the source is available locally.
+// Modules replaced by a local directory are detected from that directory.
+type Finder struct {
+	// Dirs are the directories to look for modules in. Each is either a
+	// vendor directory, with modules at <dir>/<path>, or a module cache
+	// (GOPATH/pkg/mod), with modules at <dir>/<path>@<version>.
+	Dirs []string
+}
+
+// License implements license.Finder
+func (f *Finder) License(ctx context.Context, m module.Module) (*license.License, error) {
+	// A module replaced by a local directory has its license files there
+	if m.IsLocal() {
+		lic, _, err := detectDir(ctx, filepath.FromSlash(m.Path))
+		return license.OrNotFound(lic, err)
+	}
+
+	for _, dir := range f.Dirs {
+		for _, path := range m.Paths() {
+			candidates := []string{filepath.Join(dir, filepath.FromSlash(path))}
+			if m.Version != "" {
+				candidates = append([]string{filepath.Join(dir, filepath.FromSlash(
+					escapePath(path)+"@"+escapePath(m.Version)))}, candidates...)
+			}
+
+			for _, c := range candidates {
+				if lic, ok, err := detectDir(ctx, c); ok {
+					return license.OrNotFound(lic, err)
+				}
+			}
+		}
+	}
+
+	return nil, license.ErrNotFound
+}
+
+// detectDir detects the license of the module in the directory from its
+// license files. This returns false if the directory has no license files.
+func detectDir(ctx context.Context, dir string) (*license.License, bool, error) {
+	files := licenseFiles(dir)
+	if len(files) == 0 {
+		return nil, false, nil
+	}
+
+	license.UpdateStatus(ctx, license.StatusNormal, fmt.Sprintf(
+		"detecting license in %s", dir))
+	data := map[string][]byte{}
+	for _, name := range files {
+		d, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, true, err
+		}
+		data[name] = d
+	}
+
+	lic, err := textdetect.DetectFiles(data)
+	if lic != nil {
+		lic.URL = fileURL(filepath.Join(dir, files[0]))
+		lic.Source = "local"
+	}
+
+	return lic, true, err
+}
+
+// licenseFiles returns the names of the license files in the directory,
+// such as LICENSE, LICENSE.md or COPYING.
+func licenseFiles(dir string) []string {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var result []string
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+
+		if textdetect.IsLicenseFile(info.Name()) {
+			result = append(result, info.Name())
+		}
+	}
+
+	return result
+}
+
+// fileURL returns the file URL of the path, made absolute so that it can
+// be opened from a report.
+func fileURL(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	// Windows paths need a leading slash, such as file:///C:/foo
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// escapePath escapes a module path or version the way the module cache
+// does. Upper case letters are replaced with "!" and the lower case letter.
+func escapePath(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if 'A' <= r && r <= 'Z' {
+			b.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,156 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinder(t *testing.T) {
+	vendor := t.TempDir()
+	writeFile(t, filepath.Join(vendor, "github.com", "foo", "bar", "LICENSE"), mitLicense)
+	writeFile(t, filepath.Join(vendor, "github.com", "foo", "nolicense", "README.md"), "hello")
+
+	modcache := t.TempDir()
+	writeFile(t, filepath.Join(modcache, "github.com", "!burnt!sushi", "toml@v1.2.0", "COPYING"), mitLicense)
+	writeFile(t, filepath.Join(modcache, "github.com", "foo", "major", "v2@v2.1.0", "LICENSE.md"), mitLicense)
+
+	mit := func(path ...string) *license.License {
+		return &license.License{
+			Name:   "MIT",
+			SPDX:   "MIT",
+			URL:    (&url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Join(path...))}).String(),
+			Source: "local",
+		}
+	}
+
+	cases := []struct {
+		Path    string
+		Version string
+		Result  *license.License
+	}{
+		{
+			"github.com/foo/bar",
+			"v1.0.0",
+			mit(vendor, "github.com", "foo", "bar", "LICENSE"),
+		},
+
+		{
+			"github.com/BurntSushi/toml",
+			"v1.2.0",
+			mit(modcache, "github.com", "!burnt!sushi", "toml@v1.2.0", "COPYING"),
+		},
+
+		{
+			"github.com/foo/major",
+			"v2.1.0",
+			mit(modcache, "github.com", "foo", "major", "v2@v2.1.0", "LICENSE.md"),
+		},
+
+		{
+			"github.com/BurntSushi/toml",
+			"v1.3.0",
+			nil,
+		},
+
+		{
+			"github.com/foo/nolicense",
+			"v1.0.0",
+			nil,
+		},
+
+		{
+			"github.com/foo/missing",
+			"v1.0.0",
+			nil,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Path+"@"+tt.Version, func(t *testing.T) {
+			f := &Finder{Dirs: []string{vendor, modcache}}
+			actual, err := f.License(context.Background(), module.Module{
+				Path:    tt.Path,
+				Version: tt.Version,
+			})
+			if tt.Result == nil {
+				require.True(t, errors.Is(err, license.ErrNotFound), "%v", err)
+			} else {
+				require.NoError(t, err)
+			}
+			if actual != nil {
+				require.GreaterOrEqual(t, actual.Confidence, 0.9)
+				actual.Confidence = 0
+			}
+			require.Equal(t, tt.Result, actual)
+		})
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+}
+
+const mitLicense = `MIT License
+
+Copyright (c) 2018 Example
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+`
+
+func TestFinder_fallback(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "github.com", "foo", "isc", "LICENSE"), iscLicense)
+
+	f := &Finder{Dirs: []string{dir}}
+	actual, err := f.License(context.Background(), module.Module{
+		Path:    "github.com/foo/isc",
+		Version: "v1.0.0",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, actual)
+	require.Equal(t, "ISC", actual.SPDX)
+}
+
+const iscLicense = `ISC License
+
+Copyright (c) 2018 Example
+
+Permission to use, copy, modify, and/or distribute this software for any
+purpose with or without fee is hereby granted, provided that the above
+copyright notice and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+`
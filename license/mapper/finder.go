@@ -6,20 +6,31 @@ import (
 
 	"github.com/mitchellh/go-spdx"
 	"github.com/mitchellh/golicense/license"
+	spdxdb "github.com/mitchellh/golicense/license/spdx"
 	"github.com/mitchellh/golicense/module"
 )
 
 // Finder implements license.Finder and sets the license type based on the
 // given mapping if the path exists in the map.
 type Finder struct {
+	// Map is the mapping of package names to SPDX IDs. The keys can be
+	// exact names or glob patterns such as "github.com/foo/*", with exact
+	// names taking precedence. See lookup for glob pattern matching.
 	Map map[string]string
 }
 
 // License implements license.Finder
 func (f *Finder) License(ctx context.Context, m module.Module) (*license.License, error) {
-	v, ok := f.Map[m.Path]
+	v, ok := lookup(f.Map, m.Path, m.Version)
 	if !ok {
-		return nil, nil
+		return nil, license.ErrNotFound
+	}
+
+	// The embedded license list also accepts names, so only licenses
+	// that aren't in it are looked up by SPDX ID over the network.
+	if id, ok := spdxdb.Lookup(v); ok {
+		l, _ := spdxdb.Get(id)
+		return &license.License{Name: l.Name, SPDX: l.ID, Source: "override"}, nil
 	}
 
 	// Look up the license by SPDX ID
@@ -28,5 +39,5 @@ func (f *Finder) License(ctx context.Context, m module.Module) (*license.License
 		return nil, fmt.Errorf("Override license %q SPDX lookup error: %s", v, err)
 	}
 
-	return &license.License{Name: lic.Name, SPDX: lic.ID}, nil
+	return &license.License{Name: lic.Name, SPDX: lic.ID, Source: "override"}, nil
 }
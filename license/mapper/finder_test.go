@@ -0,0 +1,42 @@
+package mapper
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+func TestFinder(t *testing.T) {
+	f := &Finder{Map: map[string]string{
+		"github.com/foo/id":   "mit",
+		"github.com/foo/name": "Apache License 2.0",
+		"github.com/bar/*":    "ASL 2.0",
+	}}
+
+	cases := []struct {
+		Path   string
+		Result *license.License
+	}{
+		{"github.com/foo/id", &license.License{Name: "MIT License", SPDX: "MIT", Source: "override"}},
+		{"github.com/foo/name", &license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0", Source: "override"}},
+		{"github.com/bar/baz", &license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0", Source: "override"}},
+		{"github.com/foo/other", nil},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Path, func(t *testing.T) {
+			lic, err := f.License(context.Background(), module.Module{Path: tt.Path})
+			if tt.Result == nil {
+				require.True(t, errors.Is(err, license.ErrNotFound), "%v", err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.Result, lic)
+		})
+	}
+}
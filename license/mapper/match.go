@@ -0,0 +1,133 @@
+package mapper
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/golicense/module"
+)
+
+// lookup finds the value for the module path and version in the map. The
+// precedence is:
+//
+//  1. A key that exactly matches the path.
+//  2. A glob pattern key, such as "github.com/aws/aws-sdk-go-v2/*", using
+//     path.Match syntax. A trailing "/*" matches any number of path
+//     elements, so it matches "github.com/aws/aws-sdk-go-v2/service/s3"
+//     too. If multiple patterns match, the longest one wins.
+//
+// Either kind of key can be suffixed by "@" and a version or a version
+// range, such as "github.com/foo/bar@v2.0.0" or
+// "github.com/foo/bar@>=v2.0.0", to only match those versions (see
+// module.MatchVersion). The version comes first in the precedence: a key
+// with an exact version wins over one with a range, which wins over a key
+// without a version, and only then is the path precedence above used.
+//
+// Keys that begin and end with "/" are regular expressions and are ignored
+// here.
+func lookup(m map[string]string, p, version string) (string, bool) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		if !isRegexp(k) {
+			keys = append(keys, k)
+		}
+	}
+
+	k, ok := MostSpecific(keys, module.Module{Path: p, Version: version})
+	if !ok {
+		return "", false
+	}
+
+	return m[k], true
+}
+
+// MostSpecific returns the pattern that matches the module with the
+// highest precedence, with the same syntax and precedence as the keys of
+// the maps. See lookup.
+func MostSpecific(patterns []string, m module.Module) (string, bool) {
+	type match struct {
+		pattern     string
+		specificity int
+		exact       bool
+		length      int
+	}
+
+	var matches []match
+	for _, pattern := range patterns {
+		pp, pv := module.SplitVersion(pattern)
+		if !module.MatchVersion(pv, m.Version) {
+			continue
+		}
+
+		exact := pp == m.Path
+		if !exact && (!strings.ContainsAny(pp, "*?[") || !globMatch(pp, m.Path)) {
+			continue
+		}
+
+		matches = append(matches, match{
+			pattern:     pattern,
+			specificity: module.VersionSpecificity(pv),
+			exact:       exact,
+			length:      len(pp),
+		})
+	}
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	// Most specific first, then alphabetical so the result is stable.
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		switch {
+		case a.specificity != b.specificity:
+			return a.specificity > b.specificity
+		case a.exact != b.exact:
+			return a.exact
+		case a.length != b.length:
+			return a.length > b.length
+		}
+
+		return a.pattern < b.pattern
+	})
+
+	return matches[0].pattern, true
+}
+
+// Match returns true if the import path matches the pattern, which is either
+// an exact path or a glob pattern with the same syntax as the keys of the
+// maps, such as "github.com/aws/aws-sdk-go-v2/*".
+func Match(pattern, p string) bool {
+	return globMatch(pattern, p)
+}
+
+// MatchModule is like Match, but the pattern can also have a version or a
+// version range after an "@", such as "github.com/foo/bar@<v2.0.0", in
+// which case only those versions of the module match.
+func MatchModule(pattern string, m module.Module) bool {
+	pp, pv := module.SplitVersion(pattern)
+	return module.MatchVersion(pv, m.Version) && globMatch(pp, m.Path)
+}
+
+// globMatch matches the path against the glob pattern.
+func globMatch(pattern, p string) bool {
+	if ok, _ := path.Match(pattern, p); ok {
+		return true
+	}
+
+	// A trailing "/*" matches everything below the prefix
+	if prefix := strings.TrimSuffix(pattern, "/*"); prefix != pattern {
+		for dir := path.Dir(p); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			if ok, _ := path.Match(prefix, dir); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isRegexp returns true if the key is a regular expression.
+func isRegexp(k string) bool {
+	return len(k) > 1 && k[0] == '/' && k[len(k)-1] == '/'
+}
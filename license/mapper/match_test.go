@@ -0,0 +1,105 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	m := map[string]string{
+		"github.com/aws/aws-sdk-go-v2/*":         "Apache-2.0",
+		"github.com/aws/aws-sdk-go-v2/service/*": "MIT",
+		"github.com/aws/aws-sdk-go-v2/config":    "BSD-3-Clause",
+		"github.com/foo/bar-?":                   "ISC",
+		`/^github\.com/regexp/`:                  "MPL-2.0",
+	}
+
+	cases := []struct {
+		Input  string
+		Output string
+	}{
+		// Exact match takes precedence
+		{"github.com/aws/aws-sdk-go-v2/config", "BSD-3-Clause"},
+
+		// Longest pattern takes precedence
+		{"github.com/aws/aws-sdk-go-v2/service/s3", "MIT"},
+		{"github.com/aws/aws-sdk-go-v2/service/s3/types", "MIT"},
+
+		// A trailing /* matches any depth
+		{"github.com/aws/aws-sdk-go-v2/credentials", "Apache-2.0"},
+		{"github.com/aws/aws-sdk-go-v2/feature/s3/manager", "Apache-2.0"},
+
+		{"github.com/foo/bar-a", "ISC"},
+		{"github.com/foo/bar-ab", ""},
+		{"github.com/aws/aws-sdk-go-v2", ""},
+		{"github.com/regexp/foo", ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Input, func(t *testing.T) {
+			actual, ok := lookup(m, tt.Input, "")
+			require.Equal(t, tt.Output != "", ok)
+			require.Equal(t, tt.Output, actual)
+		})
+	}
+}
+
+func TestLookup_version(t *testing.T) {
+	m := map[string]string{
+		"github.com/foo/bar":             "MIT",
+		"github.com/foo/bar@>=v2.0.0":    "Apache-2.0",
+		"github.com/foo/bar@v2.1.0":      "BSD-3-Clause",
+		"github.com/foo/*@<v1.0.0":       "ISC",
+		"github.com/foo/baz@v1.0.0":      "MPL-2.0",
+		"github.com/foo/qux@~v1.0.0":     "GPL-3.0",
+		"github.com/foo/bad@>=v1.0.0 <v": "GPL-3.0",
+	}
+
+	cases := []struct {
+		Path    string
+		Version string
+		Output  string
+	}{
+		// An exact version takes precedence over a range
+		{"github.com/foo/bar", "v2.1.0", "BSD-3-Clause"},
+
+		// A range takes precedence over the path alone
+		{"github.com/foo/bar", "v2.0.0", "Apache-2.0"},
+		{"github.com/foo/bar", "v3.0.0-rc.1", "Apache-2.0"},
+
+		// The path alone is the fallback
+		{"github.com/foo/bar", "v1.5.0", "MIT"},
+		{"github.com/foo/bar", "", "MIT"},
+
+		// A version takes precedence over the path, even in a pattern
+		{"github.com/foo/bar", "v0.1.0", "ISC"},
+
+		{"github.com/foo/baz", "v1.0.0", "MPL-2.0"},
+		{"github.com/foo/baz", "v1.0.1", ""},
+		{"github.com/foo/baz", "v0.9.0", "ISC"},
+
+		// Invalid ranges never match
+		{"github.com/foo/qux", "v1.0.0", ""},
+		{"github.com/foo/bad", "v1.0.0", ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Path+"@"+tt.Version, func(t *testing.T) {
+			actual, ok := lookup(m, tt.Path, tt.Version)
+			require.Equal(t, tt.Output != "", ok)
+			require.Equal(t, tt.Output, actual)
+		})
+	}
+}
+
+func TestMatchModule(t *testing.T) {
+	m := module.Module{Path: "github.com/foo/bar", Version: "v1.2.0"}
+	require.True(t, MatchModule("github.com/foo/bar", m))
+	require.True(t, MatchModule("github.com/foo/*@v1.2.0", m))
+	require.True(t, MatchModule("github.com/foo/bar@>=v1.0.0 <v2.0.0", m))
+	require.False(t, MatchModule("github.com/foo/bar@v1.2.1", m))
+	require.False(t, MatchModule("github.com/foo/bar@>v1.2.0", m))
+	require.False(t, MatchModule("github.com/foo/baz", m))
+}
@@ -12,10 +12,14 @@ import (
 
 type Translator struct {
 	// Map is the mapping of package names to translate. If the name is
-	// exact then it will map exactly to the destination. If the name begins
-	// and ends with `/` (forward slash) then it will be treated like a regular
-	// expression. The destination can use \1, \2, ... to reference capture
-	// groups.
+	// exact then it will map exactly to the destination. If the name is a
+	// glob pattern such as "github.com/foo/*", every matching package maps
+	// to the destination. If the name begins and ends with `/` (forward
+	// slash) then it will be treated like a regular expression. The
+	// destination can use \1, \2, ... to reference capture groups.
+	//
+	// Exact names take precedence over glob patterns, which take precedence
+	// over regular expressions. See lookup for glob pattern matching.
 	//
 	// The translation will run until in a loop until no translation occurs
 	// anymore or len(Map) translations occur, in which case it is an error.
@@ -31,14 +35,14 @@ RESTART:
 		return module.Module{}, false
 	}
 
-	for k, v := range t.Map {
-		if k == m.Path {
-			m.Path = v
-			count++
-			goto RESTART
-		}
+	if v, ok := lookup(t.Map, m.Path, m.Version); ok {
+		m.Path = v
+		count++
+		goto RESTART
+	}
 
-		if k[0] == '/' && k[len(k)-1] == '/' {
+	for k, v := range t.Map {
+		if isRegexp(k) {
 			// Note that this isn't super performant since we constantly
 			// recompile any translations as we retry, but we don't expect
 			// many translations. If this ever becomes a performance issue,
@@ -35,6 +35,23 @@ func TestTranslator(t *testing.T) {
 			"gopkg.in/mitchellh/foo.v22",
 			"github.com/mitchellh/foo",
 		},
+
+		{
+			map[string]string{
+				"github.com/aws/aws-sdk-go-v2/*": "github.com/aws/aws-sdk-go-v2",
+			},
+			"github.com/aws/aws-sdk-go-v2/service/s3",
+			"github.com/aws/aws-sdk-go-v2",
+		},
+
+		{
+			map[string]string{
+				"example.com/foo/*":   "example.com/glob",
+				"example.com/foo/bar": "example.com/exact",
+			},
+			"example.com/foo/bar",
+			"example.com/exact",
+		},
 	}
 
 	for _, tt := range cases {
@@ -0,0 +1,207 @@
+package license
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mitchellh/golicense/module"
+)
+
+// Metrics counts the work done to look up licenses: the lookups of each
+// finder, the network requests they made and the cache hits and misses.
+// It is inserted into a context with MetricsWithContext and is safe for
+// concurrent use. A nil *Metrics discards everything.
+type Metrics struct {
+	requests    int64
+	cacheHits   int64
+	cacheMisses int64
+
+	lock    sync.Mutex
+	finders map[string]*FinderMetrics
+}
+
+// MetricsSummary is a snapshot of the Metrics, such as for a report.
+type MetricsSummary struct {
+	Requests    int64           `json:"requests"`
+	CacheHits   int64           `json:"cache_hits"`
+	CacheMisses int64           `json:"cache_misses"`
+	Finders     []FinderMetrics `json:"finders"`
+}
+
+// FinderMetrics are the metrics of a single finder.
+type FinderMetrics struct {
+	Name     string        `json:"name"`
+	Lookups  int64         `json:"lookups"`
+	Found    int64         `json:"found"`
+	Errors   int64         `json:"errors"`
+	Requests int64         `json:"requests"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// MetricsWithContext inserts Metrics into a context. The lookups of Find
+// and EqualPriority with the context are counted.
+func MetricsWithContext(ctx context.Context, m *Metrics) context.Context {
+	return context.WithValue(ctx, metricsCtxKey, m)
+}
+
+// MetricsFromContext returns the Metrics of the context, or nil if there
+// are none.
+func MetricsFromContext(ctx context.Context) *Metrics {
+	m, _ := ctx.Value(metricsCtxKey).(*Metrics)
+	return m
+}
+
+// Request counts a network request. If the request is made during the
+// lookup of a finder, it is also counted for that finder.
+func (m *Metrics) Request(ctx context.Context) {
+	if m == nil {
+		return
+	}
+
+	atomic.AddInt64(&m.requests, 1)
+	if name, ok := ctx.Value(finderCtxKey).(string); ok {
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		m.finder(name).Requests++
+	}
+}
+
+// CacheLookup counts a lookup of a module in the cache. If it was a hit,
+// no finder was used for the module.
+func (m *Metrics) CacheLookup(hit bool) {
+	if m == nil {
+		return
+	}
+
+	if hit {
+		atomic.AddInt64(&m.cacheHits, 1)
+	} else {
+		atomic.AddInt64(&m.cacheMisses, 1)
+	}
+}
+
+// Summary returns a snapshot of the metrics, with the finders sorted by
+// name.
+func (m *Metrics) Summary() MetricsSummary {
+	if m == nil {
+		return MetricsSummary{}
+	}
+
+	s := MetricsSummary{
+		Requests:    atomic.LoadInt64(&m.requests),
+		CacheHits:   atomic.LoadInt64(&m.cacheHits),
+		CacheMisses: atomic.LoadInt64(&m.cacheMisses),
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, fm := range m.finders {
+		s.Finders = append(s.Finders, *fm)
+	}
+	sort.Slice(s.Finders, func(i, j int) bool { return s.Finders[i].Name < s.Finders[j].Name })
+
+	return s
+}
+
+// String returns the summary in a human readable format, one line for
+// the totals and one for each finder.
+func (s MetricsSummary) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d requests, %d cache hits, %d cache misses",
+		s.Requests, s.CacheHits, s.CacheMisses)
+	for _, fm := range s.Finders {
+		fmt.Fprintf(&b, "\n%s: %d lookups, %d found, %d errors, %d requests, %s average",
+			fm.Name, fm.Lookups, fm.Found, fm.Errors, fm.Requests, fm.Average())
+	}
+
+	return b.String()
+}
+
+// Average returns the average duration of a lookup.
+func (fm FinderMetrics) Average() time.Duration {
+	if fm.Lookups == 0 {
+		return 0
+	}
+
+	return (fm.Duration / time.Duration(fm.Lookups)).Round(time.Millisecond)
+}
+
+// lookup runs the lookup of a finder, counting it if the context has
+// Metrics.
+func lookup(ctx context.Context, f Finder, mod module.Module) (*License, error) {
+	// The finders of a group are counted on their own
+	m := MetricsFromContext(ctx)
+	if _, ok := f.(EqualPriority); m == nil || ok {
+		return f.License(ctx, mod)
+	}
+
+	name := FinderName(f)
+	start := time.Now()
+	lic, err := f.License(context.WithValue(ctx, finderCtxKey, name), mod)
+	d := time.Since(start)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	fm := m.finder(name)
+	fm.Lookups++
+	fm.Duration += d
+	switch {
+	case lic != nil && err == nil:
+		fm.Found++
+
+	case err != nil && !errors.Is(err, ErrNotFound):
+		fm.Errors++
+	}
+
+	return lic, err
+}
+
+// finder returns the metrics of the finder with the given name. The lock
+// must be held.
+func (m *Metrics) finder(name string) *FinderMetrics {
+	if m.finders == nil {
+		m.finders = map[string]*FinderMetrics{}
+	}
+	fm, ok := m.finders[name]
+	if !ok {
+		fm = &FinderMetrics{Name: name}
+		m.finders[name] = fm
+	}
+
+	return fm
+}
+
+// FinderName returns the name of a finder for the metrics. This is the
+// name it was registered with in a Registry, or its type otherwise. The
+// name of an EqualPriority group is the names of its finders separated
+// by "|".
+func FinderName(f Finder) string {
+	switch f := f.(type) {
+	case *namedFinder:
+		return f.Name
+	case *Breaker:
+		return FinderName(f.Finder)
+	case EqualPriority:
+		names := make([]string, len(f))
+		for i, f := range f {
+			names[i] = FinderName(f)
+		}
+		return strings.Join(names, "|")
+	}
+
+	return strings.TrimPrefix(fmt.Sprintf("%T", f), "*")
+}
+
+type metricsCtxKeyType struct{}
+type finderCtxKeyType struct{}
+
+var (
+	metricsCtxKey = metricsCtxKeyType{}
+	finderCtxKey  = finderCtxKeyType{}
+)
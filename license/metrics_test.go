@@ -0,0 +1,56 @@
+package license
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_finders(t *testing.T) {
+	var found, failed MockFinder
+	found.On("License", mock.Anything, mock.Anything).Return(&License{SPDX: "MIT"}, nil).Run(func(args mock.Arguments) {
+		// A request during the lookup is counted for the finder
+		MetricsFromContext(args.Get(0).(context.Context)).Request(args.Get(0).(context.Context))
+	})
+	failed.On("License", mock.Anything, mock.Anything).Return(nil, errors.New("boom"))
+
+	var r Registry
+	r.Register("failed", func() (Finder, error) { return &failed, nil })
+	r.Register("found", func() (Finder, error) { return &found, nil })
+	fs, err := r.Finders(nil)
+	require.NoError(t, err)
+
+	var m Metrics
+	ctx := MetricsWithContext(context.Background(), &m)
+	for _, path := range []string{"github.com/foo/bar", "github.com/foo/baz"} {
+		lic, _ := Find(ctx, module.Module{Path: path}, fs)
+		require.NotNil(t, lic)
+	}
+
+	// A request outside of a lookup is only counted in the total
+	m.Request(ctx)
+
+	s := m.Summary()
+	require.Equal(t, int64(3), s.Requests)
+	require.Len(t, s.Finders, 2)
+	for i := range s.Finders {
+		s.Finders[i].Duration = 0
+	}
+	require.Equal(t, []FinderMetrics{
+		{Name: "failed", Lookups: 2, Errors: 2},
+		{Name: "found", Lookups: 2, Found: 2, Requests: 2},
+	}, s.Finders)
+}
+
+func TestMetrics_nil(t *testing.T) {
+	// Without metrics in the context nothing is counted, and nothing fails
+	m := MetricsFromContext(context.Background())
+	require.Nil(t, m)
+	m.Request(context.Background())
+	m.CacheLookup(true)
+	require.Equal(t, MetricsSummary{}, m.Summary())
+}
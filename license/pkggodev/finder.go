@@ -0,0 +1,122 @@
+package pkggodev
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+// DefaultURL is the pkg.go.dev instance used if no URL is configured.
+const DefaultURL = "https://pkg.go.dev"
+
+// Finder implements license.Finder and looks up the license of a module
+// using the licenses tab on pkg.go.dev.
+//
+// Unlike the GitHub API, pkg.go.dev detects the licenses of the exact module
+// version so this is accurate even if a project changed licenses after the
+// version in use.
+type Finder struct {
+	// Client is the HTTP client to use. http.DefaultClient is used if nil.
+	Client *http.Client
+
+	// URL is the base URL of pkg.go.dev. DefaultURL is used if empty.
+	URL string
+}
+
+// License implements license.Finder
+func (f *Finder) License(ctx context.Context, m module.Module) (*license.License, error) {
+	if m.Version == "" {
+		return nil, license.ErrNotFound
+	}
+
+	base := f.URL
+	if base == "" {
+		base = DefaultURL
+	}
+	base = strings.TrimSuffix(base, "/")
+
+	u := fmt.Sprintf("%s/%s@%s?tab=licenses", base, m.MajorPath(), m.Version)
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	license.UpdateStatus(ctx, license.StatusNormal, "querying pkg.go.dev")
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Modules that pkg.go.dev doesn't know about are common (private or
+	// translated paths), so this isn't an error.
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, license.ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, license.HTTPError(resp.StatusCode,
+			fmt.Errorf("pkg.go.dev returned %s for %s", resp.Status, u))
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	lic := parseLicenses(string(body))
+	if lic == nil {
+		return nil, license.ErrNotFound
+	}
+
+	lic.URL = u
+	lic.Source = "pkggodev"
+	return lic, nil
+}
+
+// parseLicenses extracts the detected license types from the licenses tab.
+// Each license file is listed with a comma separated list of SPDX IDs. The
+// SPDX ID is only set if a single license is detected.
+func parseLicenses(body string) *license.License {
+	var ids []string
+	seen := map[string]struct{}{}
+	for _, match := range licenseRe.FindAllStringSubmatch(body, -1) {
+		for _, id := range strings.Split(html.UnescapeString(match[1]), ",") {
+			id = strings.TrimSpace(id)
+			if id == "" || id == "UNKNOWN" {
+				continue
+			}
+			if _, ok := seen[id]; ok {
+				continue
+			}
+
+			seen[id] = struct{}{}
+			ids = append(ids, id)
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		return nil
+
+	case 1:
+		return &license.License{Name: ids[0], SPDX: ids[0]}
+
+	default:
+		return &license.License{Name: strings.Join(ids, ", ")}
+	}
+}
+
+// licenseRe matches the heading of every license file on the licenses tab.
+var licenseRe = regexp.MustCompile(`<div id="#lic-\d+">([^<]*)</div>`)
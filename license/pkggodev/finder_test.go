@@ -0,0 +1,158 @@
+package pkggodev
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "licenses", r.URL.Query().Get("tab"))
+
+		switch r.URL.Path {
+		case "/github.com/foo/bar@v1.0.0":
+			w.Write([]byte(`<section class="License" id="lic-0">
+<h2 class="go-textTitle"><div id="#lic-0">MIT</div></h2>`))
+
+		case "/github.com/foo/bar@v0.1.0":
+			w.Write([]byte(`<div id="#lic-0">GPL-2.0</div>`))
+
+		case "/github.com/foo/bar/v2@v2.3.0":
+			w.Write([]byte(`<div id="#lic-0">Apache-2.0</div>`))
+
+		case "/github.com/foo/multi@v1.0.0":
+			w.Write([]byte(`<div id="#lic-0">Apache-2.0, MIT</div>
+<div id="#lic-1">MIT</div>`))
+
+		case "/github.com/foo/unknown@v1.0.0":
+			w.Write([]byte(`<div id="#lic-0">UNKNOWN</div>`))
+
+		case "/github.com/foo/broken@v1.0.0":
+			w.WriteHeader(http.StatusInternalServerError)
+
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		Path    string
+		Version string
+		Result  *license.License
+		Err     bool
+	}{
+		{
+			"github.com/foo/bar",
+			"v1.0.0",
+			&license.License{Name: "MIT", SPDX: "MIT", Source: "pkggodev"},
+			false,
+		},
+
+		{
+			"github.com/foo/bar",
+			"v0.1.0",
+			&license.License{Name: "GPL-2.0", SPDX: "GPL-2.0", Source: "pkggodev"},
+			false,
+		},
+
+		// The major version suffix is added back to the path
+		{
+			"github.com/foo/bar",
+			"v2.3.0",
+			&license.License{Name: "Apache-2.0", SPDX: "Apache-2.0", Source: "pkggodev"},
+			false,
+		},
+
+		{
+			"github.com/foo/multi",
+			"v1.0.0",
+			&license.License{Name: "Apache-2.0, MIT", Source: "pkggodev"},
+			false,
+		},
+
+		{
+			"github.com/foo/unknown",
+			"v1.0.0",
+			nil,
+			false,
+		},
+
+		{
+			"github.com/foo/missing",
+			"v1.0.0",
+			nil,
+			false,
+		},
+
+		{
+			"github.com/foo/broken",
+			"v1.0.0",
+			nil,
+			true,
+		},
+
+		{
+			"github.com/foo/bar",
+			"",
+			nil,
+			false,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Path+"@"+tt.Version, func(t *testing.T) {
+			m := module.Module{Path: tt.Path, Version: tt.Version}
+			f := &Finder{URL: srv.URL}
+			actual, err := f.License(context.Background(), m)
+			// A module without a license isn't an error
+			if tt.Result == nil && !tt.Err {
+				require.True(t, errors.Is(err, license.ErrNotFound), "%v", err)
+				err = nil
+			}
+			require.Equal(t, tt.Err, err != nil)
+
+			// Server errors may go away on a later run
+			if err != nil {
+				require.True(t, license.IsTransient(err))
+			}
+
+			// The URL is the licenses tab of the version
+			if tt.Result != nil {
+				require.Equal(t, srv.URL+"/"+m.MajorPath()+"@"+tt.Version+"?tab=licenses", actual.URL)
+				actual.URL = ""
+			}
+			require.Equal(t, tt.Result, actual)
+		})
+	}
+}
+
+func TestFinder_recorded(t *testing.T) {
+	// A licenses tab recorded from pkg.go.dev
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/golang.org/x/text@v0.3.0", r.URL.Path)
+		http.ServeFile(w, r, filepath.Join("testdata", "x-text-licenses.html"))
+	}))
+	defer srv.Close()
+
+	f := &Finder{URL: srv.URL}
+	actual, err := f.License(context.Background(), module.Module{
+		Path:    "golang.org/x/text",
+		Version: "v0.3.0",
+	})
+	require.NoError(t, err)
+	require.Equal(t, &license.License{
+		Name:   "BSD-3-Clause",
+		SPDX:   "BSD-3-Clause",
+		URL:    srv.URL + "/golang.org/x/text@v0.3.0?tab=licenses",
+		Source: "pkggodev",
+	}, actual)
+}
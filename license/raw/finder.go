@@ -0,0 +1,181 @@
+// Package raw contains a license.Finder for code hosts without a license
+// API, such as Gitea, Azure DevOps or a self-hosted Git server, that
+// downloads the license file from a configured URL.
+package raw
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mitchellh/golicense/license"
+	textdetect "github.com/mitchellh/golicense/license/detect"
+	"github.com/mitchellh/golicense/module"
+)
+
+// Files are the names of the license files tried in order for a URL
+// template with a {file} placeholder.
+var Files = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "COPYING"}
+
+// Finder implements license.Finder and detects the license of modules on
+// the configured hosts from the raw content of their license file.
+//
+// The URL of the file is a template per host, such as
+// "https://{host}/{owner}/{repo}/raw/{ref}/LICENSE" for Gitea. The
+// placeholders are replaced with the parts of the repository of the module
+// (see module.ParseRepo) and the git ref to look at. A {file} placeholder
+// is replaced with each of Files in turn until one exists.
+type Finder struct {
+	// Client is the HTTP client to use. http.DefaultClient is used if nil.
+	Client *http.Client
+
+	// URLs are the URL templates of the license file by host, such as
+	// "git.example.com". Modules on other hosts aren't looked up.
+	URLs map[string]string
+
+	// Ref is the git ref to look up the license at by module path. By
+	// default this is the commit of a pseudo-version, or else the version
+	// itself since it is a tag.
+	Ref map[string]string
+}
+
+// License implements license.Finder
+func (f *Finder) License(ctx context.Context, m module.Module) (*license.License, error) {
+	urls := f.urls(m)
+	if len(urls) == 0 {
+		return nil, license.ErrNotFound
+	}
+
+	for _, u := range urls {
+		license.UpdateStatus(ctx, license.StatusNormal, "downloading "+u)
+		data, ok, err := f.get(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		license.UpdateStatus(ctx, license.StatusNormal, "detecting license")
+		lic, confidence := textdetect.Detect(string(data))
+		if lic == nil || confidence < textdetect.Threshold {
+			return nil, license.ErrNotFound
+		}
+
+		lic.Source = "raw"
+		return lic, nil
+	}
+
+	return nil, license.ErrNotFound
+}
+
+// Host implements license.HostFinder. Each configured host is looked up
+// on the host of its URL template, which fail independently.
+func (f *Finder) Host(m module.Module) string {
+	urls := f.urls(m)
+	if len(urls) == 0 {
+		return ""
+	}
+
+	u, err := url.Parse(urls[0])
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(u.Host)
+}
+
+// urls returns the URLs to try for the license file of the module, or nil
+// if its host isn't configured.
+func (f *Finder) urls(m module.Module) []string {
+	r, ok := module.ParseRepo(m.Path)
+	if !ok {
+		return nil
+	}
+
+	var tmpl string
+	for host, v := range f.URLs {
+		if strings.EqualFold(host, r.Host) {
+			tmpl = v
+			break
+		}
+	}
+	if tmpl == "" {
+		return nil
+	}
+
+	ref := f.Ref[m.Path]
+	switch {
+	case ref != "":
+	case m.PseudoCommit() != "":
+		ref = m.PseudoCommit()
+	case m.Version != "":
+		ref = m.Version
+	default:
+		ref = "HEAD"
+	}
+
+	replacer := strings.NewReplacer(
+		"{host}", r.Host,
+		"{owner}", escapePath(r.Owner),
+		"{repo}", url.PathEscape(r.Name),
+		"{ref}", url.PathEscape(ref),
+	)
+	u := replacer.Replace(tmpl)
+	if !strings.Contains(u, "{file}") {
+		return []string{u}
+	}
+
+	result := make([]string, len(Files))
+	for i, name := range Files {
+		result[i] = strings.Replace(u, "{file}", url.PathEscape(name), -1)
+	}
+
+	return result
+}
+
+// get returns the content at the URL. This returns false without an error
+// if there is no file or we don't have access to it, since private
+// repositories are expected.
+func (f *Finder) get(ctx context.Context, u string) ([]byte, bool, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return nil, false, nil
+	default:
+		return nil, false, license.HTTPError(resp.StatusCode,
+			fmt.Errorf("%s returned %s", u, resp.Status))
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	return data, err == nil, err
+}
+
+// escapePath escapes each segment of a path.
+func escapePath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,144 @@
+package raw
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinder(t *testing.T) {
+	mit, err := ioutil.ReadFile(filepath.Join("testdata", "LICENSE"))
+	require.NoError(t, err)
+
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+
+		switch r.URL.Path {
+		case "/foo/bar/raw/v2.1.0/LICENSE.md",
+			"/foo/bar/raw/4b39c73a6495/LICENSE",
+			"/foo/pinned/raw/main/LICENSE":
+			w.Write(mit)
+		case "/foo/readme/raw/v1.0.0/LICENSE":
+			w.Write([]byte("See the README."))
+		case "/foo/private/raw/v1.0.0/LICENSE":
+			w.WriteHeader(http.StatusForbidden)
+		case "/foo/broken/raw/v1.0.0/LICENSE":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	f := &Finder{
+		URLs: map[string]string{"Git.Example.com": srv.URL + "/{owner}/{repo}/raw/{ref}/{file}"},
+		Ref:  map[string]string{"git.example.com/foo/pinned": "main"},
+	}
+
+	mitLicense := &license.License{Name: "MIT License", SPDX: "MIT", Source: "raw"}
+	cases := []struct {
+		Path     string
+		Version  string
+		Result   *license.License
+		Err      bool
+		Requests []string
+	}{
+		{
+			"git.example.com/foo/bar/v2", "v2.1.0", mitLicense, false,
+			[]string{"/foo/bar/raw/v2.1.0/LICENSE", "/foo/bar/raw/v2.1.0/LICENSE.md"},
+		},
+		{
+			"git.example.com/foo/bar/sub", "v0.0.0-20190312203227-4b39c73a6495", mitLicense, false,
+			[]string{"/foo/bar/raw/4b39c73a6495/LICENSE"},
+		},
+		{
+			"git.example.com/foo/pinned", "v1.0.0", mitLicense, false,
+			[]string{"/foo/pinned/raw/main/LICENSE"},
+		},
+		{
+			"git.example.com/foo/readme", "v1.0.0", nil, false,
+			[]string{"/foo/readme/raw/v1.0.0/LICENSE"},
+		},
+		{
+			"git.example.com/foo/private", "v1.0.0", nil, false,
+			[]string{"/foo/private/raw/v1.0.0/LICENSE", "/foo/private/raw/v1.0.0/LICENSE.md",
+				"/foo/private/raw/v1.0.0/LICENSE.txt", "/foo/private/raw/v1.0.0/COPYING"},
+		},
+		{
+			"git.example.com/foo/broken", "v1.0.0", nil, true,
+			[]string{"/foo/broken/raw/v1.0.0/LICENSE"},
+		},
+		{
+			"github.com/foo/bar", "v1.0.0", nil, false,
+			nil,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Path, func(t *testing.T) {
+			requests = nil
+			actual, err := f.License(context.Background(), module.Module{
+				Path:    tt.Path,
+				Version: tt.Version,
+			})
+			// A module without a license isn't an error
+			if tt.Result == nil && !tt.Err {
+				require.True(t, errors.Is(err, license.ErrNotFound), "%v", err)
+				err = nil
+			}
+			require.Equal(t, tt.Err, err != nil)
+			if actual != nil {
+				require.GreaterOrEqual(t, actual.Confidence, 0.9)
+				actual.Confidence = 0
+			}
+			require.Equal(t, tt.Result, actual)
+			require.Equal(t, tt.Requests, requests)
+		})
+	}
+}
+
+func TestFinder_urls(t *testing.T) {
+	f := &Finder{URLs: map[string]string{
+		"gitea.example.com": "https://{host}/{owner}/{repo}/raw/{ref}/LICENSE",
+		"dev.azure.com":     "https://dev.azure.com/{owner}/_apis/git/repositories/{repo}/items?path=/{file}&versionDescriptor.version={ref}",
+	}}
+
+	cases := []struct {
+		Path string
+		URLs []string
+		Host string
+	}{
+		{
+			"gitea.example.com/foo/bar",
+			[]string{"https://gitea.example.com/foo/bar/raw/HEAD/LICENSE"},
+			"gitea.example.com",
+		},
+		{
+			"dev.azure.com/org/my project/_git/repo.git",
+			[]string{
+				"https://dev.azure.com/org/my%20project/_apis/git/repositories/repo/items?path=/LICENSE&versionDescriptor.version=HEAD",
+				"https://dev.azure.com/org/my%20project/_apis/git/repositories/repo/items?path=/LICENSE.md&versionDescriptor.version=HEAD",
+				"https://dev.azure.com/org/my%20project/_apis/git/repositories/repo/items?path=/LICENSE.txt&versionDescriptor.version=HEAD",
+				"https://dev.azure.com/org/my%20project/_apis/git/repositories/repo/items?path=/COPYING&versionDescriptor.version=HEAD",
+			},
+			"dev.azure.com",
+		},
+		{"example.com/foo/bar", nil, ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Path, func(t *testing.T) {
+			require.Equal(t, tt.URLs, f.urls(module.Module{Path: tt.Path}))
+			require.Equal(t, tt.Host, f.Host(module.Module{Path: tt.Path}))
+		})
+	}
+}
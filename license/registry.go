@@ -0,0 +1,92 @@
+package license
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FinderFactory creates a finder for a Registry. It may return a nil
+// finder if the finder isn't available, such as a finder that makes
+// network requests when running offline.
+type FinderFactory func() (Finder, error)
+
+// Registry is an ordered set of named finders. Finders are only created
+// when they are enabled, so a finder that isn't used doesn't need to be
+// set up. The zero value is an empty registry.
+type Registry struct {
+	names     []string
+	factories map[string]FinderFactory
+}
+
+// Register adds a finder with the given name. Finders are used in the
+// order they are registered unless Finders is given an order. Registering
+// a name again replaces the factory but keeps its position.
+func (r *Registry) Register(name string, f FinderFactory) {
+	if r.factories == nil {
+		r.factories = map[string]FinderFactory{}
+	}
+	if _, ok := r.factories[name]; !ok {
+		r.names = append(r.names, name)
+	}
+
+	r.factories[name] = f
+}
+
+// Names returns the names of the registered finders in order.
+func (r *Registry) Names() []string {
+	return append([]string(nil), r.names...)
+}
+
+// Finders creates the finders with the given names, in the given order. If
+// names is empty, all registered finders are created in the order they
+// were registered. Names separated by "|", such as "pkggodev|goproxy",
+// are a group of finders of equal priority that are combined with
+// EqualPriority. An error is returned if a name isn't registered, is
+// given more than once or its finder can't be created.
+func (r *Registry) Finders(names []string) ([]Finder, error) {
+	if len(names) == 0 {
+		names = r.names
+	}
+
+	result := make([]Finder, 0, len(names))
+	seen := map[string]bool{}
+	for _, entry := range names {
+		var group EqualPriority
+		for _, name := range strings.Split(entry, "|") {
+			name = strings.TrimSpace(name)
+			f, ok := r.factories[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown finder %q", name)
+			}
+			if seen[name] {
+				return nil, fmt.Errorf("finder %q is listed more than once", name)
+			}
+			seen[name] = true
+
+			finder, err := f()
+			if err != nil {
+				return nil, fmt.Errorf("error creating finder %q: %s", name, err)
+			}
+			if finder != nil {
+				group = append(group, &namedFinder{Finder: finder, Name: name})
+			}
+		}
+
+		switch len(group) {
+		case 0:
+		case 1:
+			result = append(result, group[0])
+		default:
+			result = append(result, group)
+		}
+	}
+
+	return result, nil
+}
+
+// namedFinder is a finder created by a Registry. It keeps the name the
+// finder was registered with for the Metrics.
+type namedFinder struct {
+	Finder
+	Name string
+}
@@ -0,0 +1,83 @@
+package license
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	var r Registry
+	var created []string
+	finder := func(name string) FinderFactory {
+		return func() (Finder, error) {
+			created = append(created, name)
+			return &MockFinder{}, nil
+		}
+	}
+	r.Register("a", finder("a"))
+	r.Register("b", finder("b"))
+	r.Register("c", finder("c"))
+	r.Register("disabled", func() (Finder, error) { return nil, nil })
+	r.Register("broken", func() (Finder, error) { return nil, errors.New("no token") })
+
+	// Registering again keeps the position
+	r.Register("a", finder("a2"))
+	require.Equal(t, []string{"a", "b", "c", "disabled", "broken"}, r.Names())
+
+	cases := []struct {
+		Name    string
+		Names   []string
+		Created []string
+		Finders []string
+		Err     bool
+	}{
+		{"order", []string{"c", "a"}, []string{"c", "a2"}, []string{"c", "a"}, false},
+		{"disabled", []string{"b", "disabled"}, []string{"b"}, []string{"b"}, false},
+		{"unknown", []string{"a", "nope"}, nil, nil, true},
+		{"duplicate", []string{"a", "b", "a"}, nil, nil, true},
+		{"group", []string{"c|b", "a"}, []string{"c", "b", "a2"}, []string{"c|b", "a"}, false},
+		{"group with disabled", []string{"disabled|b"}, []string{"b"}, []string{"b"}, false},
+		{"duplicate in group", []string{"a", "b|a"}, nil, nil, true},
+		{"unknown in group", []string{"a|nope"}, nil, nil, true},
+		{"error", []string{"broken"}, nil, nil, true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			created = nil
+			fs, err := r.Finders(tt.Names)
+			if tt.Err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.Created, created)
+
+			var names []string
+			for _, f := range fs {
+				names = append(names, FinderName(f))
+			}
+			require.Equal(t, tt.Finders, names)
+		})
+	}
+}
+
+func TestRegistry_default(t *testing.T) {
+	var r Registry
+	var created []string
+	for _, name := range []string{"a", "b"} {
+		name := name
+		r.Register(name, func() (Finder, error) {
+			created = append(created, name)
+			return &MockFinder{}, nil
+		})
+	}
+
+	// Without names, every finder is created in registration order
+	fs, err := r.Finders(nil)
+	require.NoError(t, err)
+	require.Len(t, fs, 2)
+	require.Equal(t, []string{"a", "b"}, created)
+}
@@ -0,0 +1,97 @@
+package resolver
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// metaImport is a go-import meta tag, as documented in "go help
+// importpath": <meta name="go-import" content="prefix vcs repo">.
+type metaImport struct {
+	Prefix, VCS, RepoRoot string
+}
+
+// parseMetaGoImports returns the go-import meta tags in the head of an
+// HTML document. Like the go tool, the HTML is parsed leniently and
+// parsing stops at the body since the tags must be in the head.
+func parseMetaGoImports(r io.Reader) ([]metaImport, error) {
+	d := xml.NewDecoder(r)
+	d.CharsetReader = charsetReader
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+
+	var result []metaImport
+	for {
+		t, err := d.RawToken()
+		if err != nil {
+			if err == io.EOF || len(result) > 0 {
+				err = nil
+			}
+
+			return result, err
+		}
+
+		if e, ok := t.(xml.StartElement); ok && strings.EqualFold(e.Name.Local, "body") {
+			return result, nil
+		}
+		if e, ok := t.(xml.EndElement); ok && strings.EqualFold(e.Name.Local, "head") {
+			return result, nil
+		}
+
+		e, ok := t.(xml.StartElement)
+		if !ok || !strings.EqualFold(e.Name.Local, "meta") || attrValue(e.Attr, "name") != "go-import" {
+			continue
+		}
+
+		if f := strings.Fields(attrValue(e.Attr, "content")); len(f) == 3 {
+			result = append(result, metaImport{
+				Prefix:   f[0],
+				VCS:      f[1],
+				RepoRoot: f[2],
+			})
+		}
+	}
+}
+
+// matchMetaImport returns the go-import tag whose prefix matches the
+// import path. Tags with the "mod" VCS are ignored since they point to a
+// module proxy rather than a repository.
+func matchMetaImport(imports []metaImport, path string) (metaImport, bool) {
+	for _, mi := range imports {
+		if mi.VCS == "mod" {
+			continue
+		}
+
+		if path == mi.Prefix || strings.HasPrefix(path, mi.Prefix+"/") {
+			return mi, true
+		}
+	}
+
+	return metaImport{}, false
+}
+
+// charsetReader accepts the charsets used by HTML pages that are
+// compatible with ASCII, which is all that go-import tags contain.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf-8", "ascii", "us-ascii", "iso-8859-1", "latin1":
+		return input, nil
+	}
+
+	return nil, fmt.Errorf("can't decode XML document using charset %q", charset)
+}
+
+// attrValue returns the value of the attribute with the given name
+// (case insensitive), or "" if there is none.
+func attrValue(attrs []xml.Attr, name string) string {
+	for _, a := range attrs {
+		if strings.EqualFold(a.Name.Local, name) {
+			return a.Value
+		}
+	}
+
+	return ""
+}
@@ -3,24 +3,40 @@ package resolver
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"regexp"
+	"strings"
 
 	"github.com/mitchellh/golicense/license"
 	"github.com/mitchellh/golicense/module"
-	"golang.org/x/tools/go/vcs"
 )
 
 // Translator resolves import paths to their proper VCS location. For
 // example: "rsc.io/pdf" turns into "github.com/rsc/pdf".
-type Translator struct{}
+//
+// This works the same way the go tool resolves custom (vanity) import
+// paths: the page at "https://<path>?go-get=1" is fetched and its
+// go-import meta tag names the repository.
+type Translator struct {
+	// Client is the HTTP client to use. http.DefaultClient is used if nil.
+	Client *http.Client
+}
 
 func (t Translator) Translate(ctx context.Context, m module.Module) (module.Module, bool) {
-	root, err := vcs.RepoRootForImportPath(m.Path, false)
-	if err != nil {
+	// Hosts that the finders know don't use meta tags, so don't waste a
+	// request on them.
+	for _, host := range knownHosts {
+		if strings.HasPrefix(m.Path, host) {
+			return module.Module{}, false
+		}
+	}
+
+	mi, ok := t.lookup(ctx, m.Path)
+	if !ok {
 		return module.Module{}, false
 	}
 
-	path := hostStripRe.ReplaceAllString(root.Repo, "")
+	path := strings.TrimSuffix(hostStripRe.ReplaceAllString(mi.RepoRoot, ""), ".git")
 	if m.Path == path {
 		return module.Module{}, false
 	}
@@ -31,5 +47,40 @@ func (t Translator) Translate(ctx context.Context, m module.Module) (module.Modu
 	return m, true
 }
 
+// lookup fetches the go-import meta tag that matches the import path.
+func (t Translator) lookup(ctx context.Context, path string) (metaImport, bool) {
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://"+path+"?go-get=1", nil)
+	if err != nil {
+		return metaImport{}, false
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return metaImport{}, false
+	}
+	defer resp.Body.Close()
+
+	// Like the go tool, the meta tags are used even if the status isn't
+	// 200 since some hosts serve them with a 404 for missing packages.
+	imports, err := parseMetaGoImports(resp.Body)
+	if err != nil {
+		return metaImport{}, false
+	}
+
+	return matchMetaImport(imports, path)
+}
+
+// knownHosts are the import path prefixes of hosts where the import path
+// is the repository.
+var knownHosts = []string{
+	"github.com/",
+	"bitbucket.org/",
+}
+
 // hostStripRe is a simple regexp to strip the schema from a URL.
 var hostStripRe = regexp.MustCompile(`^\w+:\/\/`)
@@ -2,6 +2,12 @@ package resolver
 
 import (
 	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/mitchellh/golicense/module"
@@ -46,3 +52,82 @@ func TestTranslator(t *testing.T) {
 		})
 	}
 }
+
+func TestTranslator_metaTag(t *testing.T) {
+	quote, err := ioutil.ReadFile(filepath.Join("testdata", "rsc.io-quote.html"))
+	require.NoError(t, err)
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("go-get") != "1" {
+			http.NotFound(w, r)
+			return
+		}
+
+		switch {
+		case r.URL.Path == "/quote" || strings.HasPrefix(r.URL.Path, "/quote/"):
+			// Recorded from https://rsc.io/quote?go-get=1, with the host
+			// changed to one the test certificate is valid for
+			w.Write([]byte(strings.Replace(string(quote), "rsc.io/", "example.com/", -1)))
+
+		case r.URL.Path == "/proxied":
+			w.Write([]byte(`<html><head><meta name="go-import" content="example.com/proxied mod https://proxy.example.com"></head></html>`))
+
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	// Send every request to the test server, whatever the host
+	client := srv.Client()
+	transport := client.Transport.(*http.Transport)
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, network, srv.Listener.Addr().String())
+	}
+
+	cases := []struct {
+		Input  string
+		Output string
+	}{
+		{"example.com/quote", "github.com/rsc/quote"},
+		{"example.com/quote/sub", "github.com/rsc/quote"},
+		{"example.com/proxied", ""},
+		{"example.com/missing", ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Input, func(t *testing.T) {
+			tr := Translator{Client: client}
+			actual, ok := tr.Translate(context.Background(), module.Module{
+				Path: tt.Input,
+			})
+
+			if tt.Output == "" {
+				require.False(t, ok)
+				return
+			}
+
+			require.True(t, ok)
+			require.Equal(t, tt.Output, actual.Path)
+		})
+	}
+}
+
+func TestParseMetaGoImports(t *testing.T) {
+	imports, err := parseMetaGoImports(strings.NewReader(`<html>
+<head>
+<meta name="go-import" content="example.com/foo git https://github.com/foo/foo">
+<meta name="go-import" content="example.com/foo mod https://proxy.example.com">
+<meta name="description" content="not an import">
+</head>
+<body>
+<meta name="go-import" content="example.com/body git https://github.com/foo/body">
+</body>
+</html>`))
+	require.NoError(t, err)
+	require.Equal(t, []metaImport{
+		{Prefix: "example.com/foo", VCS: "git", RepoRoot: "https://github.com/foo/foo"},
+		{Prefix: "example.com/foo", VCS: "mod", RepoRoot: "https://proxy.example.com"},
+	}, imports)
+}
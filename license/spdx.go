@@ -0,0 +1,52 @@
+package license
+
+import (
+	"strings"
+
+	"github.com/mitchellh/golicense/license/spdx"
+)
+
+// SPDXFromName returns the SPDX ID for a commonly used full license name,
+// such as "MIT License" or "Apache License 2.0". The lookup is case
+// insensitive and uses the embedded license list in the spdx package. An
+// empty string is returned if the name isn't known.
+func SPDXFromName(name string) string {
+	id, _ := spdx.Lookup(name)
+	return id
+}
+
+// IsCanonicalSPDX returns true if s is an SPDX license expression whose
+// license IDs are all in the embedded license list, spelled as in the
+// list, such as "MIT" or "MIT OR Apache-2.0". "LicenseRef-" IDs of custom
+// licenses are accepted too. Free-text names such as "MIT License" and IDs
+// that aren't known, such as a name printed by an exec command, are not.
+func IsCanonicalSPDX(s string) bool {
+	e, err := ParseExpression(s)
+	if err != nil {
+		return false
+	}
+
+	return e.canonical()
+}
+
+// canonical implements IsCanonicalSPDX for a parsed expression.
+func (e *Expression) canonical() bool {
+	if e.Op != "" {
+		for _, arg := range e.Args {
+			if !arg.canonical() {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if strings.HasPrefix(e.ID, "LicenseRef-") {
+		return len(e.ID) > len("LicenseRef-")
+	}
+
+	// "+" means "or any later version" of the license
+	id := strings.TrimSuffix(e.ID, "+")
+	l, ok := spdx.Get(id)
+	return ok && l.ID == id
+}
@@ -0,0 +1,73 @@
+// Package spdx is an offline database of common licenses from the SPDX
+// license list (https://spdx.org/licenses/), used to normalize the free-text
+// license names returned by APIs to SPDX IDs without network requests.
+//
+// The list is embedded from licenses.json. Besides the official ID and name
+// of each license, it has aliases for names that are commonly used instead.
+package spdx
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// License is a single license in the database.
+type License struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+//go:embed licenses.json
+var licensesJSON []byte
+
+var (
+	loadOnce sync.Once
+	byID     map[string]License // lowercase ID to license
+	byName   map[string]string  // normalized ID, name or alias to ID
+)
+
+// Lookup returns the SPDX ID of the license with the given ID, name or
+// alias. The lookup is case insensitive and ignores differences in
+// whitespace.
+func Lookup(name string) (string, bool) {
+	loadOnce.Do(load)
+	id, ok := byName[normalize(name)]
+	return id, ok
+}
+
+// Get returns the license with the given SPDX ID, matched case
+// insensitively.
+func Get(id string) (License, bool) {
+	loadOnce.Do(load)
+	l, ok := byID[strings.ToLower(strings.TrimSpace(id))]
+	return l, ok
+}
+
+// load parses the embedded license list. The list is validated by the
+// tests so an error here is a bug.
+func load() {
+	var ls []License
+	if err := json.Unmarshal(licensesJSON, &ls); err != nil {
+		panic("spdx: invalid embedded license list: " + err.Error())
+	}
+
+	byID = make(map[string]License, len(ls))
+	byName = make(map[string]string, len(ls)*3)
+	for _, l := range ls {
+		byID[strings.ToLower(l.ID)] = l
+		byName[normalize(l.ID)] = l.ID
+		byName[normalize(l.Name)] = l.ID
+		for _, a := range l.Aliases {
+			byName[normalize(a)] = l.ID
+		}
+	}
+}
+
+// normalize lowercases the name and collapses all whitespace to single
+// spaces.
+func normalize(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
@@ -0,0 +1,67 @@
+package spdx
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	cases := []struct {
+		Input  string
+		Output string
+	}{
+		{"Apache License 2.0", "Apache-2.0"},
+		{"Apache-2.0", "Apache-2.0"},
+		{"ASL 2.0", "Apache-2.0"},
+		{"apache license,   version 2.0", "Apache-2.0"},
+		{"MIT", "MIT"},
+		{" The MIT License ", "MIT"},
+		{`BSD 3-Clause "New" or "Revised" License`, "BSD-3-Clause"},
+		{"GNU General Public License v3.0", "GPL-3.0"},
+		{"gpl-3.0-or-later", "GPL-3.0-or-later"},
+		{"Some Custom License", ""},
+		{"", ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Input, func(t *testing.T) {
+			id, ok := Lookup(tt.Input)
+			require.Equal(t, tt.Output != "", ok)
+			require.Equal(t, tt.Output, id)
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	l, ok := Get("apache-2.0")
+	require.True(t, ok)
+	require.Equal(t, "Apache-2.0", l.ID)
+	require.Equal(t, "Apache License 2.0", l.Name)
+
+	_, ok = Get("Apache License 2.0")
+	require.False(t, ok)
+}
+
+func TestLicensesJSON(t *testing.T) {
+	var ls []License
+	require.NoError(t, json.Unmarshal(licensesJSON, &ls))
+	require.NotEmpty(t, ls)
+
+	// Every ID, name and alias must refer to a single license
+	seen := map[string]string{}
+	for _, l := range ls {
+		require.NotEmpty(t, l.ID)
+		require.NotEmpty(t, l.Name, l.ID)
+
+		names := append([]string{l.ID, l.Name}, l.Aliases...)
+		for _, name := range names {
+			key := normalize(name)
+			if other, ok := seen[key]; ok {
+				require.Equal(t, other, l.ID, "%q is used by %s and %s", name, other, l.ID)
+			}
+			seen[key] = l.ID
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package license
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSPDXFromName(t *testing.T) {
+	cases := []struct {
+		Input  string
+		Output string
+	}{
+		{"MIT License", "MIT"},
+		{"apache license 2.0", "Apache-2.0"},
+		{`BSD 3-Clause "New" or "Revised" License`, "BSD-3-Clause"},
+		{"  ISC License ", "ISC"},
+		{"Some Custom License", ""},
+		{"", ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Input, func(t *testing.T) {
+			require.Equal(t, tt.Output, SPDXFromName(tt.Input))
+		})
+	}
+}
+
+func TestIsCanonicalSPDX(t *testing.T) {
+	cases := []struct {
+		Input  string
+		Output bool
+	}{
+		{"MIT", true},
+		{"Apache-2.0 OR (MIT AND BSD-3-Clause)", true},
+		{"GPL-2.0+ WITH Classpath-exception-2.0", true},
+		{"LicenseRef-Internal", true},
+		{"mit", false},
+		{"MIT License", false},
+		{"Custom-Proprietary", false},
+		{"MIT OR Custom-Proprietary", false},
+		{"LicenseRef-", false},
+		{"NOASSERTION", false},
+		{"", false},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Input, func(t *testing.T) {
+			require.Equal(t, tt.Output, IsCanonicalSPDX(tt.Input))
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package license
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// TransientError is an error from a finder that is expected to go away if
+// the lookup is tried again later, such as a rate limit or a server error.
+// A license that couldn't be looked up because of a transient error may
+// still exist, unlike a module that the finder doesn't know about.
+type TransientError struct {
+	Err error
+}
+
+func (e *TransientError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// Transient wraps the error as a TransientError. This returns nil if err
+// is nil.
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &TransientError{Err: err}
+}
+
+// HTTPError returns err for an unexpected HTTP response status, wrapped
+// as a TransientError if the status is a rate limit or a server error.
+func HTTPError(code int, err error) error {
+	if code == http.StatusTooManyRequests || code >= 500 {
+		return Transient(err)
+	}
+
+	return err
+}
+
+// IsTransient returns true if err is a TransientError or the context
+// ended. For the combined errors returned by Find, this is true if any of
+// the finders failed with a transient error.
+func IsTransient(err error) bool {
+	if merr, ok := err.(*multierror.Error); ok {
+		for _, err := range merr.Errors {
+			if IsTransient(err) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	var terr *TransientError
+	return errors.As(err, &terr) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
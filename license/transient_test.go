@@ -0,0 +1,40 @@
+package license
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTransient(t *testing.T) {
+	notFound := errors.New("not found")
+	limited := Transient(errors.New("rate limited"))
+
+	cases := []struct {
+		Name      string
+		Err       error
+		Transient bool
+	}{
+		{"nil", nil, false},
+		{"plain", notFound, false},
+		{"transient", limited, true},
+		{"wrapped", fmt.Errorf("lookup: %w", limited), true},
+		{"context", context.DeadlineExceeded, true},
+		{"multi", multierror.Append(nil, notFound, limited), true},
+		{"multi not transient", multierror.Append(nil, notFound), false},
+		{"status not found", HTTPError(http.StatusNotFound, notFound), false},
+		{"status rate limit", HTTPError(http.StatusTooManyRequests, notFound), true},
+		{"status server error", HTTPError(http.StatusBadGateway, notFound), true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require.Equal(t, tt.Transient, IsTransient(tt.Err))
+		})
+	}
+}
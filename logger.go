@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// LogLevel is the severity of a log message.
+type LogLevel int
+
+const (
+	LogDebug LogLevel = iota
+	LogInfo
+	LogWarn
+	LogError
+)
+
+var logLevelNames = map[LogLevel]string{
+	LogDebug: "DEBUG",
+	LogInfo:  "INFO",
+	LogWarn:  "WARN",
+	LogError: "ERROR",
+}
+
+func (l LogLevel) String() string {
+	if name, ok := logLevelNames[l]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("LogLevel(%d)", int(l))
+}
+
+// ParseLogLevel parses a level name such as "debug" or "WARN".
+func ParseLogLevel(s string) (LogLevel, error) {
+	for level, name := range logLevelNames {
+		if strings.EqualFold(s, name) {
+			return level, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown log level %q, must be one of debug, info, warn or error", s)
+}
+
+// Logger writes leveled log messages, one per line. Messages below Level
+// are discarded. A nil Logger discards everything, so it is always safe
+// to log.
+type Logger struct {
+	Out   io.Writer
+	Level LogLevel
+
+	lock sync.Mutex
+}
+
+// Debugf logs a message at LogDebug.
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.logf(LogDebug, format, args...)
+}
+
+// Infof logs a message at LogInfo.
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.logf(LogInfo, format, args...)
+}
+
+// Warnf logs a message at LogWarn.
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.logf(LogWarn, format, args...)
+}
+
+// Errorf logs a message at LogError.
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.logf(LogError, format, args...)
+}
+
+func (l *Logger) logf(level LogLevel, format string, args ...interface{}) {
+	if l == nil || level < l.Level {
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	fmt.Fprintf(l.Out, "[%s] %s\n", level, strings.TrimSuffix(fmt.Sprintf(format, args...), "\n"))
+}
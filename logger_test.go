@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_level(t *testing.T) {
+	var buf bytes.Buffer
+	l := &Logger{Out: &buf, Level: LogInfo}
+
+	l.Debugf("debug %d", 1)
+	l.Infof("info %d", 2)
+	l.Warnf("warn %d", 3)
+	l.Errorf("error %d\n", 4)
+
+	require.Equal(t, "[INFO] info 2\n[WARN] warn 3\n[ERROR] error 4\n", buf.String())
+}
+
+func TestLogger_nil(t *testing.T) {
+	var l *Logger
+	l.Errorf("discarded")
+}
+
+func TestParseLogLevel(t *testing.T) {
+	cases := []struct {
+		Input    string
+		Expected LogLevel
+		Err      bool
+	}{
+		{"debug", LogDebug, false},
+		{"INFO", LogInfo, false},
+		{"Warn", LogWarn, false},
+		{"error", LogError, false},
+		{"trace", 0, true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Input, func(t *testing.T) {
+			actual, err := ParseLogLevel(tt.Input)
+			if tt.Err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.Expected, actual)
+		})
+	}
+}
@@ -2,353 +2,1163 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"io/ioutil"
-	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"sort"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/google/go-github/v18/github"
-	"github.com/rsc/goversion/version"
 	"golang.org/x/oauth2"
 
+	"github.com/mitchellh/golicense/analysis"
 	"github.com/mitchellh/golicense/config"
 	"github.com/mitchellh/golicense/license"
 	githubFinder "github.com/mitchellh/golicense/license/github"
-	"github.com/mitchellh/golicense/license/golang"
-	"github.com/mitchellh/golicense/license/gopkg"
+	"github.com/mitchellh/golicense/license/goproxy"
 	"github.com/mitchellh/golicense/license/mapper"
-	"github.com/mitchellh/golicense/license/resolver"
 	"github.com/mitchellh/golicense/module"
 )
 
-type moduleVersionLicense struct {
-	Version  string    `json:"version,omitempty"`
-	License  string    `json:"license,omitempty"`
-	SPDX     string    `json:"spdx,omitempty"`
-	Hash     string    `json:"hash,omitempty"`
-	Created  time.Time `json:"created,omitempty"`
-	LastUsed time.Time `json:"used,omitempty"`
-}
-type cachedModule struct {
-	Path   string                 `json:"path,omitempty"`
-	VerLic []moduleVersionLicense `json:"verlic,omitempty"`
-}
-
-type cacheFile struct {
-	Modules []cachedModule
-}
-
-var cacheData cacheFile = cacheFile{}
-var cacheDataLookup map[string]cachedModule
 var skipFiles []string = []string{}
 
 const (
-	EnvGitHubToken = "GITHUB_TOKEN"
+	EnvGitHubToken           = "GITHUB_TOKEN"
+	EnvGitHubURL             = "GITHUB_URL"
+	EnvGitHubEnterpriseToken = "GITHUB_ENTERPRISE_TOKEN"
+	EnvBitbucketToken        = "BITBUCKET_TOKEN"
+	EnvNoColor               = "NO_COLOR"
 )
 
 func main() {
 	os.Exit(realMain())
 }
 
-func readFile(fn string) {
-
-	jsonFile, err := os.Open(fn)
-	// if we os.Open returns an error then handle it
-	if err != nil {
-		fmt.Println(err)
-	}
-	fmt.Printf("Successfully Opened: %s\n", fn)
-	// defer the closing of our jsonFile so that we can parse it later on
-	defer jsonFile.Close()
-
-	// read our opened jsonFile as a byte array.
-	byteValue, _ := ioutil.ReadAll(jsonFile)
-
-	// we unmarshal our byteArray which contains our
-	// jsonFile's content into 'users' which we defined above
-	err = json.Unmarshal(byteValue, &cacheData)
-	if err != nil {
-		fmt.Printf("error: %s\n", err.Error())
-		fmt.Printf("No file found, will attempt to create new \n")
-	}
-
-	cacheDataLookup = map[string]cachedModule{}
-
-	for _, cc := range cacheData.Modules {
-		cacheDataLookup[cc.Path] = cc
-	}
-}
-
 func realMain() int {
 	termOut := &TermOutput{Out: os.Stdout}
 
 	var flagLicense bool
+	var flagConfig string
+	var flagNoConfig bool
 	var flagOutXLSX string
+	var flagOutJUnit string
+	var flagOutHTML string
+	var flagOutMarkdown string
+	var flagOutMetrics string
+	var flagOutJSONL string
+	var flagOutTemplate stringsFlag
+	var flagOutJSON string
+	var flagOutCycloneDX string
+	var flagOutSPDX string
+	var flagReportDir, flagFormats string
+	var flagAttest, flagAttestKey string
 	var flagCache string
+	var flagCacheReadonly bool
+	var flagCacheBase string
+	var flagVerifySum string
+	var flagCacheTTL, flagCacheRetention, flagCacheNegativeTTL time.Duration
+	var flagTimeout, flagLookupTimeout, flagFinderTimeout time.Duration
+	var flagMaxFailures int
+	var flagShowHashes bool
+	var flagGitHubActions bool
+	var flagCheckUpdates bool
+	var flagMaxAge time.Duration
+	var flagGitHubURL string
+	var flagProxy string
+	var flagHTTPCache string
+	var flagListFinders, flagListFormats, flagListExitCodes bool
+	var flagVersion bool
+	var flagVerbose bool
+	var flagLogLevel string
+	var flagDiff bool
+	var flagSBOM bool
+	var flagWatch bool
+	var flagStrict bool
+	var flagIncludeStd bool
+	var flagOffline bool
+	var flagParallel int
+	var flagMinConfidence float64
+	var flagLicenseDirs string
+	var flagBinariesFrom string
 	var skip string
-	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	var flagExclude stringsFlag
+	var flagAllowFile, flagDenyFile stringsFlag
+	// Flag errors return ExitCodeUsage instead of exiting with 2, which
+	// would look like a denied license. The flag package reports the error
+	// itself, and the help is printed below.
+	flags := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	flags.Usage = func() {}
+	flags.StringVar(&flagConfig, "config", "",
+		"path to the configuration file. If set, all arguments are binaries")
+	flags.BoolVar(&flagNoConfig, "no-config", false,
+		"don't look for a .golicense.hcl or .golicense.yaml configuration\n"+
+			"in the current directory and its parents")
 	flags.BoolVar(&flagLicense, "license", true,
 		"look up and verify license. If false, dependencies are\n"+
 			"printed without licenses.")
 	flags.BoolVar(&termOut.Plain, "plain", false, "plain terminal output, no colors or live updates")
-	flags.BoolVar(&termOut.Verbose, "verbose", false, "additional logging to terminal, requires -plain")
+	flags.BoolVar(&flagVerbose, "verbose", false, "log every status update, same as -log-level=debug")
+	flags.StringVar(&flagLogLevel, "log-level", "",
+		"minimum level of the messages logged to stderr: debug, info, warn\n"+
+			"or error (default info, or warn with -quiet or -summary-only)")
+	flags.BoolVar(&termOut.RequireSPDX, "require-spdx", false,
+		"fail if a license can't be mapped to an SPDX ID")
+	flags.BoolVar(&termOut.StrictSPDX, "strict-spdx", false,
+		"fail if a license has no canonical SPDX ID or expression, such as\n"+
+			"a free-text name or an unknown ID (implies -require-spdx)")
+	flags.BoolVar(&termOut.FailUnknown, "fail-unknown", false,
+		"fail with exit code 2 instead of 1 if no license was found for a\n"+
+			"module or its lookup failed")
+	flags.BoolVar(&flagShowHashes, "show-hashes", false,
+		"include the module hash, such as \"h1:abcd1234\", in the terminal\n"+
+			"output and the reports, for checking it against go.sum")
+	flags.BoolVar(&termOut.Quiet, "quiet", false,
+		"only print the modules that fail the check, no progress or summary\n"+
+			"if all modules pass")
+	flags.BoolVar(&termOut.SummaryOnly, "summary-only", false,
+		"only print a single PASS or FAIL line with the number of modules\n"+
+			"by state, no modules or progress")
 	flags.StringVar(&flagOutXLSX, "out-xlsx", "",
 		"save report in Excel XLSX format to the given path")
+	flags.StringVar(&flagOutJSON, "out-json", "",
+		"save report in JSON format to the given path, \"-\" for stdout")
+	flags.StringVar(&flagOutCycloneDX, "out-cyclonedx", "",
+		"save a CycloneDX SBOM in JSON format to the given path")
+	flags.StringVar(&flagOutSPDX, "out-spdx", "",
+		"save an SPDX document in tag-value format to the given path")
+	flags.StringVar(&flagOutJUnit, "out-junit", "",
+		"save report in JUnit XML format to the given path")
+	flags.StringVar(&flagOutMarkdown, "out-md", "",
+		"save report as a GitHub-flavored Markdown table, \"-\" for stdout")
+	flags.StringVar(&flagOutJSONL, "out-jsonl", "",
+		"stream the results as JSON Lines, one object per module as soon as\n"+
+			"it is looked up, to the given path, \"-\" for stdout")
+	flags.StringVar(&flagOutMetrics, "out-metrics", "",
+		"save the lookup metrics (requests, cache hits, finder latency)\n"+
+			"as JSON to the given path, \"-\" for stdout")
+	flags.StringVar(&flagOutHTML, "out-html", "",
+		"save report as an HTML page to the given path")
+	flags.StringVar(&flagReportDir, "report-dir", "",
+		"save a report of each format in -formats to the given directory,\n"+
+			"named report.json, report.xlsx, report.html and so on")
+	flags.StringVar(&flagFormats, "formats", "",
+		"comma-separated formats written to -report-dir: xlsx, json, jsonl,\n"+
+			"cyclonedx, spdx, junit, html or markdown")
+	flags.Var(&flagOutTemplate, "out-template",
+		"save report rendered with a Go text/template, given as TEMPLATE:OUT.\n"+
+			"OUT may be \"-\" for stdout. Can be repeated")
+	flags.StringVar(&flagAttest, "attest", "",
+		"save a JSON report to the given path along with a checksum file")
+	flags.StringVar(&flagAttestKey, "attest-key", "",
+		"sign an in-toto attestation of the -attest report with the\n"+
+			"PEM-encoded Ed25519 private key at the given path")
+	flags.BoolVar(&flagGitHubActions, "github-actions", false,
+		"emit GitHub Actions workflow commands for denied or unknown licenses")
+	flags.BoolVar(&flagOffline, "offline", false,
+		"make no network requests. Licenses are only taken from the cache,\n"+
+			"the overrides in the configuration and -license-dirs")
+	flags.BoolVar(&flagCheckUpdates, "check-updates", false,
+		"query the Go module proxy for newer versions of each module")
+	flags.DurationVar(&flagMaxAge, "max-age", 0,
+		"warn about module versions published longer ago than this, such\n"+
+			"as 17520h for two years. The publish time is queried from the\n"+
+			"Go module proxy, or with -offline taken from pseudo-versions.")
+	flags.StringVar(&flagGitHubURL, "github-url", os.Getenv(EnvGitHubURL),
+		"URL of a GitHub Enterprise instance to look up modules hosted on\n"+
+			"it, such as https://github.example.com (default $GITHUB_URL)")
 	flags.StringVar(&flagCache, "cache", "",
-		"read cached file from the given path")
+		"read cached file from the given path, compressed with gzip if\n"+
+			"it ends in .gz")
+	flags.BoolVar(&flagCacheReadonly, "cache-readonly", false,
+		"never write the -cache file and fail if any module is missing\n"+
+			"from it, listing the missing modules")
+	flags.StringVar(&flagCacheBase, "cache-base", "",
+		"read-only cache files layered below -cache (comma separated).\n"+
+			"Modules found in these are not written to -cache.")
+	flags.StringVar(&flagProxy, "proxy", "",
+		"URL of the HTTP proxy to send all requests through. Defaults to\n"+
+			"the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.")
+	flags.StringVar(&flagHTTPCache, "http-cache", "",
+		"directory to cache HTTP responses of the finders in. Cached\n"+
+			"responses are revalidated with their ETag when they expire.")
+	flags.IntVar(&flagParallel, "parallel", 5,
+		"number of modules to look up concurrently")
+	flags.DurationVar(&flagTimeout, "timeout", 0,
+		"stop looking up licenses after this long (such as 10m) and report\n"+
+			"the remaining modules as errors. Zero means no limit.")
+	flags.DurationVar(&flagLookupTimeout, "lookup-timeout", 0,
+		"report a module as an error if looking up its license takes\n"+
+			"longer than this. Zero means no limit.")
+	flags.DurationVar(&flagFinderTimeout, "finder-timeout", 0,
+		"fail a single request of a network finder, such as the GitHub API,\n"+
+			"that takes longer than this, so the next finder is tried.\n"+
+			"Zero means no limit.")
+	flags.IntVar(&flagMaxFailures, "max-failures", 5,
+		"skip a network finder for a host for the rest of the run after it\n"+
+			"failed this many times in a row there, such as when the host is down.\n"+
+			"Zero means never.")
+	flags.Float64Var(&flagMinConfidence, "min-confidence", 0,
+		"treat licenses detected from their text with a lower confidence\n"+
+			"than this (0 to 1, such as 0.95) as unknown")
+	flags.StringVar(&flagBinariesFrom, "binaries-from", "",
+		"read newline separated paths of binaries to analyze from the\n"+
+			"given file, \"-\" for stdin")
+	flags.StringVar(&flagLicenseDirs, "license-dirs", "",
+		"vendor directories or module caches (GOPATH/pkg/mod) to read\n"+
+			"license files from before using the network (comma separated)")
+	flags.DurationVar(&flagCacheTTL, "cache-ttl", 0,
+		"look up licenses again if they were cached longer ago than this\n"+
+			"(such as 720h). Zero means cached licenses never expire.")
+	flags.DurationVar(&flagCacheNegativeTTL, "cache-negative-ttl", 0,
+		"cache modules without a license for this long (such as 24h) so\n"+
+			"they aren't looked up on every run. Zero means they aren't cached.")
+	flags.DurationVar(&flagCacheRetention, "cache-retention", 365*24*time.Hour,
+		"remove licenses from the -cache file that haven't been used for\n"+
+			"this long. Zero means they are never removed.")
 	flags.StringVar(&skip, "skip", "",
 		"skip all modules that contains these names (comma separated)")
+	flags.StringVar(&flagVerifySum, "verify-sum", "",
+		"go.sum file to verify the module hashes of the binary against.\n"+
+			"A mismatch fails the run with exit code 4")
+	flags.Var(&flagExclude, "exclude",
+		"leave out modules whose path matches this import path or glob\n"+
+			"pattern, such as github.com/myorg/*. Can be repeated")
+	flags.Var(&flagAllowFile, "allow-file",
+		"file with licenses to allow, one name or SPDX ID per line, added\n"+
+			"to the allow list of the configuration. Can be repeated")
+	flags.Var(&flagDenyFile, "deny-file",
+		"file with licenses to deny, one name or SPDX ID per line, added\n"+
+			"to the deny list of the configuration. Can be repeated")
+	flags.BoolVar(&flagListFinders, "list-finders", false,
+		"print the available license finders and exit")
+	flags.BoolVar(&flagListFormats, "list-formats", false,
+		"print the available output formats and exit")
+	flags.BoolVar(&flagListExitCodes, "list-exit-codes", false,
+		"print the exit codes in order of priority and exit")
+	flags.BoolVar(&flagSBOM, "sbom", false,
+		"check CycloneDX (JSON) or SPDX (tag-value) documents given as\n"+
+			"arguments against the configuration instead of binaries")
+	flags.BoolVar(&flagDiff, "diff", false,
+		"compare two -cache files given as arguments (OLD NEW) and print\n"+
+			"the modules that were added, removed, or changed license")
+	flags.BoolVar(&flagStrict, "strict", false,
+		"fail the run if a binary was skipped because it was compiled\n"+
+			"without Go modules")
+	flags.BoolVar(&flagIncludeStd, "include-std", false,
+		"report the Go standard library of each Go version the binaries\n"+
+			"were built with as a \"std\" module with its BSD-3-Clause license")
+	flags.BoolVar(&flagWatch, "watch", false,
+		"analyze the module directory given as argument again whenever\n"+
+			"its go.mod or go.sum changes and print the license changes.\n"+
+			"Report files aren't written in this mode.")
+	flags.BoolVar(&flagVersion, "version", false,
+		"print the version and exit")
 	err := flags.Parse(os.Args[1:])
+	if err == flag.ErrHelp {
+		printHelp(flags)
+		return 0
+	}
 	if err != nil {
-		fmt.Printf("error: %s\n", err.Error())
+		fmt.Fprintln(os.Stderr)
 		printHelp(flags)
-		return 1
+		return ExitCodeUsage
+	}
+
+	// The color package only checks whether stdout is a terminal, but
+	// errors are written to stderr. -plain always disables colors.
+	color.NoColor = termOut.Plain || !useColor(os.Stderr)
+
+	// -verbose and -quiet change the default level, but an explicit
+	// -log-level always wins
+	logLevel := LogInfo
+	switch {
+	case flagVerbose:
+		logLevel = LogDebug
+
+	case termOut.Quiet, termOut.SummaryOnly:
+		logLevel = LogWarn
+	}
+	if flagLogLevel != "" {
+		logLevel, err = ParseLogLevel(flagLogLevel)
+		if err != nil {
+			fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf("❗️ %s\n\n", err)))
+			printHelp(flags)
+			return ExitCodeUsage
+		}
+	}
+	logger := &Logger{Out: os.Stderr, Level: logLevel}
+	termOut.Log = logger
+
+	if flagVersion {
+		fmt.Println(versionString())
+		return 0
+	}
+
+	if flagListFinders || flagListFormats || flagListExitCodes {
+		var lists [][]capability
+		if flagListFinders {
+			lists = append(lists, finders)
+		}
+		if flagListFormats {
+			lists = append(lists, formats)
+		}
+		if flagListExitCodes {
+			lists = append(lists, exitCodes)
+		}
+		for i, cs := range lists {
+			if i > 0 {
+				fmt.Println()
+			}
+			printCapabilities(os.Stdout, cs)
+		}
+
+		return 0
 	}
 
 	args := flags.Args()
-	if len(args) == 0 {
+	if flagDiff {
+		if len(args) != 2 {
+			fmt.Fprint(os.Stderr, color.RedString(
+				"❗️ -diff expects the old and new cache files.\n\n"))
+			printHelp(flags)
+			return ExitCodeUsage
+		}
+
+		var cfs [2]cacheFile
+		for i, fn := range args {
+			cfs[i], err = loadCacheFile(fn)
+			if err != nil {
+				fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
+					"❗️ Error reading cache file: %s\n", err)))
+				return ExitCodeUsage
+			}
+		}
+
+		// The diff is printed unless the JSON goes to stdout instead
+		d := diffCaches(cfs[0], cfs[1])
+		if flagOutJSON != "-" {
+			d.WriteText(os.Stdout)
+		}
+		if flagOutJSON != "" {
+			w := os.Stdout
+			if flagOutJSON != "-" {
+				if w, err = os.Create(flagOutJSON); err != nil {
+					fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
+						"❗️ Error writing JSON diff: %s\n", err)))
+					return ExitCodeUsage
+				}
+				defer w.Close()
+			}
+
+			if err := d.WriteJSON(w); err != nil {
+				fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
+					"❗️ Error writing JSON diff: %s\n", err)))
+				return ExitCodeUsage
+			}
+		}
+
+		// A changed license needs to be reviewed, like one that isn't allowed
+		if len(d.Changed) > 0 {
+			return ExitCodePolicy
+		}
+
+		return 0
+	}
+
+	if len(args) == 0 && flagBinariesFrom == "" {
 		fmt.Fprint(os.Stderr, color.RedString(
 			"❗️ Path to file to analyze expected.\n\n"))
 		printHelp(flags)
-		return 1
+		return ExitCodeUsage
 	}
 
+	if flagParallel < 1 {
+		fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
+			"❗️ -parallel must be at least 1, got %d.\n\n", flagParallel)))
+		printHelp(flags)
+		return ExitCodeUsage
+	}
+
+	if flagTimeout < 0 || flagLookupTimeout < 0 || flagFinderTimeout < 0 || flagMaxFailures < 0 {
+		fmt.Fprint(os.Stderr, color.RedString(
+			"❗️ -timeout, -lookup-timeout, -finder-timeout and -max-failures can't be negative.\n\n"))
+		printHelp(flags)
+		return ExitCodeUsage
+	}
+
+	if flagMinConfidence < 0 || flagMinConfidence > 1 {
+		fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
+			"❗️ -min-confidence must be between 0 and 1, got %g.\n\n", flagMinConfidence)))
+		printHelp(flags)
+		return ExitCodeUsage
+	}
+
+	if flagCacheReadonly && flagCache == "" {
+		fmt.Fprint(os.Stderr, color.RedString(
+			"❗️ -cache-readonly requires -cache to be set.\n\n"))
+		printHelp(flags)
+		return ExitCodeUsage
+	}
+
+	if flagAttestKey != "" && flagAttest == "" {
+		fmt.Fprint(os.Stderr, color.RedString(
+			"❗️ -attest-key requires -attest to be set.\n\n"))
+		printHelp(flags)
+		return ExitCodeUsage
+	}
+
+	if flagSBOM && flagCache != "" {
+		fmt.Fprint(os.Stderr, color.RedString(
+			"❗️ -cache can't be used with -sbom since nothing is looked up.\n\n"))
+		printHelp(flags)
+		return ExitCodeUsage
+	}
+
+	if flagOffline && flagCheckUpdates {
+		fmt.Fprint(os.Stderr, color.RedString(
+			"❗️ -check-updates can't be used with -offline since it queries the module proxy.\n\n"))
+		printHelp(flags)
+		return ExitCodeUsage
+	}
+
+	if flagWatch && flagSBOM {
+		fmt.Fprint(os.Stderr, color.RedString(
+			"❗️ -watch can't be used with -sbom since an SBOM doesn't change.\n\n"))
+		printHelp(flags)
+		return ExitCodeUsage
+	}
+
+	if flagCacheBase != "" && flagCache == "" {
+		fmt.Fprint(os.Stderr, color.RedString(
+			"❗️ -cache-base requires -cache to be set.\n\n"))
+		printHelp(flags)
+		return ExitCodeUsage
+	}
+
+	if (flagReportDir == "") != (flagFormats == "") {
+		fmt.Fprint(os.Stderr, color.RedString(
+			"❗️ -report-dir and -formats must be set together.\n\n"))
+		printHelp(flags)
+		return ExitCodeUsage
+	}
+
+	// -report-dir fills in the -out-* flags that aren't set explicitly
+	if flagReportDir != "" {
+		paths, err := reportDirPaths(flagReportDir, flagFormats)
+		if err != nil {
+			fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf("❗️ %s\n\n", err)))
+			printHelp(flags)
+			return ExitCodeUsage
+		}
+
+		if err := os.MkdirAll(flagReportDir, 0755); err != nil {
+			fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
+				"❗️ Error creating -report-dir: %s\n", err)))
+			return ExitCodeUsage
+		}
+
+		for format, flag := range map[string]*string{
+			"xlsx":      &flagOutXLSX,
+			"json":      &flagOutJSON,
+			"jsonl":     &flagOutJSONL,
+			"cyclonedx": &flagOutCycloneDX,
+			"spdx":      &flagOutSPDX,
+			"junit":     &flagOutJUnit,
+			"html":      &flagOutHTML,
+			"markdown":  &flagOutMarkdown,
+		} {
+			if path, ok := paths[format]; ok && *flag == "" {
+				*flag = path
+			}
+		}
+	}
+
+	var cache *Cache
 	if flagCache != "" {
-		readFile(flagCache)
+		cache = &Cache{
+			TTL:         flagCacheTTL,
+			NegativeTTL: flagCacheNegativeTTL,
+			Retention:   flagCacheRetention,
+			Log:         logger,
+		}
+		if err := cache.Load(flagCache); err != nil {
+			logger.Warnf("Ignoring unreadable cache, starting with an empty one: %s", err)
+		}
+		if flagCacheBase != "" {
+			if err := cache.LoadBase(strings.Split(flagCacheBase, ",")); err != nil {
+				logger.Warnf("Ignoring unreadable base cache: %s", err)
+			}
+		}
 	}
 	if skip != "" {
 		skipFiles = strings.Split(skip, ",")
 	}
 
-	// Determine the exe path and parse the configuration if given.
+	// Determine the exe path and parse the configuration if given. With
+	// -config every argument is a binary. Otherwise the first argument is
+	// the configuration if there are several, or always with
+	// -binaries-from. Without a configuration, one is looked for in the
+	// current directory and its parents unless -no-config is set.
 	var cfg config.Config
-	exePaths := args[:1]
-	if len(args) > 1 {
+	var cfgPath string
+	var exePaths []string
+	switch {
+	case flagConfig != "":
+		cfgPath = flagConfig
+		exePaths = args
+
+	case len(args) == 1 && flagBinariesFrom == "":
+		exePaths = args[:1]
+
+	case len(args) > 1 || (len(args) == 1 && flagBinariesFrom != ""):
+		cfgPath = args[0]
 		exePaths = args[1:]
+	}
+	if cfgPath == "" && !flagNoConfig {
+		path, err := config.Discover(".")
+		if err != nil {
+			fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
+				"❗️ Error looking for a configuration: %s\n", err)))
+			return ExitCodeUsage
+		}
 
-		c, err := config.ParseFile(args[0])
+		cfgPath = path
+	}
+	if cfgPath != "" {
+		c, err := config.ParseFile(cfgPath)
 		if err != nil {
 			fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
 				"❗️ Error parsing configuration:\n\n%s\n", err)))
-			return 1
+			return ExitCodeUsage
 		}
+		logger.Debugf("Loaded configuration from %s", cfgPath)
 
 		// Store the config and set it on the output
 		cfg = *c
 	}
 
-	allMods := map[module.Module]struct{}{}
+	// Shared policy lists are merged into the lists of the configuration
+	for _, f := range []struct {
+		Flag  string
+		Files []string
+		List  *[]string
+	}{
+		{"-allow-file", flagAllowFile, &cfg.Allow},
+		{"-deny-file", flagDenyFile, &cfg.Deny},
+	} {
+		for _, fn := range f.Files {
+			list, err := config.ParseListFile(fn)
+			if err != nil {
+				fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
+					"❗️ Error reading %s: %s\n", f.Flag, err)))
+				return ExitCodeUsage
+			}
+
+			*f.List = config.MergeList(*f.List, list)
+		}
+	}
+
+	if flagBinariesFrom != "" {
+		paths, err := readBinariesFrom(flagBinariesFrom)
+		if err != nil {
+			fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
+				"❗️ Error reading -binaries-from: %s\n", err)))
+			return ExitCodeUsage
+		}
+
+		exePaths = mergePaths(exePaths, paths)
+		if len(exePaths) == 0 {
+			fmt.Fprint(os.Stderr, color.RedString(
+				"❗️ Path to file to analyze expected.\n\n"))
+			printHelp(flags)
+			return ExitCodeUsage
+		}
+	}
+
+	if flagWatch {
+		ok := len(exePaths) == 1
+		if ok {
+			_, ok = analysis.GoModPath(exePaths[0])
+		}
+		if !ok {
+			fmt.Fprint(os.Stderr, color.RedString(
+				"❗️ -watch expects a single module directory or go.mod file.\n\n"))
+			printHelp(flags)
+			return ExitCodeUsage
+		}
+	}
+
+	// The files that were analyzed, for attestations. A directory is
+	// analyzed from its go.mod file.
+	subjects := make([]string, 0, len(exePaths))
 	for _, exePath := range exePaths {
-		// Read the dependencies from the binary itself
-		vsn, err := version.ReadExe(exePath)
+		if goMod, ok := analysis.GoModPath(exePath); ok && !flagSBOM {
+			exePath = goMod
+		}
+		subjects = append(subjects, exePath)
+	}
+
+	// With -sbom the modules and their licenses come from the documents
+	var allMods []module.Module
+	var skippedBinaries int
+	var sbomLicenses *sbomFinder
+	if flagSBOM {
+		allMods, sbomLicenses, err = readSBOMs(exePaths)
 		if err != nil {
 			fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
-				"❗️ Error reading %q: %s\n", args[0], err)))
-			return 1
+				"❗️ Error reading SBOM: %s\n", err)))
+			return ExitCodeUsage
+		}
+	} else {
+		// A binary without module information is skipped if there are
+		// others to check, such as in a mix of module and GOPATH binaries
+		var noModuleInfo []*analysis.ReadError
+		allMods, noModuleInfo, err = analysis.ReadModulesSkipping(exePaths)
+		skipped := map[string]bool{}
+		for _, rerr := range noModuleInfo {
+			fmt.Fprint(os.Stderr, color.YellowString(fmt.Sprintf(
+				"⚠️  Skipping %q: it was compiled without using Go modules.\n", rerr.Path)))
+			skipped[rerr.Path] = true
+		}
+		skippedBinaries = len(noModuleInfo)
+
+		// Skipped binaries weren't analyzed, so they aren't attested
+		kept := subjects[:0]
+		for _, subject := range subjects {
+			if !skipped[subject] {
+				kept = append(kept, subject)
+			}
 		}
+		subjects = kept
 
-		if vsn.ModuleInfo == "" {
+		if flagIncludeStd && err == nil {
+			allMods = append(allMods, analysis.StdModules(subjects)...)
+		}
+	}
+	var readErr *analysis.ReadError
+	if errors.As(err, &readErr) {
+		if errors.Is(readErr.Err, analysis.ErrNoModuleInfo) {
 			// ModuleInfo empty means that the binary didn't use Go modules
 			// or it could mean that a binary has no dependencies. Either way
 			// we error since we can't be sure.
 			fmt.Fprint(os.Stderr, color.YellowString(fmt.Sprintf(
 				"⚠️  %q ⚠️\n\n"+
 					"This executable was compiled without using Go modules or has \n"+
-					"zero dependencies. golicense considers this an error (exit code 1).\n", exePath)))
-			return 1
+					"zero dependencies. golicense considers this an error (exit code 3).\n", readErr.Path)))
+			return ExitCodeUsage
 		}
 
-		// From the raw module string from the binary, we need to parse this
-		// into structured data with the module information.
-		mods, err := module.ParseExeData(vsn.ModuleInfo)
+		fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
+			"❗️ Error reading %q: %s\n", readErr.Path, readErr.Err)))
+		return ExitCodeUsage
+	}
+
+	// Verify the hashes of all modules, including skipped ones, since
+	// skipping only concerns licenses
+	var sumMismatches []analysis.SumMismatch
+	if flagVerifySum != "" {
+		sumMismatches, err = analysis.VerifySum(allMods, flagVerifySum)
 		if err != nil {
 			fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
-				"❗️ Error parsing dependencies: %s\n", err)))
-			return 1
-		}
-		for _, mod := range mods {
-			allMods[mod] = struct{}{}
+				"❗️ Error reading go.sum file: %s\n", err)))
+			return ExitCodeUsage
 		}
 	}
 
-	mods := make([]module.Module, 0, len(allMods))
-	for mod := range allMods {
-		skipthis := false
-		if skip != "" {
-			for _, s := range skipFiles {
-				if strings.Contains(mod.String(), s) {
-					fmt.Printf("Skipping module: %s \n", mod.String())
-					skipthis = true
-				}
-			}
+	mods, mainMods := analysis.SkipMain(allMods)
+	for _, mod := range mainMods {
+		logger.Infof("Skipping main module: %s", mod.String())
+	}
+
+	mods, skipped := analysis.Skip(mods, skipFiles)
+	for _, mod := range skipped {
+		logger.Infof("Skipping module: %s", mod.String())
+	}
+
+	// Excluded modules are left out completely, so they are removed before
+	// the configuration can apply anything else to them
+	mods, excluded := analysis.Exclude(mods, append(flagExclude, cfg.Exclude...))
+	for _, mod := range excluded {
+		logger.Infof("Excluding module: %s", mod.String())
+	}
+
+	// Internal modules are listed but never looked up over the network
+	for _, mod := range analysis.MarkPrivate(mods, analysis.PrivatePatterns(&cfg)) {
+		logger.Debugf("Internal module: %s", mod.String())
+	}
+
+	// Templates are parsed before anything is looked up so that a
+	// mistake in one fails right away
+	var templateOuts []*TemplateOutput
+	for _, v := range flagOutTemplate {
+		tmplPath, path, err := parseTemplateFlag(v)
+		if err != nil {
+			fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf("❗️ %s\n\n", err)))
+			printHelp(flags)
+			return ExitCodeUsage
 		}
-		if !skipthis {
-			mods = append(mods, mod)
+
+		out, err := NewTemplateOutput(tmplPath, path, &cfg)
+		if err != nil {
+			fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
+				"❗️ Error loading -out-template: %s\n", err)))
+			return ExitCodeUsage
 		}
+		out.ShowHashes = flagShowHashes
+		templateOuts = append(templateOuts, out)
 	}
 
 	// Complete terminal output setup
+	stdoutReports := 0
+	for _, path := range []string{flagOutJSON, flagOutJSONL, flagOutMarkdown, flagOutMetrics} {
+		if path == "-" {
+			stdoutReports++
+		}
+	}
+	for _, out := range templateOuts {
+		if out.Path == "-" {
+			stdoutReports++
+		}
+	}
+	if stdoutReports > 1 {
+		fmt.Fprint(os.Stderr, color.RedString(
+			"❗️ Only one of -out-json, -out-jsonl, -out-md, -out-metrics and -out-template can be written to stdout.\n\n"))
+		return ExitCodeUsage
+	}
+	if stdoutReports > 0 {
+		// Keep stdout clean for the report
+		termOut.Out = os.Stderr
+	}
 	termOut.Config = &cfg
 	termOut.Modules = mods
+	termOut.ShowHashes = flagShowHashes
 
 	// Setup the outputs
 	out := &MultiOutput{Outputs: []Output{termOut}}
+	reports := &ResultStore{}
 	if flagOutXLSX != "" {
-		out.Outputs = append(out.Outputs, &XLSXOutput{
-			Path:   flagOutXLSX,
+		reports.Reports = append(reports.Reports, &XLSXOutput{
+			Path:       flagOutXLSX,
+			Config:     &cfg,
+			ShowHashes: flagShowHashes,
+		})
+	}
+	if flagOutJSON != "" {
+		reports.Reports = append(reports.Reports, &JSONOutput{
+			Path:       flagOutJSON,
+			Config:     &cfg,
+			ShowHashes: flagShowHashes,
+		})
+	}
+	if flagOutJSONL != "" {
+		out.Outputs = append(out.Outputs, &JSONLOutput{
+			Path:       flagOutJSONL,
+			Config:     &cfg,
+			ShowHashes: flagShowHashes,
+		})
+	}
+	if flagOutCycloneDX != "" {
+		reports.Reports = append(reports.Reports, &CycloneDXOutput{
+			Path:   flagOutCycloneDX,
+			Config: &cfg,
+		})
+	}
+	if flagOutSPDX != "" {
+		reports.Reports = append(reports.Reports, &SPDXOutput{
+			Path:   flagOutSPDX,
+			Config: &cfg,
+		})
+	}
+	if flagOutJUnit != "" {
+		reports.Reports = append(reports.Reports, &JUnitOutput{
+			Path:   flagOutJUnit,
+			Config: &cfg,
+		})
+	}
+	if flagOutHTML != "" {
+		reports.Reports = append(reports.Reports, &HTMLOutput{
+			Path:       flagOutHTML,
+			Config:     &cfg,
+			ShowHashes: flagShowHashes,
+		})
+	}
+	if flagOutMarkdown != "" {
+		reports.Reports = append(reports.Reports, &MarkdownOutput{
+			Path:       flagOutMarkdown,
+			Config:     &cfg,
+			ShowHashes: flagShowHashes,
+		})
+	}
+	for _, o := range templateOuts {
+		reports.Reports = append(reports.Reports, o)
+	}
+	if flagAttest != "" {
+		reports.Reports = append(reports.Reports, &AttestOutput{
+			Path:     flagAttest,
+			KeyPath:  flagAttestKey,
+			Binaries: subjects,
+			Config:   &cfg,
+		})
+	}
+	if flagGitHubActions {
+		reports.Reports = append(reports.Reports, &GitHubActionsOutput{
+			Out:    os.Stdout,
 			Config: &cfg,
 		})
 	}
+	if len(reports.Reports) > 0 {
+		out.Outputs = append(out.Outputs, reports)
+	}
 
-	// Setup a context. We don't connect this to an interrupt signal or
-	// anything since we just exit immediately on interrupt. No cleanup
-	// necessary.
-	ctx := context.Background()
-
-	// Auth with GitHub if available
-	var githubClient *http.Client
-	if v := os.Getenv(EnvGitHubToken); v != "" {
-		ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: v})
-		githubClient = oauth2.NewClient(ctx, ts)
-	}
-
-	// Build our translators and license finders
-	ts := []license.Translator{
-		&mapper.Translator{Map: cfg.Translate},
-		&resolver.Translator{},
-		&golang.Translator{},
-		&gopkg.Translator{},
-	}
-	var fs []license.Finder
-	if flagLicense {
-		fs = []license.Finder{
-			&mapper.Finder{Map: cfg.Override},
-			&githubFinder.RepoAPI{
-				Client: github.NewClient(githubClient),
-			},
-		}
-	}
-
-	// Kick off all the license lookups.
-	var wg sync.WaitGroup
-	sem := NewSemaphore(5)
-	count := 0
-	for _, m := range mods {
-		count++
-		wg.Add(1)
-		go func(m module.Module) {
-			defer wg.Done()
-
-			// Acquire a semaphore so that we can limit concurrency
-			sem.Acquire()
-			defer sem.Release()
-
-			// Build the context
-			ctx = license.StatusWithContext(ctx, StatusListener(out, &m))
-
-			// Lookup
-			out.Start(&m)
-			var lic *license.License
-			var err error
-			if flagCache != "" {
-
-				found := false
-				index := 0
-				cca, ok := cacheDataLookup[m.Path]
-				if ok {
-					for vvk, vv := range cca.VerLic {
-						if vv.Version == m.Version {
-							if vv.Hash != m.Hash {
-								os.Exit(1)
-							}
-							found = true
-							index = vvk
-						}
-					}
-				}
-				if ok && found {
-					ccc := cacheDataLookup[m.Path]
-					ccc.VerLic[index].LastUsed = time.Now()
-					lic = &license.License{Name: cca.VerLic[index].License, SPDX: cca.VerLic[index].SPDX}
-					cacheDataLookup[m.Path] = ccc
-				} else {
-					count++
-					// We first try the untranslated version. If we can detect
-					// a license then take that. Otherwise, we translate.
-					lic, err = license.Find(ctx, m, fs)
-					if lic == nil || err != nil {
-						lic, err = license.Find(ctx, license.Translate(ctx, m, ts), fs)
-					}
-
-					if lic != nil && err == nil {
-						c2, ok2 := cacheDataLookup[m.Path]
-
-						var newVerLic moduleVersionLicense
-						newVerLic.Version = m.Version
-						newVerLic.License = lic.Name
-						newVerLic.SPDX = lic.SPDX
-						newVerLic.Hash = m.Hash
-						newVerLic.Created = time.Now()
-						newVerLic.LastUsed = time.Now()
-
-						if ok2 {
-							c2.VerLic = append(c2.VerLic, newVerLic)
-						} else {
-							var newMod cachedModule
-							newMod.Path = m.Path
-							newMod.VerLic = append(newMod.VerLic, newVerLic)
-
-							cacheData.Modules = append(cacheData.Modules, newMod)
-						}
-					}
-				}
-			} else {
-				count++
-				// We first try the untranslated version. If we can detect
-				// a license then take that. Otherwise, we translate.
-				lic, err = license.Find(ctx, m, fs)
-				if lic == nil || err != nil {
-					lic, err = license.Find(ctx, license.Translate(ctx, m, ts), fs)
-				}
-			}
-			out.Finish(&m, lic, err)
-		}(m)
-		/*
-			if count > 100 {
-				break
-			}*/
+	// Setup a context that is cancelled on interrupt, so that lookups in
+	// flight and modules waiting for a slot stop and the outputs and cache
+	// are still written for what finished. A second interrupt exits
+	// immediately as usual.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	if flagTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, flagTimeout)
+		defer cancel()
 	}
 
-	// Wait for all lookups to complete
-	wg.Wait()
+	// Metrics are only collected if they are shown
+	var metrics *license.Metrics
+	if flagVerbose || flagOutMetrics != "" {
+		metrics = &license.Metrics{}
+		ctx = license.MetricsWithContext(ctx, metrics)
+	}
 
-	if flagCache != "" {
+	var licenseDirs []string
+	if flagLicenseDirs != "" {
+		licenseDirs = strings.Split(flagLicenseDirs, ",")
+	}
 
-		content, err := json.Marshal(cacheData)
+	// Build our translators and license finders. The finders are
+	// registered in their default order and the configuration may pick
+	// which of them are used and in what order.
+	var httpClient *http.Client
+	var ts []license.Translator
+	finderOpts := analysis.FinderOptions{
+		Offline:     flagOffline,
+		LicenseDirs: licenseDirs,
+	}
+	if flagOffline {
+		// No client is created for the network finders, and libraries
+		// that use the default client fail instead of connecting.
+		http.DefaultTransport = offlineTransport{}
+		ts = analysis.OfflineTranslators(&cfg)
+	} else {
+		// All requests go through the same transport so that they use the
+		// proxy. Libraries that don't accept a client use the default one.
+		transport, err := newHTTPTransport(flagProxy)
 		if err != nil {
-			fmt.Println(err)
+			fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
+				"❗️ Error parsing -proxy: %s\n", err)))
+			return ExitCodeUsage
 		}
-		err = ioutil.WriteFile(flagCache, content, 0644)
+		http.DefaultTransport = transport
+
+		// Responses served from the HTTP cache are not counted as requests
+		var clientTransport http.RoundTripper = metricsTransport{Base: transport}
+		if flagHTTPCache != "" {
+			clientTransport = &httpCacheTransport{Dir: flagHTTPCache, Base: clientTransport, Log: logger}
+		}
+		httpClient = &http.Client{Transport: clientTransport}
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+
+		// Auth with GitHub if available. Multiple comma separated tokens are
+		// used in turn to multiply the rate limit.
+		githubClient := httpClient
+		var githubClients []*github.Client
+		for _, v := range strings.Split(os.Getenv(EnvGitHubToken), ",") {
+			if v = strings.TrimSpace(v); v == "" {
+				continue
+			}
+
+			ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: v})
+			tokenClient := oauth2.NewClient(ctx, ts)
+			if len(githubClients) == 0 {
+				githubClient = tokenClient
+			}
+			githubClients = append(githubClients, github.NewClient(tokenClient))
+		}
+
+		// GitHub Enterprise, if configured. This uses its own token if given
+		// since a github.com token isn't valid there.
+		var enterpriseHost string
+		var enterpriseClient *github.Client
+		if flagGitHubURL != "" {
+			enterpriseHTTP := githubClient
+			if v := os.Getenv(EnvGitHubEnterpriseToken); v != "" {
+				ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: v})
+				enterpriseHTTP = oauth2.NewClient(ctx, ts)
+			}
+
+			enterpriseClient, enterpriseHost, err = githubFinder.NewEnterpriseClient(flagGitHubURL, enterpriseHTTP)
+			if err != nil {
+				fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
+					"❗️ Error parsing -github-url: %s\n", err)))
+				return ExitCodeUsage
+			}
+		}
+
+		ts = analysis.DefaultTranslators(&cfg, httpClient)
+		finderOpts.Client = httpClient
+		finderOpts.GitHubClient = github.NewClient(githubClient)
+		finderOpts.GitHubClients = githubClients
+		finderOpts.GitHubEnterpriseHost = enterpriseHost
+		finderOpts.GitHubEnterpriseClient = enterpriseClient
+		finderOpts.BitbucketToken = os.Getenv(EnvBitbucketToken)
+		finderOpts.Translators = ts
+	}
+	finderReg := analysis.FinderRegistry(ctx, &cfg, finderOpts)
+
+	fs := []license.Finder{}
+	if flagLicense && !flagSBOM {
+		for _, name := range cfg.Finders {
+			if name == "exec" && len(cfg.Exec) == 0 {
+				fmt.Fprint(os.Stderr, color.RedString(
+					"❗️ The \"exec\" finder requires an \"exec\" command in the configuration.\n"))
+				return ExitCodeUsage
+			}
+			if name == "raw" && len(cfg.Raw) == 0 {
+				fmt.Fprint(os.Stderr, color.RedString(
+					"❗️ The \"raw\" finder requires \"raw\" URLs in the configuration.\n"))
+				return ExitCodeUsage
+			}
+		}
+
+		fs, err = finderReg.Finders(cfg.Finders)
 		if err != nil {
-			log.Fatal(err)
+			fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
+				"❗️ Error in the finders of the configuration: %s\n", err)))
+			return ExitCodeUsage
+		}
+	}
+
+	// A network finder whose host is down is skipped after a few failures
+	// rather than failing every module in turn
+	fs = license.MapFinders(fs, func(f license.Finder) license.Finder {
+		switch license.FinderName(f) {
+		case "pkggodev", "goproxy", "github", "bitbucket", "raw":
+			return &license.Breaker{Finder: f, Threshold: flagMaxFailures, Timeout: flagFinderTimeout}
+		}
+		return f
+	})
+
+	// An SBOM is checked as it is, apart from the overrides
+	if flagLicense && flagSBOM {
+		ts = []license.Translator{}
+		fs = []license.Finder{&mapper.Finder{Map: cfg.Override}, sbomLicenses}
+	}
+
+	// Internal modules only use the finders that don't use the network,
+	// and the raw finder since it only asks the configured hosts, which
+	// are usually internal. Nothing in an SBOM is looked up over the
+	// network anyway.
+	privateFs := license.MapFinders(fs, func(f license.Finder) license.Finder {
+		switch license.FinderName(f) {
+		case "override", "local", "exec", "raw":
+			return f
 		}
+		return nil
+	})
+	if flagSBOM {
+		privateFs = fs
+	}
+
+	opts := analysis.Options{
+		Config:         &cfg,
+		Finders:        fs,
+		Translators:    ts,
+		PrivateFinders: privateFs,
+		Parallel:       flagParallel,
+		LookupTimeout:  flagLookupTimeout,
+		MinConfidence:  flagMinConfidence,
+		Listener:       out,
+	}
+
+	// Used to check for newer versions of modules, if requested
+	if flagCheckUpdates {
+		opts.Updates = &goproxy.Proxy{Client: httpClient}
+	}
+
+	// Used to flag old module versions, if requested. Offline only the
+	// dates of pseudo-versions are known.
+	if flagMaxAge > 0 {
+		opts.MaxAge = flagMaxAge
+		if !flagOffline {
+			opts.Versions = &goproxy.Proxy{Client: httpClient}
+		}
+	}
+
+	// Modules that weren't found in a read-only cache. These are still
+	// looked up so the report is complete, but the run fails at the end.
+	var readonly *readonlyCache
+	switch {
+	case cache != nil && flagCacheReadonly:
+		readonly = &readonlyCache{Cache: cache}
+		opts.Cache = readonly
+
+	case cache != nil:
+		opts.Cache = cache
+	}
+
+	if flagWatch {
+		// Without -cache, a cache in memory still avoids looking up the
+		// same modules on every change. Modules without a license aren't
+		// looked up again either, since that rarely changes while watching.
+		if opts.Cache == nil {
+			opts.Cache = &Cache{NegativeTTL: 24 * time.Hour}
+		}
+		opts.Listener = nil
+		opts.Skip = skipFiles
+		opts.Exclude = flagExclude
+
+		w := &watcher{Dir: exePaths[0], Out: os.Stdout, Options: opts}
+		if cache != nil && !flagCacheReadonly {
+			w.AfterRun = func() error { return cache.Save(flagCache) }
+		}
+
+		logger.Infof("Watching %s for changes, press Ctrl-C to stop", exePaths[0])
+		w.Run(ctx)
+		return 0
+	}
+
+	// Kick off all the license lookups and wait for them to complete.
+	results := analysis.Resolve(ctx, mods, opts)
+
+	// A failure to save the cache fails the run, but only after the
+	// outputs are closed so that the reports are still written.
+	var saveErr error
+	if cache != nil && !flagCacheReadonly {
+		if saveErr = cache.Save(flagCache); saveErr != nil {
+			logger.Errorf("Error saving cache %q: %s", flagCache, saveErr)
+		}
+	}
+
+	// Modules skipped because a finder failed too often are failed lookups
+	// like any other, but the cause is worth pointing out once
+	unavailable := map[string]int{}
+	var unavailableNames []string
+	for _, r := range results {
+		for _, name := range license.UnavailableFinders(r.Err) {
+			if unavailable[name] == 0 {
+				unavailableNames = append(unavailableNames, name)
+			}
+			unavailable[name]++
+		}
+	}
+	sort.Strings(unavailableNames)
+	for _, name := range unavailableNames {
+		logger.Warnf("Finder %s failed %d times in a row and was skipped for %d modules",
+			name, flagMaxFailures, unavailable[name])
 	}
 
 	// Close the output
 	if err := out.Close(); err != nil {
 		fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
 			"❗️ Error: %s\n", err)))
-		return 1
+		return ExitCodeUsage
+	}
+	if saveErr != nil {
+		return ExitCodeUsage
+	}
+
+	if metrics != nil {
+		summary := metrics.Summary()
+		for _, line := range strings.Split(summary.String(), "\n") {
+			logger.Debugf("Metrics: %s", line)
+		}
+
+		if flagOutMetrics != "" {
+			if err := writeMetrics(flagOutMetrics, summary); err != nil {
+				fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
+					"❗️ Error writing -out-metrics: %s\n", err)))
+				return ExitCodeUsage
+			}
+		}
+	}
+
+	// Modules whose hash doesn't match the cache fail the run. The module
+	// in the binary isn't the one that was cached, so neither the cache
+	// nor a fresh lookup can be trusted.
+	var mismatched []string
+	for _, r := range results {
+		if _, ok := r.Err.(*hashMismatchError); ok {
+			mismatched = append(mismatched, fmt.Sprintf("  %s\n", r.Err))
+		}
+	}
+	if len(mismatched) > 0 {
+		sort.Strings(mismatched)
+
+		fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
+			"❗️ %d module(s) don't match the cached hash:\n\n%s",
+			len(mismatched), strings.Join(mismatched, ""))))
+		return ExitCodeSumMismatch
 	}
 
-	return termOut.ExitCode()
+	// A read-only cache must cover every module, otherwise it wasn't
+	// refreshed before this run.
+	if missing := readonly.Missing(); len(missing) > 0 {
+		sort.Sort(module.SortByPath(missing))
+		var buf strings.Builder
+		for _, m := range missing {
+			buf.WriteString(fmt.Sprintf("  %s\n", m.String()))
+		}
+
+		fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
+			"❗️ %d module(s) missing from read-only cache %q:\n\n%s",
+			len(missing), flagCache, buf.String())))
+		return ExitCodeUsage
+	}
+
+	// A module that doesn't match go.sum may have been tampered with, so
+	// this takes priority over the license results.
+	if len(sumMismatches) > 0 {
+		var buf strings.Builder
+		for _, m := range sumMismatches {
+			buf.WriteString(fmt.Sprintf("  %s\n", m.String()))
+		}
+
+		fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
+			"❗️ %d module(s) don't match %q:\n\n%s",
+			len(sumMismatches), flagVerifySum, buf.String())))
+		termOut.SumMismatch()
+	}
+
+	// Skipped binaries weren't checked at all, which fails the run only
+	// if requested since a mix of binaries is often expected
+	if code := termOut.ExitCode(); code != 0 || !flagStrict || skippedBinaries == 0 {
+		return code
+	}
+
+	fmt.Fprint(os.Stderr, color.RedString(fmt.Sprintf(
+		"❗️ %d binary(s) were skipped because they have no module information\n",
+		skippedBinaries)))
+	return ExitCodeUnknown
+}
+
+// stringsFlag is a flag that can be given multiple times.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
 }
 
 func printHelp(fs *flag.FlagSet) {
@@ -360,11 +1170,12 @@ const help = `
 golicense analyzes the dependencies of a binary compiled from Go.
 
 Usage: %[1]s [flags] [BINARY]
-Usage: %[1]s [flags] [CONFIG] [BINARY]
+Usage: %[1]s [flags] [CONFIG] [BINARY...]
+Usage: %[1]s [flags] -config=CONFIG [BINARY...]
 
-One or two arguments can be given: a binary by itself which will output
-all the licenses of dependencies, or a configuration file and a binary
-which also notes which licenses are allowed among other settings.
+A binary by itself will output all the licenses of dependencies. With a
+configuration file, given with -config or as the first of several
+arguments, it also notes which licenses are allowed among other settings.
 
 For full help text, see the README in the GitHub repository:
 http://github.com/mitchellh/golicense
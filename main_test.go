@@ -0,0 +1,424 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mitchellh/golicense/analysis"
+)
+
+func TestRealMain_readErrorPath(t *testing.T) {
+	dir := t.TempDir()
+	cfg := filepath.Join(dir, "config.hcl")
+	require.NoError(t, ioutil.WriteFile(cfg, []byte("allow = [\"MIT\"]\n"), 0644))
+	invalid := filepath.Join(dir, "not-a-binary")
+	require.NoError(t, ioutil.WriteFile(invalid, []byte("hello"), 0644))
+
+	// The test binary is a valid Go binary
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	// Capture stderr, where the error is reported
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	args, stderr := os.Args, os.Stderr
+	os.Args = []string{"golicense", "-license=false", cfg, exe, invalid}
+	os.Stderr = w
+	defer func() { os.Args, os.Stderr = args, stderr }()
+
+	code := realMain()
+	os.Stderr = stderr
+	require.NoError(t, w.Close())
+	out, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+
+	// The error names the binary that failed, not the configuration or the
+	// first binary
+	require.Equal(t, ExitCodeUsage, code)
+	require.Contains(t, string(out), invalid)
+	require.NotContains(t, string(out), cfg)
+	require.NotContains(t, string(out), exe)
+}
+
+func TestRealMain_offline(t *testing.T) {
+	// Every request would go through this proxy
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.Error(w, "offline", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	cfg := filepath.Join(t.TempDir(), "config.hcl")
+	require.NoError(t, ioutil.WriteFile(cfg, []byte(`
+override = {
+  "github.com/stretchr/testify" = "MIT"
+}
+`), 0644))
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	code, out := runMain(t, "golicense", "-offline", "-plain", "-proxy="+srv.URL, cfg, exe)
+	require.Zero(t, atomic.LoadInt32(&requests))
+
+	// The override is used and the other modules have no license, which
+	// fails the run, but they aren't failed lookups
+	require.Equal(t, ExitCodePolicy, code, out)
+	require.Regexp(t, `github.com/stretchr/testify +MIT`, out)
+	require.Regexp(t, `github.com/davecgh/go-spew +<license not found or detected>`, out)
+	require.Contains(t, out, " 0 failed")
+}
+
+// runMain runs realMain with the given arguments and returns its exit code
+// and stdout.
+func runMain(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	oldArgs, stdout, transport := os.Args, os.Stdout, http.DefaultTransport
+	os.Args, os.Stdout = args, w
+	defer func() { os.Args, os.Stdout, http.DefaultTransport = oldArgs, stdout, transport }()
+
+	// Read concurrently so a large output doesn't block on the pipe
+	done := make(chan []byte)
+	go func() {
+		data, _ := ioutil.ReadAll(r)
+		done <- data
+	}()
+
+	code := realMain()
+	os.Stdout = stdout
+	require.NoError(t, w.Close())
+	return code, string(<-done)
+}
+
+func TestRealMain_configFlag(t *testing.T) {
+	dir := t.TempDir()
+	cfg := filepath.Join(dir, "config.hcl")
+	require.NoError(t, ioutil.WriteFile(cfg, []byte(`
+override = {
+  "github.com/foo/bar"          = "MIT"
+  "github.com/stretchr/testify" = "MIT"
+}
+`), 0644))
+
+	// The second "binary" is a module that requires a module the test
+	// binary doesn't have
+	mod := filepath.Join(dir, "mod")
+	require.NoError(t, os.Mkdir(mod, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(mod, "go.mod"), []byte(`module example.com/foo
+
+require github.com/foo/bar v1.0.0
+`), 0644))
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	// Both arguments are analyzed with the configuration
+	code, out := runMain(t, "golicense", "-offline", "-plain", "-config", cfg, exe, mod)
+	require.Equal(t, ExitCodePolicy, code, out)
+	require.Regexp(t, `github.com/stretchr/testify +MIT`, out)
+	require.Regexp(t, `github.com/foo/bar +MIT`, out)
+}
+
+func TestRealMain_execFinder(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub command is a shell script")
+	}
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "license.sh")
+	require.NoError(t, ioutil.WriteFile(script, []byte("#!/bin/sh\necho MIT\n"), 0644))
+
+	// The exec finder comes first, so it wins over the override
+	cfg := filepath.Join(dir, "config.hcl")
+	require.NoError(t, ioutil.WriteFile(cfg, []byte(fmt.Sprintf(`
+allow   = ["MIT"]
+finders = ["exec", "override"]
+exec    = ["sh", %q]
+
+override = {
+  "github.com/stretchr/testify" = "GPL-3.0"
+}
+`, script)), 0644))
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	code, out := runMain(t, "golicense", "-offline", "-plain", cfg, exe)
+	require.Equal(t, 0, code, out)
+	require.Regexp(t, `github.com/stretchr/testify +MIT License`, out)
+
+	// Unknown finders are an error
+	require.NoError(t, ioutil.WriteFile(cfg, []byte(`finders = ["nope"]`), 0644))
+	code, _ = runMain(t, "golicense", "-offline", "-plain", cfg, exe)
+	require.Equal(t, ExitCodeUsage, code)
+}
+
+func TestRealMain_equalPriority(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub command is a shell script")
+	}
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "license.sh")
+	require.NoError(t, ioutil.WriteFile(script, []byte("#!/bin/sh\necho MIT\n"), 0644))
+
+	// Neither finder wins by its order, the smallest SPDX ID is used
+	cfg := filepath.Join(dir, "config.hcl")
+	require.NoError(t, ioutil.WriteFile(cfg, []byte(fmt.Sprintf(`
+finders = ["exec|override"]
+exec    = ["sh", %q]
+
+override = {
+  "github.com/stretchr/testify" = "Apache-2.0"
+}
+`, script)), 0644))
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	report := filepath.Join(dir, "report.json")
+	code, out := runMain(t, "golicense", "-offline", "-plain", "-out-json", report, cfg, exe)
+	require.Equal(t, 0, code, out)
+	require.Regexp(t, `github.com/stretchr/testify +Apache License 2.0 \(conflicts with MIT\)`, out)
+
+	data, err := ioutil.ReadFile(report)
+	require.NoError(t, err)
+	var modules []reportModule
+	require.NoError(t, json.Unmarshal(data, &modules))
+	var found bool
+	for _, m := range modules {
+		if m.Path == "github.com/stretchr/testify" {
+			found = true
+			require.Equal(t, "Apache-2.0", m.SPDX)
+			require.Equal(t, []string{"MIT"}, m.Conflicts)
+		} else {
+			require.Empty(t, m.Conflicts, m.Path)
+		}
+	}
+	require.True(t, found)
+}
+
+func TestRealMain_strictSPDX(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub command is a shell script")
+	}
+
+	// The command prints a license name that isn't an SPDX ID
+	dir := t.TempDir()
+	script := filepath.Join(dir, "license.sh")
+	require.NoError(t, ioutil.WriteFile(script, []byte("#!/bin/sh\necho Custom-Proprietary\n"), 0644))
+	cfg := filepath.Join(dir, "config.hcl")
+	require.NoError(t, ioutil.WriteFile(cfg, []byte(fmt.Sprintf(`
+allow   = ["Custom-Proprietary"]
+finders = ["exec"]
+exec    = ["sh", %q]
+`, script)), 0644))
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	code, out := runMain(t, "golicense", "-offline", "-plain", "-require-spdx", cfg, exe)
+	require.Equal(t, 0, code, out)
+
+	code, out = runMain(t, "golicense", "-offline", "-plain", "-strict-spdx", cfg, exe)
+	require.Equal(t, ExitCodePolicy, code, out)
+}
+
+func TestRealMain_discoverConfig(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	require.NoError(t, os.Mkdir(sub, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, ".golicense.hcl"), []byte(`
+override = {
+  "github.com/stretchr/testify" = "MIT"
+}
+`), 0644))
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(wd)
+
+	// The configuration is found in the current directory and its parents
+	for _, dir := range []string{root, sub} {
+		require.NoError(t, os.Chdir(dir))
+		_, out := runMain(t, "golicense", "-offline", "-plain", exe)
+		require.Regexp(t, `github.com/stretchr/testify +MIT`, out)
+	}
+
+	// -no-config ignores it
+	_, out := runMain(t, "golicense", "-offline", "-plain", "-no-config", exe)
+	require.Regexp(t, `github.com/stretchr/testify +<license not found or detected>`, out)
+}
+
+func TestRealMain_noModuleInfo(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a binary")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not found")
+	}
+
+	// Build a binary in GOPATH mode, which has no module information
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "main.go"),
+		[]byte("package main\n\nfunc main() {}\n"), 0644))
+	gopath := filepath.Join(dir, "gopath-binary")
+	cmd := exec.Command(goBin, "build", "-o", gopath, "main.go")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GO111MODULE=off", "GOFLAGS=", "GOPATH="+dir, "CGO_ENABLED=0")
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	cfg := filepath.Join(dir, "config.hcl")
+	require.NoError(t, ioutil.WriteFile(cfg, []byte(`
+allow = ["MIT"]
+override = {
+  "github.com/stretchr/testify" = "MIT"
+}
+`), 0644))
+
+	// The test binary is a module binary, which is still analyzed
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	code, output := runMain(t, "golicense", "-offline", "-plain", "-no-config", cfg, gopath, exe)
+	require.Regexp(t, `github.com/stretchr/testify +MIT`, output)
+	require.NotEqual(t, ExitCodeUsage, code, output)
+
+	// A binary that can't be read is still an error
+	invalid := filepath.Join(dir, "not-a-binary")
+	require.NoError(t, ioutil.WriteFile(invalid, []byte("hello"), 0644))
+	code, _ = runMain(t, "golicense", "-offline", "-plain", "-no-config", cfg, gopath, invalid, exe)
+	require.Equal(t, ExitCodeUsage, code)
+
+	// Only the GOPATH binary is an error, there is nothing to check
+	code, _ = runMain(t, "golicense", "-offline", "-plain", "-no-config", cfg, gopath)
+	require.Equal(t, ExitCodeUsage, code)
+
+	// Without any problem the run passes, unless -strict is set since
+	// the skipped binary wasn't checked
+	empty := filepath.Join(dir, "empty.hcl")
+	require.NoError(t, ioutil.WriteFile(empty, nil, 0644))
+	code, output = runMain(t, "golicense", "-offline", "-plain", "-exclude=*/*", "-config", empty, gopath, exe)
+	require.Equal(t, 0, code, output)
+	code, _ = runMain(t, "golicense", "-offline", "-plain", "-exclude=*/*", "-strict", "-config", empty, gopath, exe)
+	require.Equal(t, ExitCodeUnknown, code)
+}
+
+func TestRealMain_includeStd(t *testing.T) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	_, out := runMain(t, "golicense", "-offline", "-plain", "-no-config", exe)
+	require.NotRegexp(t, `(?m)^std `, out)
+
+	_, out = runMain(t, "golicense", "-offline", "-plain", "-no-config", "-include-std", exe)
+	require.Regexp(t, `(?m)^std +BSD 3-Clause "New" or "Revised" License$`, out)
+}
+
+func TestRealMain_reportDir(t *testing.T) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	// The directory is created, and an explicit path wins over it
+	dir := filepath.Join(t.TempDir(), "reports", "latest")
+	html := filepath.Join(t.TempDir(), "custom.html")
+	code, out := runMain(t, "golicense", "-offline", "-plain", "-no-config", "-exclude=*/*",
+		"-report-dir", dir, "-formats", "json, xlsx,html", "-out-html", html, exe)
+	require.Equal(t, 0, code, out)
+
+	for _, fn := range []string{filepath.Join(dir, "report.json"), filepath.Join(dir, "report.xlsx"), html} {
+		fi, err := os.Stat(fn)
+		require.NoError(t, err)
+		require.NotZero(t, fi.Size(), fn)
+	}
+	entries, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// A report that can't be written doesn't stop the others
+	dir = t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "report.json"), 0755))
+	code, _ = runMain(t, "golicense", "-offline", "-plain", "-no-config", "-exclude=*/*",
+		"-report-dir", dir, "-formats", "json,html,markdown", exe)
+	require.Equal(t, ExitCodeUsage, code)
+	require.FileExists(t, filepath.Join(dir, "report.html"))
+	require.FileExists(t, filepath.Join(dir, "report.md"))
+
+	// Unknown formats and a missing -formats are errors
+	code, _ = runMain(t, "golicense", "-offline", "-plain", "-no-config",
+		"-report-dir", dir, "-formats", "json,pdf", exe)
+	require.Equal(t, ExitCodeUsage, code)
+	code, _ = runMain(t, "golicense", "-offline", "-plain", "-no-config", "-report-dir", dir, exe)
+	require.Equal(t, ExitCodeUsage, code)
+}
+
+func TestRealMain_exitCodes(t *testing.T) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	// Invalid flags are a usage error, not a failed lookup
+	code, _ := runMain(t, "golicense", "-offline", "-plain", "-no-config", "-parallel=0", exe)
+	require.Equal(t, ExitCodeUsage, code)
+	code, _ = runMain(t, "golicense", "-offline", "-plain", "-no-config", "-nope", exe)
+	require.Equal(t, ExitCodeUsage, code)
+
+	// Offline, most modules have no license, which is denied unless
+	// -fail-unknown tells it apart
+	code, out := runMain(t, "golicense", "-offline", "-plain", "-no-config", exe)
+	require.Equal(t, ExitCodePolicy, code, out)
+	code, out = runMain(t, "golicense", "-offline", "-plain", "-no-config", "-fail-unknown", exe)
+	require.Equal(t, ExitCodeUnknown, code, out)
+
+	// A cached hash that doesn't match the binary is an integrity failure
+	bi, ok := debug.ReadBuildInfo()
+	require.True(t, ok)
+	var version string
+	for _, dep := range bi.Deps {
+		if dep.Path == "github.com/stretchr/testify" && dep.Sum != "" {
+			version = dep.Version
+		}
+	}
+	if version == "" {
+		t.Skip("the test binary has no hash for testify")
+	}
+
+	cache := filepath.Join(t.TempDir(), "cache.json")
+	require.NoError(t, ioutil.WriteFile(cache, []byte(fmt.Sprintf(`{
+  "version": 1,
+  "Modules": [{"path": "github.com/stretchr/testify", "verlic": [
+    {"version": %q, "license": "MIT License", "spdx": "MIT", "hash": "h1:other="}
+  ]}]
+}`, version)), 0644))
+	code, out = runMain(t, "golicense", "-offline", "-plain", "-no-config", "-cache", cache, exe)
+	require.Equal(t, ExitCodeSumMismatch, code, out)
+}
+
+func TestFinders_registry(t *testing.T) {
+	// -list-finders lists every finder of the registry, in its order
+	var names []string
+	for _, c := range finders {
+		names = append(names, c.Name)
+	}
+	require.Equal(t, analysis.FinderRegistry(context.Background(), nil, analysis.FinderOptions{}).Names(), names)
+}
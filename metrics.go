@@ -0,0 +1,26 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/mitchellh/golicense/license"
+)
+
+// writeMetrics writes the metrics summary as JSON to the given path, or to
+// stdout if the path is "-".
+func writeMetrics(path string, s license.MetricsSummary) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if path == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/golicense/analysis"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_cache(t *testing.T) {
+	var c Cache
+	mit := &license.License{Name: "MIT License", SPDX: "MIT"}
+	c.Store(module.Module{Path: "github.com/foo/cached", Version: "v1.0.0", Hash: "h1:cached="}, mit)
+	c.Store(module.Module{Path: "github.com/foo/stale", Version: "v1.0.0", Hash: "h1:old="}, mit)
+
+	var f license.MockFinder
+	f.On("License", mock.Anything, mock.Anything).Return(mit, nil)
+
+	mods := []module.Module{
+		// Hit
+		{Path: "github.com/foo/cached", Version: "v1.0.0", Hash: "h1:cached="},
+
+		// Misses, including a version that isn't cached
+		{Path: "github.com/foo/new", Version: "v1.0.0"},
+		{Path: "github.com/foo/cached", Version: "v2.0.0"},
+
+		// Neither, the hash doesn't match
+		{Path: "github.com/foo/stale", Version: "v1.0.0", Hash: "h1:new="},
+	}
+
+	var metrics license.Metrics
+	ctx := license.MetricsWithContext(context.Background(), &metrics)
+	analysis.Resolve(ctx, mods, analysis.Options{
+		Finders:     []license.Finder{&f},
+		Translators: []license.Translator{},
+		Cache:       &c,
+	})
+
+	s := metrics.Summary()
+	require.Equal(t, int64(1), s.CacheHits)
+	require.Equal(t, int64(2), s.CacheMisses)
+	require.Len(t, s.Finders, 1)
+	require.Equal(t, "license.MockFinder", s.Finders[0].Name)
+	require.Equal(t, int64(2), s.Finders[0].Lookups)
+	require.Equal(t, int64(2), s.Finders[0].Found)
+}
+
+func TestWriteMetrics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.json")
+	require.NoError(t, writeMetrics(path, license.MetricsSummary{
+		Requests:  3,
+		CacheHits: 1,
+		Finders:   []license.FinderMetrics{{Name: "github", Lookups: 2, Requests: 3}},
+	}))
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	var s license.MetricsSummary
+	require.NoError(t, json.Unmarshal(data, &s))
+	require.Equal(t, int64(3), s.Requests)
+	require.Equal(t, "github", s.Finders[0].Name)
+}
@@ -0,0 +1,109 @@
+package module
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseGoMod parses the requirements from the contents of a go.mod file
+// and sets their hashes from the contents of the matching go.sum file.
+// gosum may be empty, in which case no hashes are set.
+//
+// Replacements are applied the same way the Go tool does, so the result
+// matches what ParseExeData returns for a binary built from the module.
+// Modules replaced by a local directory have no version or hash.
+func ParseGoMod(gomod, gosum string) ([]Module, error) {
+	type replacement struct {
+		Path    string
+		Version string
+	}
+
+	var result []Module
+	replace := map[string]replacement{}
+	inBlock := ""
+	for i, line := range strings.Split(gomod, "\n") {
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		// Determine the directive, either from the line itself or the
+		// block that we're in.
+		directive := inBlock
+		switch {
+		case inBlock != "" && fields[0] == ")":
+			inBlock = ""
+			continue
+
+		case inBlock == "" && len(fields) == 2 && fields[1] == "(":
+			inBlock = fields[0]
+			continue
+
+		case inBlock == "":
+			directive = fields[0]
+			fields = fields[1:]
+		}
+
+		for j, f := range fields {
+			fields[j] = strings.Trim(f, `"`)
+		}
+
+		switch directive {
+		case "require":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("go.mod:%d: unexpected require: %s", i+1, line)
+			}
+
+			result = append(result, Module{Path: fields[0], Version: fields[1]})
+
+		case "replace":
+			// Either "old => new v", "old v => new v" or "old => ./dir"
+			arrow := -1
+			for j, f := range fields {
+				if f == "=>" {
+					arrow = j
+				}
+			}
+			if arrow < 1 || arrow > 2 || len(fields)-arrow < 2 || len(fields)-arrow > 3 {
+				return nil, fmt.Errorf("go.mod:%d: unexpected replace: %s", i+1, line)
+			}
+
+			var r replacement
+			r.Path = fields[arrow+1]
+			if len(fields)-arrow == 3 {
+				r.Version = fields[arrow+2]
+			}
+
+			key := fields[0]
+			if arrow == 2 {
+				key += "@" + fields[1]
+			}
+			replace[key] = r
+		}
+	}
+
+	hashes := ParseGoSum(gosum)
+	for i, m := range result {
+		r, ok := replace[m.Path+"@"+m.Version]
+		if !ok {
+			r, ok = replace[m.Path]
+		}
+		if ok {
+			m = Module{Path: r.Path, Version: r.Version}
+		}
+
+		// Strip the import version like ParseExeData does
+		if loc := importVersionRe.FindStringIndex(m.Path); loc != nil {
+			m.Path = m.Path[:loc[0]]
+		}
+
+		m.Hash = hashes[m.SumKey()]
+
+		result[i] = m
+	}
+
+	return result, nil
+}
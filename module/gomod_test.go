@@ -0,0 +1,130 @@
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGoMod(t *testing.T) {
+	cases := []struct {
+		Name     string
+		GoMod    string
+		GoSum    string
+		Expected []Module
+		Error    string
+	}{
+		{
+			"require block and single line",
+			`
+module example.com/foo
+
+go 1.18
+
+require github.com/fatih/color v1.7.0
+
+require (
+	github.com/mattn/go-isatty v0.0.4 // indirect
+	github.com/rsc/goversion/v12 v12.0.0
+)
+`,
+			`
+github.com/fatih/color v1.7.0 h1:DkWD4oS2D8LGGgTQ6IvwJJXSL5Vp2ffcQg58nFV38Ys=
+github.com/fatih/color v1.7.0/go.mod h1:Zm6kSWBoL9eyXnKyktHP6abPY2pDugNf5KwzbycvMj4=
+github.com/mattn/go-isatty v0.0.4 h1:bnP0vzxcAdeI1zdubAl5PjU6zsERjGZb7raWodagDYs=
+`,
+			[]Module{
+				Module{
+					Path:    "github.com/fatih/color",
+					Version: "v1.7.0",
+					Hash:    "h1:DkWD4oS2D8LGGgTQ6IvwJJXSL5Vp2ffcQg58nFV38Ys=",
+				},
+				Module{
+					Path:    "github.com/mattn/go-isatty",
+					Version: "v0.0.4",
+					Hash:    "h1:bnP0vzxcAdeI1zdubAl5PjU6zsERjGZb7raWodagDYs=",
+				},
+				Module{
+					Path:    "github.com/rsc/goversion",
+					Version: "v12.0.0",
+				},
+			},
+			"",
+		},
+
+		{
+			"replacements",
+			`
+module example.com/foo
+
+require (
+	github.com/markbates/inflect v1.0.0
+	github.com/foo/bar v0.1.0
+	github.com/foo/local v0.2.0
+)
+
+replace github.com/markbates/inflect => github.com/markbates/inflect v0.0.0-20171215194931-a12c3aec81a6
+
+replace (
+	github.com/foo/bar v0.1.0 => github.com/fork/bar v0.1.1
+	github.com/foo/local => ../local
+)
+`,
+			`
+github.com/markbates/inflect v0.0.0-20171215194931-a12c3aec81a6 h1:LZhVjIISSbj8qLf2qDPP0D8z0uvOWAW5C85ly5mJW6c=
+github.com/fork/bar v0.1.1 h1:fork=
+`,
+			[]Module{
+				Module{
+					Path:    "github.com/markbates/inflect",
+					Version: "v0.0.0-20171215194931-a12c3aec81a6",
+					Hash:    "h1:LZhVjIISSbj8qLf2qDPP0D8z0uvOWAW5C85ly5mJW6c=",
+				},
+				Module{
+					Path:    "github.com/fork/bar",
+					Version: "v0.1.1",
+					Hash:    "h1:fork=",
+				},
+				Module{
+					Path: "../local",
+				},
+			},
+			"",
+		},
+
+		{
+			"no go.sum",
+			"require github.com/fatih/color v1.7.0\n",
+			"",
+			[]Module{
+				Module{
+					Path:    "github.com/fatih/color",
+					Version: "v1.7.0",
+				},
+			},
+			"",
+		},
+
+		{
+			"invalid require",
+			"require github.com/fatih/color\n",
+			"",
+			nil,
+			"unexpected require",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require := require.New(t)
+			actual, err := ParseGoMod(tt.GoMod, tt.GoSum)
+			if tt.Error != "" {
+				require.Error(err)
+				require.Contains(err.Error(), tt.Error)
+				return
+			}
+			require.NoError(err)
+			require.Equal(tt.Expected, actual)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package module
+
+import "strings"
+
+// ParseGoSum parses the module hashes from the contents of a go.sum file,
+// keyed by SumKey. The hashes of go.mod files are ignored.
+func ParseGoSum(gosum string) map[string]string {
+	result := map[string]string{}
+	for _, line := range strings.Split(gosum, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+
+		m := Module{Path: fields[0], Version: fields[1]}
+
+		// Strip the import version like ParseExeData does
+		if loc := importVersionRe.FindStringIndex(m.Path); loc != nil {
+			m.Path = m.Path[:loc[0]]
+		}
+
+		result[m.SumKey()] = fields[2]
+	}
+
+	return result
+}
+
+// SumKey returns the key of the module's hash in the result of ParseGoSum.
+func (m *Module) SumKey() string {
+	return m.Path + "@" + m.Version
+}
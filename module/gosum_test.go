@@ -0,0 +1,21 @@
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGoSum(t *testing.T) {
+	actual := ParseGoSum(`
+github.com/fatih/color v1.7.0 h1:DkWD4oS2D8LGGgTQ6IvwJJXSL5Vp2ffcQg58nFV38Ys=
+github.com/fatih/color v1.7.0/go.mod h1:Zm6kSWBoL9eyXnKyktHP6abPY2pDugNf5KwzbycvMj4=
+github.com/rsc/goversion/v12 v12.0.0 h1:zVF4y5ciA/rw779S62bEAq4Yif1cBc/UwRkXJ2xZyT4=
+invalid line
+`)
+
+	require.Equal(t, map[string]string{
+		"github.com/fatih/color@v1.7.0":    "h1:DkWD4oS2D8LGGgTQ6IvwJJXSL5Vp2ffcQg58nFV38Ys=",
+		"github.com/rsc/goversion@v12.0.0": "h1:zVF4y5ciA/rw779S62bEAq4Yif1cBc/UwRkXJ2xZyT4=",
+	}, actual)
+}
@@ -2,8 +2,11 @@ package module
 
 import (
 	"fmt"
+	"path"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 )
 
 // Module represents a single Go module.
@@ -15,6 +18,132 @@ type Module struct {
 	Path    string // Import path, such as "github.com/mitchellh/golicense"
 	Version string // Version like "v1.2.3"
 	Hash    string // Hash such as "h1:abcd1234"
+
+	// Latest is a newer version of the module that is available, if known.
+	// This is only populated if checking for updates was requested.
+	Latest string
+
+	// Private is true for an internal module that matches GOPRIVATE or a
+	// configured pattern. It can't be found publicly, so it is never
+	// looked up over the network.
+	Private bool
+
+	// Main is true for the main module of a binary, or another module of
+	// its workspace, which is the code being checked rather than one of
+	// its dependencies. These have the version "(devel)".
+	Main bool
+
+	// Time is when the version was published, if known. This is only
+	// populated if the age of modules was checked.
+	Time time.Time
+
+	// Stale is true if the version was published longer ago than the
+	// maximum age that was checked.
+	Stale bool
+}
+
+// DevelVersion is the version of the main module, and of the other modules
+// of a workspace, in the build information of a binary.
+const DevelVersion = "(devel)"
+
+// IsLocal returns true if the module is replaced by a local directory, in
+// which case Path is the directory and there is no version. Directories
+// are recognized the same way the Go tool does: relative paths must start
+// with "./" or "../".
+func (m *Module) IsLocal() bool {
+	p := m.Path
+	return p == "." || p == ".." ||
+		strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../") ||
+		strings.HasPrefix(p, `.\`) || strings.HasPrefix(p, `..\`) ||
+		filepath.IsAbs(p)
+}
+
+// MatchPrefixPatterns returns true if the path, or any of its leading
+// path elements, matches any of the glob patterns. The patterns use the
+// syntax of GOPRIVATE: a comma separated list of path.Match patterns, so
+// "git.corp.example.com" matches every module on that host.
+func MatchPrefixPatterns(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		for _, glob := range strings.Split(pattern, ",") {
+			glob = strings.TrimSpace(glob)
+			if glob == "" {
+				continue
+			}
+
+			// Match as many leading path elements as the pattern has
+			n := strings.Count(glob, "/")
+			prefix := p
+			for i := 0; i < len(p); i++ {
+				if p[i] == '/' {
+					if n == 0 {
+						prefix = p[:i]
+						break
+					}
+					n--
+				}
+			}
+			if n > 0 {
+				continue
+			}
+
+			if ok, _ := path.Match(glob, prefix); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// PseudoCommit returns the commit hash prefix of a pseudo-version, such as
+// "4b39c73a6495" for "v0.0.0-20190312203227-4b39c73a6495", or an empty
+// string if the version isn't a pseudo-version. A pseudo-version refers to
+// a commit that has no release tag.
+func (m *Module) PseudoCommit() string {
+	match := pseudoVersionRe.FindStringSubmatch(m.Version)
+	if match == nil {
+		return ""
+	}
+
+	return match[2]
+}
+
+// PseudoTime returns the commit time of a pseudo-version in UTC, such as
+// 2019-03-12 20:32:27 for "v0.0.0-20190312203227-4b39c73a6495". It returns
+// false if the version isn't a pseudo-version.
+func (m *Module) PseudoTime() (time.Time, bool) {
+	match := pseudoVersionRe.FindStringSubmatch(m.Version)
+	if match == nil {
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse("20060102150405", match[1])
+	return t, err == nil
+}
+
+// MajorPath returns the module path with its major version suffix, such as
+// "github.com/foo/bar/v2" for version "v2.3.0". Path has the suffix
+// removed, but the module proxy and pkg.go.dev need it for v2+ modules.
+// Versions marked "+incompatible" have no suffix, and gopkg.in paths keep
+// the major version in the path instead.
+func (m *Module) MajorPath() string {
+	match := majorRe.FindStringSubmatch(m.Version)
+	if match == nil || strings.HasPrefix(m.Path, "gopkg.in/") || importVersionRe.MatchString(m.Path) {
+		return m.Path
+	}
+
+	return m.Path + "/" + match[1]
+}
+
+// Paths returns the module paths to try for the module, most likely first:
+// MajorPath and then Path if it is different, in case the module doesn't
+// follow the major version suffix convention.
+func (m *Module) Paths() []string {
+	if p := m.MajorPath(); p != m.Path {
+		return []string{p, m.Path}
+	}
+
+	return []string{m.Path}
 }
 
 // String returns a human readable string format.
@@ -24,10 +153,17 @@ func (m *Module) String() string {
 
 // ParseExeData parses the raw dependency information from a compiled Go
 // binary's readonly data section. Any unexpected values will return errors.
+//
+// The main module itself isn't returned, but a dependency that is the main
+// module or another module of its workspace is returned with Main set.
 func ParseExeData(raw string) ([]Module, error) {
 	var result []Module
+	var mainPath string
 	for _, line := range strings.Split(strings.TrimSpace(raw), "\n") {
 		row := strings.Split(line, "\t")
+		if row[0] == "mod" && len(row) > 1 {
+			mainPath = row[1]
+		}
 
 		// Ignore non-dependency information, such as path/mod. The
 		// "=>" syntax means it is a replacement.
@@ -46,6 +182,12 @@ func ParseExeData(raw string) ([]Module, error) {
 				"Unexpected raw dependency format: %s", line)
 		}
 
+		// The main module is a dependency with its own path, including
+		// the major version suffix, and other modules of its workspace
+		// have no version. A replacement with a local directory has no
+		// version either, but it is still a dependency.
+		main := row[1] == mainPath || (row[0] == "dep" && row[2] == DevelVersion)
+
 		// If the path ends in an import version, strip it since we have
 		// an exact version available in Version.
 		if loc := importVersionRe.FindStringIndex(row[1]); loc != nil {
@@ -56,6 +198,7 @@ func ParseExeData(raw string) ([]Module, error) {
 			Path:    row[1],
 			Version: row[2],
 			Hash:    row[3],
+			Main:    main,
 		}
 
 		// If this is a replacement, then replace the last result
@@ -74,3 +217,14 @@ func ParseExeData(raw string) ([]Module, error) {
 // import version specifiers like `/v12` on an import that is Go modules
 // compatible.
 var importVersionRe = regexp.MustCompile(`/v\d+$`)
+
+// majorRe matches the major version of a v2+ module version that isn't
+// "+incompatible", since those don't have a major version suffix.
+var majorRe = regexp.MustCompile(`^(v[2-9]|v[1-9][0-9]+)\.[^+]*$`)
+
+// pseudoVersionRe matches a pseudo-version in any of its forms, such as
+// "v0.0.0-20190312203227-4b39c73a6495" or
+// "v1.2.4-0.20190312203227-4b39c73a6495+incompatible", and captures the
+// commit time and hash prefix.
+var pseudoVersionRe = regexp.MustCompile(
+	`^v[0-9]+\.(?:0\.0-|[0-9]+\.[0-9]+-(?:[^+]*\.)?0\.)([0-9]{14})-([0-9a-f]{12})(?:\+incompatible)?$`)
@@ -3,6 +3,7 @@ package module
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -59,6 +60,39 @@ func TestParseExeData(t *testing.T) {
 			},
 			"",
 		},
+
+		{
+			"main module",
+			strings.TrimSpace(mainModule),
+			[]Module{
+				Module{Path: "example.com/app", Version: "(devel)", Main: true},
+				Module{Path: "example.com/lib", Version: "(devel)", Main: true},
+				Module{
+					Path:    "github.com/foo/bar",
+					Version: "v0.0.0-20190312203227-4b39c73a6495",
+					Hash:    "h1:bar=",
+				},
+			},
+			"",
+		},
+
+		{
+			"local replacement",
+			strings.TrimSpace(localReplacement),
+			[]Module{
+				Module{Path: "../fork", Version: "(devel)"},
+			},
+			"",
+		},
+
+		{
+			"other major version of the main module",
+			strings.TrimSpace(otherMajor),
+			[]Module{
+				Module{Path: "github.com/foo/bar", Version: "v2.1.0", Hash: "h1:bar="},
+			},
+			"",
+		},
 	}
 
 	for _, tt := range cases {
@@ -84,3 +118,140 @@ mod	github.com/gohugoio/hugo	(devel)
 dep	github.com/markbates/inflect	v1.0.0
 =>	github.com/markbates/inflect	v0.0.0-20171215194931-a12c3aec81a6	h1:LZhVjIISSbj8qLf2qDPP0D8z0uvOWAW5C85ly5mJW6c=
 `
+
+// mainModule is a binary built in a workspace with example.com/lib, which
+// also depends on the main module.
+const mainModule = `
+path	example.com/app/cmd/app
+mod	example.com/app/v2	(devel)
+dep	example.com/app/v2	(devel)
+dep	example.com/lib	(devel)
+dep	github.com/foo/bar	v0.0.0-20190312203227-4b39c73a6495	h1:bar=
+`
+
+// localReplacement is a binary with a dependency replaced by a directory.
+const localReplacement = `
+path	example.com/app
+mod	example.com/app	(devel)
+dep	github.com/foo/bar	v1.0.0	h1:bar=
+=>	../fork	(devel)
+`
+
+// otherMajor is a binary that depends on an older major version of its
+// own module.
+const otherMajor = `
+path	github.com/foo/bar/v3/cmd/bar
+mod	github.com/foo/bar/v3	(devel)
+dep	github.com/foo/bar/v2	v2.1.0	h1:bar=
+`
+
+func TestModulePseudoCommit(t *testing.T) {
+	cases := []struct {
+		Version string
+		Commit  string
+	}{
+		{"v0.0.0-20190312203227-4b39c73a6495", "4b39c73a6495"},
+		{"v1.2.4-0.20190312203227-4b39c73a6495", "4b39c73a6495"},
+		{"v1.2.3-rc.1.0.20190312203227-4b39c73a6495", "4b39c73a6495"},
+		{"v2.0.1-0.20190312203227-4b39c73a6495+incompatible", "4b39c73a6495"},
+		{"v1.2.3", ""},
+		{"v1.2.3-rc.1", ""},
+		{"v1.2.3-20190312203227-4b39c73a6495", ""},
+		{"(devel)", ""},
+		{"", ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Version, func(t *testing.T) {
+			m := &Module{Path: "github.com/foo/bar", Version: tt.Version}
+			require.Equal(t, tt.Commit, m.PseudoCommit())
+		})
+	}
+}
+
+func TestModuleMajorPath(t *testing.T) {
+	cases := []struct {
+		Path    string
+		Version string
+		Result  string
+	}{
+		{"github.com/foo/bar", "v1.2.3", "github.com/foo/bar"},
+		{"github.com/foo/bar", "v2.3.0", "github.com/foo/bar/v2"},
+		{"github.com/foo/bar", "v12.0.0", "github.com/foo/bar/v12"},
+		{"github.com/foo/bar", "v2.0.1-0.20190312203227-4b39c73a6495", "github.com/foo/bar/v2"},
+		{"github.com/foo/bar", "v2.3.0+incompatible", "github.com/foo/bar"},
+		{"github.com/foo/bar/v2", "v2.3.0", "github.com/foo/bar/v2"},
+		{"gopkg.in/yaml.v2", "v2.4.0", "gopkg.in/yaml.v2"},
+		{"github.com/foo/bar", "(devel)", "github.com/foo/bar"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Path+"@"+tt.Version, func(t *testing.T) {
+			m := &Module{Path: tt.Path, Version: tt.Version}
+			require.Equal(t, tt.Result, m.MajorPath())
+		})
+	}
+
+	// Paths falls back to the path without the suffix
+	m := &Module{Path: "github.com/foo/bar", Version: "v2.3.0"}
+	require.Equal(t, []string{"github.com/foo/bar/v2", "github.com/foo/bar"}, m.Paths())
+	m.Version = "v1.2.3"
+	require.Equal(t, []string{"github.com/foo/bar"}, m.Paths())
+}
+
+func TestModulePseudoTime(t *testing.T) {
+	m := &Module{Path: "github.com/foo/bar", Version: "v1.2.4-0.20190312203227-4b39c73a6495"}
+	tm, ok := m.PseudoTime()
+	require.True(t, ok)
+	require.Equal(t, time.Date(2019, 3, 12, 20, 32, 27, 0, time.UTC), tm)
+
+	m.Version = "v1.2.3"
+	_, ok = m.PseudoTime()
+	require.False(t, ok)
+}
+
+func TestModuleIsLocal(t *testing.T) {
+	cases := []struct {
+		Path  string
+		Local bool
+	}{
+		{"github.com/foo/bar", false},
+		{"example.com/foo", false},
+		{"./local", true},
+		{"../local", true},
+		{"..", true},
+		{"/abs/local", true},
+		{"local", false},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Path, func(t *testing.T) {
+			m := &Module{Path: tt.Path}
+			require.Equal(t, tt.Local, m.IsLocal())
+		})
+	}
+}
+
+func TestMatchPrefixPatterns(t *testing.T) {
+	cases := []struct {
+		Patterns []string
+		Path     string
+		Match    bool
+	}{
+		{[]string{"git.corp.example.com"}, "git.corp.example.com/team/lib", true},
+		{[]string{"git.corp.example.com/*"}, "git.corp.example.com/team/lib", true},
+		{[]string{"git.corp.example.com/*"}, "git.corp.example.com", false},
+		{[]string{"*.corp.example.com"}, "git.corp.example.com/team/lib", true},
+		{[]string{"github.com/foo,github.com/bar"}, "github.com/bar/baz", true},
+		{[]string{"github.com/foo", " github.com/bar "}, "github.com/bar/baz", true},
+		{[]string{"github.com/foo"}, "github.com/foobar/baz", false},
+		{[]string{"", ","}, "github.com/foo/bar", false},
+		{nil, "github.com/foo/bar", false},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Path, func(t *testing.T) {
+			require.Equal(t, tt.Match, MatchPrefixPatterns(tt.Patterns, tt.Path), tt.Patterns)
+		})
+	}
+}
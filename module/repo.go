@@ -0,0 +1,65 @@
+package module
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Repo is a repository on a code host, such as github.com/foo/bar.
+type Repo struct {
+	Host  string // such as "github.com"
+	Owner string // may be "org/project" for Azure DevOps
+	Name  string
+}
+
+// String returns the path of the repository, such as "github.com/foo/bar".
+func (r Repo) String() string {
+	if strings.Contains(r.Owner, "/") {
+		return r.Host + "/" + r.Owner + "/_git/" + r.Name
+	}
+
+	return r.Host + "/" + r.Owner + "/" + r.Name
+}
+
+// ParseRepo returns the repository that a module or package path is hosted
+// in, for the finders that look up licenses on a code host. Subpaths and
+// major version suffixes are removed, so both "github.com/foo/bar/v3" and
+// "github.com/foo/bar/subpkg" are in github.com/foo/bar. A gopkg.in path is
+// in the GitHub repository that gopkg.in redirects to, such as
+// github.com/go-yaml/yaml for "gopkg.in/yaml.v2". An Azure DevOps path
+// such as "dev.azure.com/org/project/_git/repo" has the owner
+// "org/project". This returns false if the path has no host, owner and
+// repository name.
+func ParseRepo(p string) (Repo, bool) {
+	if ms := gopkgRe.FindStringSubmatch(p); ms != nil {
+		// Without a user, the repository is go-<pkg>/<pkg>
+		owner := ms[1]
+		if owner == "" {
+			owner = "go-" + ms[2]
+		}
+
+		return Repo{Host: "github.com", Owner: owner, Name: ms[2]}, true
+	}
+
+	parts := strings.Split(p, "/")
+	if len(parts) < 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return Repo{}, false
+	}
+
+	// Azure DevOps repositories are in a project of an organization, such
+	// as dev.azure.com/org/project/_git/repo
+	if len(parts) >= 5 && parts[3] == "_git" && parts[4] != "" {
+		return Repo{
+			Host:  parts[0],
+			Owner: parts[1] + "/" + parts[2],
+			Name:  strings.TrimSuffix(parts[4], ".git"),
+		}, true
+	}
+
+	return Repo{Host: parts[0], Owner: parts[1], Name: strings.TrimSuffix(parts[2], ".git")}, true
+}
+
+// gopkgRe is the regexp matching the package for a GoPkg import. This is
+// taken almost directly from the GoPkg source code itself so it should
+// match perfectly.
+var gopkgRe = regexp.MustCompile(`(?i)^gopkg\.in/(?:([a-zA-Z0-9][-a-zA-Z0-9]+)/)?([a-zA-Z][-.a-zA-Z0-9]*)\.((?:v0|v[1-9][0-9]*)(?:\.0|\.[1-9][0-9]*){0,2}(?:-unstable)?)(?:\.git)?((?:/[a-zA-Z0-9][-.a-zA-Z0-9]*)*)$`)
@@ -0,0 +1,41 @@
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRepo(t *testing.T) {
+	cases := []struct {
+		Path string
+		Repo string
+	}{
+		{"github.com/foo/bar", "github.com/foo/bar"},
+		{"github.com/foo/bar/v3", "github.com/foo/bar"},
+		{"github.com/foo/bar/subpkg", "github.com/foo/bar"},
+		{"github.com/foo/bar/sdk/v2/subpkg", "github.com/foo/bar"},
+		{"bitbucket.org/foo/bar.git", "bitbucket.org/foo/bar"},
+		{"dev.azure.com/org/project/_git/repo.git/subpkg", "dev.azure.com/org/project/_git/repo"},
+		{"gopkg.in/yaml.v2", "github.com/go-yaml/yaml"},
+		{"gopkg.in/mitchellh/foo.v22", "github.com/mitchellh/foo"},
+		{"gopkg.in/check.v1/subpkg", "github.com/go-check/check"},
+		{"gopkg.in/yaml", ""},
+		{"github.com/foo", ""},
+		{"github.com//bar", ""},
+		{"std", ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Path, func(t *testing.T) {
+			r, ok := ParseRepo(tt.Path)
+			if tt.Repo == "" {
+				require.False(t, ok, r.String())
+				return
+			}
+
+			require.True(t, ok)
+			require.Equal(t, tt.Repo, r.String())
+		})
+	}
+}
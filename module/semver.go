@@ -0,0 +1,187 @@
+package module
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares two semantic versions such as "v1.2.3" by their
+// precedence. The result is 0 if a == b, -1 if a < b and +1 if a > b. ok is
+// false if either version can't be parsed.
+func CompareVersions(a, b string) (result int, ok bool) {
+	av, aok := parseSemver(a)
+	bv, bok := parseSemver(b)
+	if !aok || !bok {
+		return 0, false
+	}
+
+	return compareSemver(av, bv), true
+}
+
+// SplitVersion splits a key of the form "path@version" into the path and
+// the version, which is empty if the key has no "@". The version can also
+// be a range, see MatchVersion.
+func SplitVersion(key string) (path, version string) {
+	if idx := strings.IndexByte(key, '@'); idx >= 0 {
+		return key[:idx], key[idx+1:]
+	}
+
+	return key, ""
+}
+
+// MatchVersion returns true if the version satisfies the constraint, which
+// is either an exact version such as "v2.0.0" or a range of one or more
+// comparisons separated by spaces that must all hold, such as
+// ">=v2.0.0 <v3.0.0". The operators are =, !=, <, <=, > and >=. An empty
+// constraint matches every version.
+func MatchVersion(constraint, version string) bool {
+	if constraint == "" {
+		return true
+	}
+	if VersionSpecificity(constraint) == 2 {
+		return constraint == version
+	}
+
+	for _, cmp := range strings.Fields(constraint) {
+		idx := strings.IndexByte(cmp, 'v')
+		if idx < 0 {
+			return false
+		}
+		op := cmp[:idx]
+		c, ok := CompareVersions(version, cmp[idx:])
+		if !ok {
+			return false
+		}
+
+		var match bool
+		switch op {
+		case "=":
+			match = c == 0
+		case "!=":
+			match = c != 0
+		case "<":
+			match = c < 0
+		case "<=":
+			match = c <= 0
+		case ">":
+			match = c > 0
+		case ">=":
+			match = c >= 0
+		}
+		if !match {
+			return false
+		}
+	}
+
+	return true
+}
+
+// VersionSpecificity returns how specific the version constraint of a key
+// is, so that the most specific of several matching keys can be used: 2
+// for an exact version, 1 for a range and 0 for no version at all.
+func VersionSpecificity(constraint string) int {
+	switch {
+	case constraint == "":
+		return 0
+	case strings.ContainsAny(constraint, "<>=! "):
+		return 1
+	}
+
+	return 2
+}
+
+type semver struct {
+	nums [3]int
+	pre  []string
+}
+
+func parseSemver(v string) (semver, bool) {
+	var result semver
+	if !strings.HasPrefix(v, "v") {
+		return result, false
+	}
+	v = v[1:]
+
+	// Build metadata is ignored for precedence
+	if idx := strings.IndexByte(v, '+'); idx >= 0 {
+		v = v[:idx]
+	}
+	if idx := strings.IndexByte(v, '-'); idx >= 0 {
+		result.pre = strings.Split(v[idx+1:], ".")
+		v = v[:idx]
+	}
+
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return result, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return result, false
+		}
+		result.nums[i] = n
+	}
+
+	return result, true
+}
+
+func compareSemver(a, b semver) int {
+	for i := range a.nums {
+		if a.nums[i] != b.nums[i] {
+			if a.nums[i] > b.nums[i] {
+				return 1
+			}
+			return -1
+		}
+	}
+
+	// A version without a pre-release has higher precedence
+	switch {
+	case len(a.pre) == 0 && len(b.pre) == 0:
+		return 0
+	case len(a.pre) == 0:
+		return 1
+	case len(b.pre) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(a.pre) && i < len(b.pre); i++ {
+		if c := comparePre(a.pre[i], b.pre[i]); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case len(a.pre) > len(b.pre):
+		return 1
+	case len(a.pre) < len(b.pre):
+		return -1
+	}
+	return 0
+}
+
+// comparePre compares two pre-release identifiers. Numeric identifiers are
+// compared numerically and have lower precedence than alphanumeric ones.
+func comparePre(a, b string) int {
+	an, aerr := strconv.Atoi(a)
+	bn, berr := strconv.Atoi(b)
+	switch {
+	case aerr == nil && berr == nil:
+		if an == bn {
+			return 0
+		}
+		if an > bn {
+			return 1
+		}
+		return -1
+
+	case aerr == nil:
+		return -1
+
+	case berr == nil:
+		return 1
+	}
+
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,53 @@
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitVersion(t *testing.T) {
+	p, v := SplitVersion("github.com/foo/bar@>=v2.0.0")
+	require.Equal(t, "github.com/foo/bar", p)
+	require.Equal(t, ">=v2.0.0", v)
+
+	p, v = SplitVersion("github.com/foo/bar")
+	require.Equal(t, "github.com/foo/bar", p)
+	require.Equal(t, "", v)
+}
+
+func TestMatchVersion(t *testing.T) {
+	cases := []struct {
+		Constraint string
+		Version    string
+		Match      bool
+	}{
+		{"", "v1.0.0", true},
+		{"", "", true},
+		{"v1.0.0", "v1.0.0", true},
+		{"v1.0.0", "v1.0.1", false},
+		{"v2.0.0+incompatible", "v2.0.0+incompatible", true},
+		{"=v1.0.0", "v1.0.0", true},
+		{"!=v1.0.0", "v1.0.0", false},
+		{"!=v1.0.0", "v1.0.1", true},
+		{">v1.0.0", "v1.0.0", false},
+		{">=v1.0.0", "v1.0.0", true},
+		{"<v1.0.0", "v1.0.0-rc.1", true},
+		{"<=v1.0.0", "v1.0.1", false},
+		{">=v1.0.0 <v2.0.0", "v1.9.0", true},
+		{">=v1.0.0 <v2.0.0", "v2.0.0", false},
+		{"<v0.1.0", "v0.0.0-20190312203227-4b39c73a6495", true},
+
+		// Invalid constraints and versions never match
+		{"~v1.0.0", "v1.0.0", false},
+		{">=1.0.0", "v1.0.0", false},
+		{">=v1.0.0", "(devel)", false},
+		{">=v1.0.0", "", false},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Constraint+" "+tt.Version, func(t *testing.T) {
+			require.Equal(t, tt.Match, MatchVersion(tt.Constraint, tt.Version))
+		})
+	}
+}
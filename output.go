@@ -24,23 +24,13 @@ type Output interface {
 	Close() error
 }
 
-// StatusListener returns a license.StatusListener implementation for
-// a single module to route to an Output implementation.
-//
-// The caller must still call Start and Finish appropriately on the
-// Output while using this StatusListener.
-func StatusListener(o Output, m *module.Module) license.StatusListener {
-	return &outputStatusListener{m: m, o: o}
-}
-
-// outputStatusListener is a license.StatusListener implementation that
-// updates the Output for a single module by calling Update.
-type outputStatusListener struct {
-	m *module.Module
-	o Output
-}
-
-// UpdateStatus implements license.StatusListener
-func (sl *outputStatusListener) UpdateStatus(t license.StatusType, msg string) {
-	sl.o.Update(sl.m, t, msg)
+// ReportOutput is implemented by the outputs that write a report of all
+// modules once the lookups are complete. Rather than each collecting the
+// results in Finish, a ResultStore collects them and passes the same
+// sorted results to every report, so that they list the modules in the
+// same order.
+type ReportOutput interface {
+	// Flush writes the report with the results of all lookups, sorted by
+	// module path and version.
+	Flush([]Result) error
 }
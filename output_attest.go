@@ -0,0 +1,192 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/mitchellh/golicense/config"
+)
+
+const (
+	// attestPredicateType identifies the golicense report as the predicate
+	// of an in-toto statement.
+	attestPredicateType = "https://github.com/mitchellh/golicense/report/v1"
+
+	inTotoStatementType = "https://in-toto.io/Statement/v1"
+	inTotoPayloadType   = "application/vnd.in-toto+json"
+)
+
+// AttestOutput writes the results of license lookups as a JSON report that
+// can be tied to the analyzed binaries for supply-chain provenance.
+//
+// The report is written to Path, along with its SHA-256 checksum in
+// Path + ".sha256" in the format used by sha256sum. If KeyPath is set, an
+// in-toto statement with the binaries as subjects and the report as the
+// predicate is signed and written as a DSSE envelope to
+// Path + ".intoto.json".
+type AttestOutput struct {
+	// Path is the path to the report to write. This and the related files
+	// will be overwritten if they exist.
+	Path string
+
+	// KeyPath is the path to a PEM-encoded PKCS #8 Ed25519 private key used
+	// to sign the attestation. If empty, no attestation is written.
+	KeyPath string
+
+	// Binaries are the paths of the analyzed binaries. Their hashes are
+	// the subjects of the attestation.
+	Binaries []string
+
+	// Config is the configuration (if any). This will be used to check
+	// if a license is allowed or not.
+	Config *config.Config
+}
+
+type inTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+type inTotoStatement struct {
+	Type          string          `json:"_type"`
+	Subject       []inTotoSubject `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     interface{}     `json:"predicate"`
+}
+
+type dsseSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+type dsseEnvelope struct {
+	PayloadType string          `json:"payloadType"`
+	Payload     string          `json:"payload"`
+	Signatures  []dsseSignature `json:"signatures"`
+}
+
+// Flush implements ReportOutput. The results are sorted, so the report,
+// and therefore its checksum, is stable for the same results.
+func (o *AttestOutput) Flush(results []Result) error {
+	// The hashes are always included, since they are what is attested
+	report := reportModules(results, o.Config, true)
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(o.Path, data, 0644); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(data)
+	checksum := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), filepath.Base(o.Path))
+	if err := ioutil.WriteFile(o.Path+".sha256", []byte(checksum), 0644); err != nil {
+		return err
+	}
+
+	if o.KeyPath == "" {
+		return nil
+	}
+
+	key, err := readEd25519Key(o.KeyPath)
+	if err != nil {
+		return err
+	}
+
+	statement := inTotoStatement{
+		Type:          inTotoStatementType,
+		PredicateType: attestPredicateType,
+		Predicate:     map[string]interface{}{"modules": report},
+	}
+	for _, b := range o.Binaries {
+		digest, err := sha256File(b)
+		if err != nil {
+			return err
+		}
+
+		statement.Subject = append(statement.Subject, inTotoSubject{
+			Name:   filepath.Base(b),
+			Digest: map[string]string{"sha256": digest},
+		})
+	}
+
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return err
+	}
+
+	envelope := dsseEnvelope{
+		PayloadType: inTotoPayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures: []dsseSignature{{
+			Sig: base64.StdEncoding.EncodeToString(
+				ed25519.Sign(key, dssePAE(inTotoPayloadType, payload))),
+		}},
+	}
+
+	data, err = json.MarshalIndent(envelope, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(o.Path+".intoto.json", data, 0644)
+}
+
+// dssePAE is the DSSE pre-authentication encoding of the payload, which is
+// what is actually signed.
+func dssePAE(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s",
+		len(payloadType), payloadType, len(payload), payload))
+}
+
+// readEd25519Key reads a PEM-encoded PKCS #8 Ed25519 private key, such as
+// one generated by "openssl genpkey -algorithm ed25519".
+func readEd25519Key(path string) (ed25519.PrivateKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+
+	result, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: key must be an Ed25519 private key", path)
+	}
+
+	return result, nil
+}
+
+// sha256File returns the hex encoded SHA-256 hash of a file.
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
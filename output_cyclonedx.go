@@ -0,0 +1,193 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+// CycloneDXOutput writes the modules and their licenses as a CycloneDX 1.4
+// SBOM in JSON format.
+type CycloneDXOutput struct {
+	// Path is the path to the file to write. This will be overwritten if
+	// it exists.
+	Path string
+
+	// Config is the configuration (if any). The justification of modules
+	// with an exception is added as a component property.
+	Config *config.Config
+}
+
+type cdxBOM struct {
+	BOMFormat    string         `json:"bomFormat"`
+	SpecVersion  string         `json:"specVersion"`
+	SerialNumber string         `json:"serialNumber"`
+	Version      int            `json:"version"`
+	Metadata     cdxMetadata    `json:"metadata"`
+	Components   []cdxComponent `json:"components"`
+}
+
+type cdxMetadata struct {
+	Timestamp string    `json:"timestamp"`
+	Tools     []cdxTool `json:"tools"`
+}
+
+type cdxTool struct {
+	Name string `json:"name"`
+}
+
+type cdxComponent struct {
+	Type       string          `json:"type"`
+	BOMRef     string          `json:"bom-ref"`
+	Name       string          `json:"name"`
+	Version    string          `json:"version"`
+	PURL       string          `json:"purl"`
+	Hashes     []cdxHash       `json:"hashes,omitempty"`
+	Licenses   []cdxLicenseRef `json:"licenses,omitempty"`
+	Properties []cdxProperty   `json:"properties,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+// cdxLicenseRef is either a single license or an SPDX license expression.
+type cdxLicenseRef struct {
+	License    *cdxLicense `json:"license,omitempty"`
+	Expression string      `json:"expression,omitempty"`
+}
+
+type cdxLicense struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// component creates the component of a module with its license.
+func (o *CycloneDXOutput) component(m *module.Module, l *license.License) cdxComponent {
+	purl := modulePURL(m)
+	c := cdxComponent{
+		Type:    "library",
+		BOMRef:  purl,
+		Name:    m.Path,
+		Version: m.Version,
+		PURL:    purl,
+	}
+	if h := moduleSHA256(m); h != "" {
+		c.Hashes = []cdxHash{{Alg: "SHA-256", Content: h}}
+	}
+	if l != nil {
+		// A license must have either an SPDX ID or a name, never both.
+		// Dual or compound licenses can only be given as an expression.
+		if e, err := license.ParseExpression(l.SPDX); err == nil && e.Compound() {
+			c.Licenses = []cdxLicenseRef{{Expression: e.String()}}
+		} else if l.SPDX != "" {
+			c.Licenses = []cdxLicenseRef{{License: &cdxLicense{ID: l.SPDX}}}
+		} else if l.Name != "" {
+			c.Licenses = []cdxLicenseRef{{License: &cdxLicense{Name: l.Name}}}
+		}
+	}
+	if o.Config != nil && o.Config.Exceptions[m.Path] != "" {
+		c.Properties = []cdxProperty{{
+			Name:  "golicense:exception",
+			Value: o.Config.Exceptions[m.Path],
+		}}
+	}
+
+	return c
+}
+
+// Flush implements ReportOutput
+func (o *CycloneDXOutput) Flush(results []Result) error {
+	serial, err := uuidV4()
+	if err != nil {
+		return err
+	}
+
+	bom := cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.4",
+		SerialNumber: "urn:uuid:" + serial,
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Tools:     []cdxTool{{Name: "golicense"}},
+		},
+		Components: make([]cdxComponent, 0, len(results)),
+	}
+	for _, r := range results {
+		r := r
+		bom.Components = append(bom.Components, o.component(&r.Module, r.License))
+	}
+
+	data, err := json.MarshalIndent(bom, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(o.Path, data, 0644)
+}
+
+// modulePURL returns the package URL of the module, such as
+// "pkg:golang/github.com/foo/bar@v1.2.3".
+func modulePURL(m *module.Module) string {
+	segments := strings.Split(m.Path, "/")
+	for i, s := range segments {
+		segments[i] = purlEscape(s)
+	}
+
+	purl := "pkg:golang/" + strings.Join(segments, "/")
+	if m.Version != "" {
+		purl += "@" + purlEscape(m.Version)
+	}
+
+	return purl
+}
+
+// purlEscape percent-encodes a package URL component. "+" is encoded too
+// since it is commonly decoded as a space.
+func purlEscape(s string) string {
+	return strings.Replace(url.PathEscape(s), "+", "%2B", -1)
+}
+
+// moduleSHA256 returns the hex encoded SHA-256 hash from the "h1:" hash of
+// a module, or an empty string if the module has no such hash.
+func moduleSHA256(m *module.Module) string {
+	if !strings.HasPrefix(m.Hash, "h1:") {
+		return ""
+	}
+
+	sum, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(m.Hash, "h1:"))
+	if err != nil || len(sum) != 32 {
+		return ""
+	}
+
+	return hex.EncodeToString(sum)
+}
+
+// uuidV4 returns a random (version 4) UUID.
+func uuidV4() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCycloneDXOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bom.json")
+	out := &CycloneDXOutput{Path: path}
+
+	store := &ResultStore{Reports: []ReportOutput{out}}
+	store.Finish(&module.Module{
+		Path:    "github.com/foo/bar",
+		Version: "v1.2.3",
+		Hash:    "h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=",
+	}, &license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+	store.Finish(&module.Module{
+		Path:    "github.com/foo/custom",
+		Version: "v2.0.0+incompatible",
+	}, &license.License{Name: "Custom License"}, nil)
+	store.Finish(&module.Module{
+		Path:    "github.com/foo/dual",
+		Version: "v1.0.0",
+	}, &license.License{Name: "MIT OR Apache-2.0", SPDX: "MIT or Apache-2.0"}, nil)
+	store.Finish(&module.Module{
+		Path:    "github.com/foo/missing",
+		Version: "v0.1.0",
+	}, nil, nil)
+	require.NoError(t, store.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	var bom map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &bom))
+	validateCycloneDX14(t, bom)
+
+	var actual cdxBOM
+	require.NoError(t, json.Unmarshal(data, &actual))
+	require.Equal(t, []cdxComponent{
+		{
+			Type:    "library",
+			BOMRef:  "pkg:golang/github.com/foo/bar@v1.2.3",
+			Name:    "github.com/foo/bar",
+			Version: "v1.2.3",
+			PURL:    "pkg:golang/github.com/foo/bar@v1.2.3",
+			Hashes: []cdxHash{{
+				Alg:     "SHA-256",
+				Content: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			}},
+			Licenses: []cdxLicenseRef{{License: &cdxLicense{ID: "MIT"}}},
+		},
+		{
+			Type:     "library",
+			BOMRef:   "pkg:golang/github.com/foo/custom@v2.0.0%2Bincompatible",
+			Name:     "github.com/foo/custom",
+			Version:  "v2.0.0+incompatible",
+			PURL:     "pkg:golang/github.com/foo/custom@v2.0.0%2Bincompatible",
+			Licenses: []cdxLicenseRef{{License: &cdxLicense{Name: "Custom License"}}},
+		},
+		{
+			Type:     "library",
+			BOMRef:   "pkg:golang/github.com/foo/dual@v1.0.0",
+			Name:     "github.com/foo/dual",
+			Version:  "v1.0.0",
+			PURL:     "pkg:golang/github.com/foo/dual@v1.0.0",
+			Licenses: []cdxLicenseRef{{Expression: "MIT OR Apache-2.0"}},
+		},
+		{
+			Type:    "library",
+			BOMRef:  "pkg:golang/github.com/foo/missing@v0.1.0",
+			Name:    "github.com/foo/missing",
+			Version: "v0.1.0",
+			PURL:    "pkg:golang/github.com/foo/missing@v0.1.0",
+		},
+	}, actual.Components)
+}
+
+// validateCycloneDX14 checks the document against the constraints of the
+// CycloneDX 1.4 JSON schema for the properties that we produce.
+func validateCycloneDX14(t *testing.T, bom map[string]interface{}) {
+	t.Helper()
+
+	require.Equal(t, "CycloneDX", bom["bomFormat"])
+	require.Equal(t, "1.4", bom["specVersion"])
+	require.Regexp(t, `^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-[1-5][0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+		bom["serialNumber"])
+	require.GreaterOrEqual(t, bom["version"], float64(1))
+
+	refs := map[string]struct{}{}
+	hashRe := regexp.MustCompile(`^([a-fA-F0-9]{32}|[a-fA-F0-9]{40}|[a-fA-F0-9]{64}|[a-fA-F0-9]{96}|[a-fA-F0-9]{128})$`)
+	for _, raw := range bom["components"].([]interface{}) {
+		c := raw.(map[string]interface{})
+		require.Equal(t, "library", c["type"])
+		require.NotEmpty(t, c["name"])
+		require.Regexp(t, `^pkg:golang/`, c["purl"])
+
+		// bom-ref must be unique within the document
+		ref := c["bom-ref"].(string)
+		require.NotContains(t, refs, ref)
+		refs[ref] = struct{}{}
+
+		if hashes, ok := c["hashes"]; ok {
+			for _, raw := range hashes.([]interface{}) {
+				h := raw.(map[string]interface{})
+				require.Contains(t, []string{"MD5", "SHA-1", "SHA-256", "SHA-384", "SHA-512"}, h["alg"])
+				require.Regexp(t, hashRe, h["content"])
+			}
+		}
+
+		if licenses, ok := c["licenses"]; ok {
+			for _, raw := range licenses.([]interface{}) {
+				ref := raw.(map[string]interface{})
+
+				// Exactly one of license or expression
+				_, hasLicense := ref["license"]
+				expr, hasExpr := ref["expression"]
+				require.True(t, hasLicense != hasExpr, "must have either license or expression: %v", ref)
+				if hasExpr {
+					require.NotEmpty(t, expr)
+					continue
+				}
+
+				// Exactly one of id or name
+				l := ref["license"].(map[string]interface{})
+				_, hasID := l["id"]
+				_, hasName := l["name"]
+				require.True(t, hasID != hasName, "license must have either id or name: %v", l)
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+// GitHubActionsOutput is a ReportOutput implementation that emits GitHub Actions
+// workflow commands for every module that is denied or has an unknown
+// license, so that they show up as annotations in the Actions UI. Failed
+// lookups and modules without a license are annotated even without a
+// configuration. Modules allowed by an exception are noted with their
+// justification.
+type GitHubActionsOutput struct {
+	// Out is where the workflow commands are written. GitHub Actions only
+	// reads these from stdout.
+	Out io.Writer
+
+	// Config is the configuration (if any). This will be used to check
+	// if a license is allowed or not.
+	Config *config.Config
+}
+
+// Flush implements ReportOutput
+func (o *GitHubActionsOutput) Flush(results []Result) error {
+	for _, r := range results {
+		r := r
+		line := o.line(&r.Module, r.License, r.Err)
+		if line == "" {
+			continue
+		}
+		if _, err := fmt.Fprintln(o.Out, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// line returns the workflow command for a module, or an empty string if
+// the module needs no annotation.
+func (o *GitHubActionsOutput) line(m *module.Module, l *license.License, err error) string {
+	state := config.StateUnknown
+	if o.Config != nil {
+		state = o.Config.AllowedModule(m, l)
+	}
+
+	switch {
+	case state == config.StateAllowed:
+		ex, ok := o.Config.Exceptions[m.Path]
+		if !ok {
+			return ""
+		}
+
+		msg := fmt.Sprintf("%s: allowed by exception: %s", m.String(), ex)
+		return fmt.Sprintf("::notice title=golicense::%s", escapeWorkflowData(msg))
+
+	case l == nil && err != nil:
+		msg := fmt.Sprintf("%s: %s", m.String(), err)
+		return fmt.Sprintf("::error title=golicense::%s", escapeWorkflowData(msg))
+
+	case state == config.StateDenied:
+		msg := fmt.Sprintf("%s: license %q is denied", m.String(), l.String())
+		return fmt.Sprintf("::error title=golicense::%s", escapeWorkflowData(msg))
+
+	case state == config.StateNotAllowed:
+		msg := fmt.Sprintf("%s: license %q is not in the allow list", m.String(), l.String())
+		return fmt.Sprintf("::warning title=golicense::%s", escapeWorkflowData(msg))
+
+	case l == nil:
+		msg := fmt.Sprintf("%s: no license found", m.String())
+		return fmt.Sprintf("::warning title=golicense::%s", escapeWorkflowData(msg))
+	}
+
+	return ""
+}
+
+// escapeWorkflowData escapes a message for use as the data of a workflow
+// command, as documented by GitHub Actions.
+func escapeWorkflowData(s string) string {
+	return workflowDataReplacer.Replace(s)
+}
+
+var workflowDataReplacer = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubActionsOutput(t *testing.T) {
+	var buf bytes.Buffer
+	out := &GitHubActionsOutput{
+		Out: &buf,
+		Config: &config.Config{
+			Allow:      []string{"MIT"},
+			Deny:       []string{"GPL-3.0"},
+			Exceptions: map[string]string{"github.com/foo/excepted": "approved by legal"},
+		},
+	}
+
+	mit := &license.License{Name: "MIT License", SPDX: "MIT"}
+	gpl := &license.License{Name: "GNU General Public License v3.0", SPDX: "GPL-3.0"}
+	store := &ResultStore{Reports: []ReportOutput{out}}
+	store.Finish(&module.Module{Path: "github.com/foo/a", Version: "v1.0.0"}, mit, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/denied", Version: "v1.0.0"}, gpl, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/excepted", Version: "v1.0.0"}, gpl, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/failed", Version: "v1.0.0"}, nil, errors.New("boom"))
+	store.Finish(&module.Module{Path: "github.com/foo/other", Version: "v1.0.0"},
+		&license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0"}, nil)
+	require.NoError(t, store.Close())
+
+	// Allowed modules are left out
+	require.Equal(t, ""+
+		"::error title=golicense::github.com/foo/denied (v1.0.0): license \"GNU General Public License v3.0\" is denied\n"+
+		"::notice title=golicense::github.com/foo/excepted (v1.0.0): allowed by exception: approved by legal\n"+
+		"::error title=golicense::github.com/foo/failed (v1.0.0): boom\n"+
+		"::warning title=golicense::github.com/foo/other (v1.0.0): license \"Apache License 2.0\" is not in the allow list\n",
+		buf.String())
+}
+
+func TestGitHubActionsOutput_noConfig(t *testing.T) {
+	var buf bytes.Buffer
+	store := &ResultStore{Reports: []ReportOutput{&GitHubActionsOutput{Out: &buf}}}
+	store.Finish(&module.Module{Path: "github.com/foo/a", Version: "v1.0.0"},
+		&license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/failed", Version: "v1.0.0"}, nil, errors.New("boom"))
+	store.Finish(&module.Module{Path: "github.com/foo/none", Version: "v1.0.0"}, nil, nil)
+	require.NoError(t, store.Close())
+
+	require.Equal(t, ""+
+		"::error title=golicense::github.com/foo/failed (v1.0.0): boom\n"+
+		"::warning title=golicense::github.com/foo/none (v1.0.0): no license found\n",
+		buf.String())
+}
+
+func TestGitHubActionsOutput_escape(t *testing.T) {
+	var buf bytes.Buffer
+	store := &ResultStore{Reports: []ReportOutput{&GitHubActionsOutput{Out: &buf}}}
+	store.Finish(&module.Module{Path: "github.com/foo/failed", Version: "v1.0.0"},
+		nil, errors.New("100% failed\r\nretry"))
+	require.NoError(t, store.Close())
+
+	require.Equal(t,
+		"::error title=golicense::github.com/foo/failed (v1.0.0): 100%25 failed%0D%0Aretry\n",
+		buf.String())
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"io/ioutil"
+	"net/url"
+
+	"github.com/mitchellh/golicense/config"
+)
+
+// HTMLOutput writes the results of license lookups as a standalone HTML
+// page, for sharing the report with people who don't read terminal output.
+// Modules are colored like the terminal output.
+type HTMLOutput struct {
+	// Path is the path to the file to write. This will be overwritten if
+	// it exists.
+	Path string
+
+	// Config is the configuration (if any). This will be used to check
+	// if a license is allowed or not.
+	Config *config.Config
+
+	// ShowHashes, if true, shows the module hash below its version.
+	ShowHashes bool
+}
+
+// Flush implements ReportOutput
+func (o *HTMLOutput) Flush(results []Result) error {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, reportModules(results, o.Config, o.ShowHashes)); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(o.Path, buf.Bytes(), 0644)
+}
+
+// htmlLicenseURL returns the URL of a license to link to. html/template
+// only allows web URLs in links, but file URLs of local license files are
+// useful too when the report is opened on the same machine.
+func htmlLicenseURL(s string) template.URL {
+	u, err := url.Parse(s)
+	if err != nil {
+		return ""
+	}
+
+	switch u.Scheme {
+	case "http", "https", "file":
+		return template.URL(u.String())
+
+	default:
+		return ""
+	}
+}
+
+// htmlTemplate renders the report modules. Rows are colored by the allowed
+// state: red if denied, yellow if unknown. Licenses link to where they were
+// found.
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"licenseURL": htmlLicenseURL,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>golicense report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292e; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 0.4em 0.8em; border-bottom: 1px solid #e1e4e8; }
+th { background: #f6f8fa; }
+tr.no { background: #ffeef0; }
+tr.unknown { background: #fffbdd; }
+.badge { display: inline-block; padding: 0.1em 0.6em; border-radius: 1em; font-size: 0.85em; font-weight: 600; color: #fff; }
+.badge.yes { background: #28a745; }
+.badge.no { background: #d73a49; }
+.badge.unknown { background: #dbab09; }
+.error { color: #d73a49; font-size: 0.85em; }
+.exception { color: #586069; font-size: 0.85em; }
+.hash { display: block; color: #586069; font-family: monospace; font-size: 0.85em; }
+</style>
+</head>
+<body>
+<h1>golicense report</h1>
+<table>
+<thead>
+<tr><th>Module</th><th>Version</th><th>License</th><th>SPDX</th><th>Status</th></tr>
+</thead>
+<tbody>
+{{- range .}}
+<tr class="{{.Allowed}}">
+<td>{{.Path}}</td>
+<td>{{.Version}}{{if .Hash}} <span class="hash">{{.Hash}}</span>{{end}}</td>
+<td>{{if licenseURL .URL}}<a href="{{licenseURL .URL}}">{{.License}}</a>{{else}}{{.License}}{{end}}{{if .Error}} <span class="error">{{.Error}}</span>{{end}}</td>
+<td>{{.SPDX}}</td>
+<td><span class="badge {{.Allowed}}">{{if eq .Allowed "yes"}}Allowed{{else if eq .Allowed "no"}}Denied{{else}}Unknown{{end}}</span>{{if .Exception}} <span class="exception">Exception: {{.Exception}}</span>{{end}}</td>
+</tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.html")
+	out := &HTMLOutput{
+		Path: path,
+		Config: &config.Config{
+			Allow: []string{"MIT"},
+			Deny:  []string{"GPL-3.0"},
+		},
+	}
+
+	store := &ResultStore{Reports: []ReportOutput{out}}
+	store.Finish(&module.Module{Path: "github.com/foo/mit", Version: "v1.0.0"},
+		&license.License{Name: "MIT License", SPDX: "MIT",
+			URL: "https://github.com/foo/mit/blob/master/LICENSE"}, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/gpl", Version: "v0.2.0"},
+		&license.License{Name: "GNU General Public License v3.0", SPDX: "GPL-3.0"}, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/other", Version: "v2.0.0"},
+		&license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0",
+			URL: "javascript:alert(1)"}, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/missing", Version: "v1.1.0"},
+		nil, errors.New("<not found>"))
+	require.NoError(t, store.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	actual := string(data)
+
+	for _, s := range []string{
+		"github.com/foo/mit",
+		"v2.0.0",
+		"GNU General Public License v3.0",
+		"<td>GPL-3.0</td>",
+		`<span class="badge yes">Allowed</span>`,
+		`<span class="badge no">Denied</span>`,
+		"<tr class=\"no\">\n<td>github.com/foo/other</td>", // not in the allow list
+		"&lt;not found&gt;",
+		`<a href="https://github.com/foo/mit/blob/master/LICENSE">MIT License</a>`,
+		"<td>Apache License 2.0</td>",
+	} {
+		require.Contains(t, actual, s)
+	}
+	require.NotContains(t, actual, "javascript")
+
+	// Modules are sorted by path
+	require.True(t, strings.Index(actual, "github.com/foo/gpl") < strings.Index(actual, "github.com/foo/mit"))
+}
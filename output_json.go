@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+// JSONOutput writes the results of license lookups as a JSON array with
+// an object per module.
+type JSONOutput struct {
+	// Path is the path to the file to write. This will be overwritten if
+	// it exists. If Path is "-", the report is written to stdout.
+	Path string
+
+	// Config is the configuration (if any). This will be used to check
+	// if a license is allowed or not.
+	Config *config.Config
+
+	// ShowHashes, if true, includes the module hash of every module.
+	ShowHashes bool
+}
+
+// reportModule is a single module in the JSON and attestation reports,
+// and the data of a module in the other reports.
+type reportModule struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Hash    string `json:"hash,omitempty"`
+	License string `json:"license,omitempty"`
+	SPDX    string `json:"spdx,omitempty"`
+	URL     string `json:"url,omitempty"`
+	Source  string `json:"source,omitempty"`
+	Allowed string `json:"allowed"`
+	Error   string `json:"error,omitempty"`
+
+	// Exception is the justification if the module is allowed by an
+	// exception in the configuration.
+	Exception string `json:"exception,omitempty"`
+
+	// Confidence is the confidence of a license detected from its text.
+	Confidence float64 `json:"confidence,omitempty"`
+
+	// Conflicts are the SPDX IDs that other finders of equal priority
+	// found instead of the license.
+	Conflicts []string `json:"conflicts,omitempty"`
+
+	// Internal is true for an internal module, which isn't looked up
+	// publicly.
+	Internal bool `json:"internal,omitempty"`
+
+	// Published is when the version was published, and Stale is true if
+	// that was longer ago than -max-age. These are only set with -max-age.
+	Published *time.Time `json:"published,omitempty"`
+	Stale     bool       `json:"stale,omitempty"`
+}
+
+// reportModules creates the report entries for the results of the lookups.
+func reportModules(results []Result, c *config.Config, showHashes bool) []reportModule {
+	report := make([]reportModule, 0, len(results))
+	for _, r := range results {
+		r := r
+		report = append(report, newReportModule(&r.Module, r.License, r.Err, c, showHashes))
+	}
+
+	return report
+}
+
+// newReportModule creates the report entry for the result of a lookup.
+// Allowed is "yes", "no" or "unknown" depending on the configuration. A
+// license that isn't in the allow list is "no" like a denied one. Hash is
+// only set if showHashes is true.
+func newReportModule(m *module.Module, l *license.License, err error, c *config.Config, showHashes bool) reportModule {
+	rm := reportModule{
+		Path:     m.Path,
+		Version:  m.Version,
+		Allowed:  "unknown",
+		Internal: m.Private,
+		Stale:    m.Stale,
+	}
+	if showHashes {
+		rm.Hash = m.Hash
+	}
+	if !m.Time.IsZero() {
+		t := m.Time
+		rm.Published = &t
+	}
+	if l != nil {
+		rm.License = l.Name
+		rm.SPDX = l.SPDX
+		rm.URL = l.URL
+		rm.Source = l.Source
+		rm.Confidence = l.Confidence
+		rm.Conflicts = l.Conflicts
+	}
+	if err != nil {
+		rm.Error = err.Error()
+	}
+	if c != nil {
+		rm.Exception = c.Exceptions[m.Path]
+		switch c.AllowedModule(m, l) {
+		case config.StateAllowed:
+			rm.Allowed = "yes"
+
+		case config.StateDenied, config.StateNotAllowed:
+			rm.Allowed = "no"
+		}
+	}
+
+	return rm
+}
+
+// Flush implements ReportOutput
+func (o *JSONOutput) Flush(results []Result) error {
+	data, err := json.MarshalIndent(reportModules(results, o.Config, o.ShowHashes), "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if o.Path == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	// Write to a temporary file first and rename it into place so that
+	// an existing report is never left partially written.
+	f, err := ioutil.TempFile(filepath.Dir(o.Path), ".golicense-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), o.Path)
+}
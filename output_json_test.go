@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v18/github"
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	githubFinder "github.com/mitchellh/golicense/license/github"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	out := &JSONOutput{
+		Path: path,
+		Config: &config.Config{
+			Allow: []string{"MIT"},
+			Deny:  []string{"GPL-3.0"},
+		},
+	}
+
+	store := &ResultStore{Reports: []ReportOutput{out}}
+	store.Finish(&module.Module{Path: "github.com/foo/gpl", Version: "v0.2.0"},
+		&license.License{Name: "GNU General Public License v3.0", SPDX: "GPL-3.0"}, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/mit", Version: "v1.0.0", Hash: "h1:mit="},
+		&license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/missing", Version: "v1.1.0"},
+		nil, errors.New("not found"))
+	store.Finish(&module.Module{Path: "github.com/foo/other", Version: "v2.0.0"},
+		&license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0"}, nil)
+	require.NoError(t, store.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	var actual []map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &actual))
+	require.Equal(t, []map[string]interface{}{
+		{
+			"path":    "github.com/foo/gpl",
+			"version": "v0.2.0",
+			"license": "GNU General Public License v3.0",
+			"spdx":    "GPL-3.0",
+			"allowed": "no",
+		},
+		{
+			"path":    "github.com/foo/missing",
+			"version": "v1.1.0",
+			"allowed": "no",
+			"error":   "not found",
+		},
+		{
+			"path":    "github.com/foo/mit",
+			"version": "v1.0.0",
+			"license": "MIT License",
+			"spdx":    "MIT",
+			"allowed": "yes",
+		},
+		{
+			"path":    "github.com/foo/other",
+			"version": "v2.0.0",
+			"license": "Apache License 2.0",
+			"spdx":    "Apache-2.0",
+			"allowed": "no",
+		},
+	}, actual)
+
+	// No temporary files are left behind
+	files, err := ioutil.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+}
+
+func TestJSONOutput_hashes(t *testing.T) {
+	report := func(show bool) map[string]interface{} {
+		path := filepath.Join(t.TempDir(), "report.json")
+		store := &ResultStore{Reports: []ReportOutput{&JSONOutput{Path: path, ShowHashes: show}}}
+		store.Finish(&module.Module{Path: "github.com/foo/bar", Version: "v1.0.0", Hash: "h1:abcd1234="},
+			&license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+		require.NoError(t, store.Close())
+
+		data, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+
+		var actual []map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &actual))
+		require.Len(t, actual, 1)
+		return actual[0]
+	}
+
+	// Omitted by default
+	require.NotContains(t, report(false), "hash")
+	require.Equal(t, "h1:abcd1234=", report(true)["hash"])
+}
+
+func TestJSONOutput_notAllowed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	out := &JSONOutput{Path: path, Config: &config.Config{Allow: []string{"MIT"}}}
+
+	store := &ResultStore{Reports: []ReportOutput{out}}
+	store.Finish(&module.Module{Path: "github.com/foo/apache", Version: "v1.0.0"},
+		&license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0"}, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/custom", Version: "v1.0.0"},
+		&license.License{Name: "Custom License"}, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/mit", Version: "v1.0.0"},
+		&license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+	require.NoError(t, store.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	var actual []map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &actual))
+	require.Len(t, actual, 3)
+
+	// Licenses outside of the allow list fail like denied ones
+	require.Equal(t, "no", actual[0]["allowed"])
+	require.Equal(t, "no", actual[1]["allowed"])
+	require.Equal(t, "yes", actual[2]["allowed"])
+}
+
+func TestJSONOutput_exception(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	out := &JSONOutput{
+		Path: path,
+		Config: &config.Config{
+			Deny: []string{"GPL-3.0"},
+			Exceptions: map[string]string{
+				"github.com/foo/gpl": "Only used by internal tooling",
+			},
+		},
+	}
+
+	store := &ResultStore{Reports: []ReportOutput{out}}
+	store.Finish(&module.Module{Path: "github.com/foo/gpl", Version: "v0.2.0"},
+		&license.License{Name: "GNU General Public License v3.0", SPDX: "GPL-3.0"}, nil)
+	require.NoError(t, store.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	var actual []map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &actual))
+	require.Equal(t, []map[string]interface{}{
+		{
+			"path":      "github.com/foo/gpl",
+			"version":   "v0.2.0",
+			"license":   "GNU General Public License v3.0",
+			"spdx":      "GPL-3.0",
+			"allowed":   "yes",
+			"exception": "Only used by internal tooling",
+		},
+	}, actual)
+}
+
+func TestJSONOutput_licenseURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"html_url": "https://github.com/foo/bar/blob/master/LICENSE",
+			"license": {"key": "mit", "name": "MIT License", "spdx_id": "MIT"}
+		}`))
+	}))
+	defer srv.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	// The URL of the license found by the GitHub finder is in the report
+	m := &module.Module{Path: "github.com/foo/bar", Version: "v1.0.0"}
+	lic, err := (&githubFinder.RepoAPI{Client: client}).License(context.Background(), *m)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	out := &JSONOutput{Path: path}
+	store := &ResultStore{Reports: []ReportOutput{out}}
+	store.Finish(m, lic, nil)
+	require.NoError(t, store.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	var actual []map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &actual))
+	require.Equal(t, []map[string]interface{}{
+		{
+			"path":    "github.com/foo/bar",
+			"version": "v1.0.0",
+			"license": "MIT License",
+			"spdx":    "MIT",
+			"url":     "https://github.com/foo/bar/blob/master/LICENSE",
+			"source":  "github",
+			"allowed": "unknown",
+		},
+	}, actual)
+}
+
+func TestJSONOutput_confidence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	out := &JSONOutput{Path: path}
+	store := &ResultStore{Reports: []ReportOutput{out}}
+	store.Finish(&module.Module{Path: "github.com/foo/detected", Version: "v1.0.0"},
+		&license.License{Name: "MIT License", SPDX: "MIT", Confidence: 0.95}, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/api", Version: "v1.0.0"},
+		&license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+	require.NoError(t, store.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	var actual []map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &actual))
+	require.Len(t, actual, 2)
+
+	// Only licenses detected from their text have a confidence
+	for _, m := range actual {
+		switch m["path"] {
+		case "github.com/foo/detected":
+			require.Equal(t, 0.95, m["confidence"])
+		case "github.com/foo/api":
+			require.NotContains(t, m, "confidence")
+		}
+	}
+}
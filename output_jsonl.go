@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+// JSONLOutput writes the results of license lookups as JSON Lines, one
+// object per module written as soon as its lookup finishes. Unlike
+// JSONOutput nothing is buffered, so large runs can be processed while
+// they are running. The lines are in the order the lookups finish.
+type JSONLOutput struct {
+	// Path is the path to the file to write. This will be overwritten if
+	// it exists. If Path is "-", the lines are written to stdout.
+	Path string
+
+	// Config is the configuration (if any). This will be used to check
+	// if a license is allowed or not.
+	Config *config.Config
+
+	// ShowHashes, if true, includes the module hash of every module.
+	ShowHashes bool
+
+	w    io.Writer
+	f    *os.File
+	err  error // first error writing, returned by Close
+	lock sync.Mutex
+}
+
+// jsonlModule is a line of a JSONLOutput.
+type jsonlModule struct {
+	reportModule
+
+	// Status is "allowed", "denied" or "unknown".
+	Status string `json:"status"`
+}
+
+// Start implements Output
+func (o *JSONLOutput) Start(m *module.Module) {}
+
+// Update implements Output
+func (o *JSONLOutput) Update(m *module.Module, t license.StatusType, msg string) {}
+
+// Finish implements Output
+func (o *JSONLOutput) Finish(m *module.Module, l *license.License, err error) {
+	rm := newReportModule(m, l, err, o.Config, o.ShowHashes)
+	data, jerr := json.Marshal(jsonlModule{reportModule: rm, Status: templateStatus[rm.Allowed]})
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if o.err != nil {
+		return
+	}
+	if jerr != nil {
+		o.err = jerr
+		return
+	}
+	if o.err = o.open(); o.err != nil {
+		return
+	}
+
+	_, o.err = o.w.Write(append(data, '\n'))
+}
+
+// Close implements Output
+func (o *JSONLOutput) Close() error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	// Without any module the file is still created, but empty
+	if o.err == nil {
+		o.err = o.open()
+	}
+	if o.f != nil {
+		if err := o.f.Close(); err != nil && o.err == nil {
+			o.err = err
+		}
+		o.f = nil
+	}
+
+	return o.err
+}
+
+// open opens the file on the first write. lock must be held.
+func (o *JSONLOutput) open() error {
+	if o.w != nil {
+		return nil
+	}
+
+	if o.Path == "-" {
+		o.w = os.Stdout
+		return nil
+	}
+
+	f, err := os.Create(o.Path)
+	if err != nil {
+		return err
+	}
+
+	o.f, o.w = f, f
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.jsonl")
+	out := &JSONLOutput{
+		Path: path,
+		Config: &config.Config{
+			Allow: []string{"MIT"},
+			Deny:  []string{"GPL-3.0"},
+		},
+	}
+
+	// Finishes arrive concurrently, as they do from the lookups
+	const n = 100
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			lic := &license.License{Name: "MIT License", SPDX: "MIT"}
+			if i%2 == 1 {
+				lic = &license.License{Name: "GNU General Public License v3.0", SPDX: "GPL-3.0"}
+			}
+			out.Finish(&module.Module{Path: fmt.Sprintf("github.com/foo/mod%d", i), Version: "v1.0.0"}, lic, nil)
+		}(i)
+	}
+	wg.Wait()
+	require.NoError(t, out.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	// Every line is a complete object on its own
+	lines := 0
+	statuses := map[string]int{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines++
+
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line), scanner.Text())
+		require.Equal(t, "v1.0.0", line["version"])
+		require.Contains(t, line["path"], "github.com/foo/mod")
+		require.Contains(t, []interface{}{"MIT", "GPL-3.0"}, line["spdx"])
+		statuses[line["status"].(string)]++
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, n, lines)
+	require.Equal(t, map[string]int{"allowed": n / 2, "denied": n / 2}, statuses)
+}
+
+func TestJSONLOutput_empty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.jsonl")
+	out := &JSONLOutput{Path: path}
+	require.NoError(t, out.Close())
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Zero(t, fi.Size())
+}
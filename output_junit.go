@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+// JUnitOutput writes the results of license lookups as a JUnit XML report.
+// Each module is a test case that fails if its license is denied, or not
+// allowed when an allow or deny list is configured. A failed lookup or a
+// module without a license fails even without a configuration.
+type JUnitOutput struct {
+	// Path is the path to the file to write. This will be overwritten if
+	// it exists.
+	Path string
+
+	// Config is the configuration (if any). This will be used to check
+	// if a license is allowed or not.
+	Config *config.Config
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// testCase creates the test case of a module with the result of its
+// lookup.
+func (o *JUnitOutput) testCase(m *module.Module, l *license.License, err error) junitTestCase {
+	tc := junitTestCase{
+		Name:      m.String(),
+		ClassName: m.Path,
+	}
+
+	state := config.StateUnknown
+	if o.Config != nil {
+		if ex, ok := o.Config.Exceptions[m.Path]; ok {
+			tc.SystemOut = fmt.Sprintf("allowed by exception: %s", ex)
+		}
+
+		state = o.Config.AllowedModule(m, l)
+	}
+
+	var failure string
+	switch {
+	case state == config.StateAllowed:
+
+	case l == nil && err != nil:
+		failure = "license lookup failed"
+
+	case state == config.StateDenied:
+		failure = fmt.Sprintf("license denied: %s", l.String())
+
+	case state == config.StateNotAllowed:
+		failure = fmt.Sprintf("license not allowed: %s", l.String())
+
+	case l == nil:
+		failure = "license not found"
+	}
+	if failure != "" {
+		tc.Failure = &junitFailure{Message: failure, Text: failure}
+		if err != nil {
+			tc.Failure.Text = fmt.Sprintf("%s\n\n%s", failure, err)
+		}
+	}
+
+	return tc
+}
+
+// Flush implements ReportOutput
+func (o *JUnitOutput) Flush(results []Result) error {
+	suite := junitTestSuite{Name: "golicense"}
+	for _, r := range results {
+		r := r
+		tc := o.testCase(&r.Module, r.License, r.Err)
+		suite.Cases = append(suite.Cases, tc)
+		suite.Tests++
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+	}
+
+	data, err := xml.MarshalIndent(junitTestSuites{
+		Suites: []junitTestSuite{suite},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(o.Path, append([]byte(xml.Header), data...), 0644)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJUnitOutput(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Config   *config.Config
+		Failures map[string]string // by module path
+	}{
+		{
+			"config",
+			&config.Config{
+				Allow:      []string{"MIT"},
+				Deny:       []string{"GPL-3.0"},
+				Exceptions: map[string]string{"github.com/foo/excepted": "approved by legal"},
+			},
+			map[string]string{
+				"github.com/foo/denied": "license denied: GNU General Public License v3.0",
+				"github.com/foo/failed": "license lookup failed",
+				"github.com/foo/none":   "license denied: <license not found or detected>",
+				"github.com/foo/other":  "license not allowed: Apache License 2.0",
+			},
+		},
+
+		{
+			"no config",
+			nil,
+			map[string]string{
+				"github.com/foo/failed": "license lookup failed",
+				"github.com/foo/none":   "license not found",
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "report.xml")
+			store := &ResultStore{Reports: []ReportOutput{&JUnitOutput{Path: path, Config: tt.Config}}}
+
+			mit := &license.License{Name: "MIT License", SPDX: "MIT"}
+			gpl := &license.License{Name: "GNU General Public License v3.0", SPDX: "GPL-3.0"}
+			store.Finish(&module.Module{Path: "github.com/foo/a", Version: "v1.0.0"}, mit, nil)
+			store.Finish(&module.Module{Path: "github.com/foo/denied", Version: "v1.0.0"}, gpl, nil)
+			store.Finish(&module.Module{Path: "github.com/foo/excepted", Version: "v1.0.0"}, gpl, nil)
+			store.Finish(&module.Module{Path: "github.com/foo/failed", Version: "v1.0.0"}, nil, errors.New("boom"))
+			store.Finish(&module.Module{Path: "github.com/foo/none", Version: "v1.0.0"}, nil, nil)
+			store.Finish(&module.Module{Path: "github.com/foo/other", Version: "v1.0.0"},
+				&license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0"}, nil)
+			require.NoError(t, store.Close())
+
+			data, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			var report junitTestSuites
+			require.NoError(t, xml.Unmarshal(data, &report))
+			require.Len(t, report.Suites, 1)
+
+			suite := report.Suites[0]
+			require.Equal(t, 6, suite.Tests)
+			require.Equal(t, len(tt.Failures), suite.Failures)
+
+			failures := map[string]string{}
+			for _, tc := range suite.Cases {
+				if tc.Failure != nil {
+					failures[tc.ClassName] = tc.Failure.Message
+				}
+			}
+			require.Equal(t, tt.Failures, failures)
+
+			// The error of a failed lookup is in the failure text
+			for _, tc := range suite.Cases {
+				if tc.ClassName == "github.com/foo/failed" {
+					require.Contains(t, tc.Failure.Text, "boom")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/mitchellh/golicense/config"
+)
+
+// MarkdownOutput writes the results of license lookups as a GitHub-flavored
+// Markdown table, such as for posting as a pull request comment.
+type MarkdownOutput struct {
+	// Path is the path to the file to write. This will be overwritten if
+	// it exists. If Path is "-", the report is written to stdout.
+	Path string
+
+	// Config is the configuration (if any). This will be used to check
+	// if a license is allowed or not.
+	Config *config.Config
+
+	// ShowHashes, if true, adds a Hash column with the module hash.
+	ShowHashes bool
+}
+
+// Flush implements ReportOutput
+func (o *MarkdownOutput) Flush(results []Result) error {
+	report := reportModules(results, o.Config, o.ShowHashes)
+
+	var buf bytes.Buffer
+	counts := map[string]int{}
+	if o.ShowHashes {
+		buf.WriteString("| Module | Version | Hash | License | SPDX | Status |\n")
+		buf.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	} else {
+		buf.WriteString("| Module | Version | License | SPDX | Status |\n")
+		buf.WriteString("| --- | --- | --- | --- | --- |\n")
+	}
+	for _, rm := range report {
+		counts[rm.Allowed]++
+
+		lic := rm.License
+		if rm.Error != "" {
+			lic = strings.TrimSpace(lic + " (error: " + rm.Error + ")")
+		}
+
+		status := markdownStatus[rm.Allowed]
+		if rm.Exception != "" {
+			status += " (exception: " + markdownCell(rm.Exception) + ")"
+		}
+
+		version := markdownCell(rm.Version)
+		if o.ShowHashes {
+			version += " | " + markdownCell(rm.Hash)
+		}
+
+		fmt.Fprintf(&buf, "| %s | %s | %s | %s | %s |\n",
+			markdownCell(rm.Path),
+			version,
+			markdownCell(lic),
+			markdownCell(rm.SPDX),
+			status)
+	}
+	fmt.Fprintf(&buf, "\n**%d modules:** %d allowed, %d denied, %d unknown\n",
+		len(report), counts["yes"], counts["no"], counts["unknown"])
+
+	if o.Path == "-" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+
+	return ioutil.WriteFile(o.Path, buf.Bytes(), 0644)
+}
+
+// markdownStatus is the status column for each allowed state of a
+// reportModule.
+var markdownStatus = map[string]string{
+	"yes":     "✅ Allowed",
+	"no":      "❌ Denied",
+	"unknown": "⚠️ Unknown",
+}
+
+// markdownCell escapes the value for use in a table cell. Pipes would end
+// the cell and newlines the row.
+func markdownCell(v string) string {
+	v = strings.Replace(v, "|", `\|`, -1)
+	v = strings.Replace(v, "\r\n", " ", -1)
+	return strings.Replace(v, "\n", " ", -1)
+}
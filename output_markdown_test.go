@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdownOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.md")
+	out := &MarkdownOutput{
+		Path: path,
+		Config: &config.Config{
+			Allow: []string{"MIT"},
+			Deny:  []string{"GPL-3.0"},
+		},
+	}
+
+	store := &ResultStore{Reports: []ReportOutput{out}}
+	store.Finish(&module.Module{Path: "github.com/foo/mit", Version: "v1.0.0"},
+		&license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/gpl", Version: "v0.2.0"},
+		&license.License{Name: "GNU General Public License v3.0", SPDX: "GPL-3.0"}, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/other", Version: "v2.0.0"},
+		&license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0"}, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/missing", Version: "v1.1.0"},
+		nil, errors.New("not | found"))
+	require.NoError(t, store.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	actual := string(data)
+
+	for _, s := range []string{
+		"| Module | Version | License | SPDX | Status |\n| --- | --- | --- | --- | --- |\n",
+		"| github.com/foo/gpl | v0.2.0 | GNU General Public License v3.0 | GPL-3.0 | ❌ Denied |",
+		"| github.com/foo/mit | v1.0.0 | MIT License | MIT | ✅ Allowed |",
+		"| github.com/foo/other | v2.0.0 | Apache License 2.0 | Apache-2.0 | ❌ Denied |",
+		"| github.com/foo/missing | v1.1.0 | (error: not \\| found) |  | ❌ Denied |",
+		"**4 modules:** 1 allowed, 3 denied, 0 unknown\n",
+	} {
+		require.Contains(t, actual, s)
+	}
+
+	// Modules are sorted by path
+	require.True(t, strings.Index(actual, "github.com/foo/gpl") < strings.Index(actual, "github.com/foo/mit"))
+}
+
+func TestMarkdownOutput_hashes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.md")
+	store := &ResultStore{Reports: []ReportOutput{&MarkdownOutput{Path: path, ShowHashes: true}}}
+	store.Finish(&module.Module{Path: "github.com/foo/mit", Version: "v1.0.0", Hash: "h1:abcd1234="},
+		&license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+	require.NoError(t, store.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "| Module | Version | Hash | License | SPDX | Status |\n"+
+		"| --- | --- | --- | --- | --- | --- |\n"+
+		"| github.com/foo/mit | v1.0.0 | h1:abcd1234= | MIT License | MIT | ⚠️ Unknown |\n")
+}
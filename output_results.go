@@ -0,0 +1,89 @@
+package main
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+// Result is the result of the license lookup of a module, as given to
+// Output.Finish.
+type Result struct {
+	Module  module.Module
+	License *license.License
+	Err     error
+}
+
+// ResultStore is an Output that collects the results of the lookups, which
+// finish in any order, and writes the reports with them sorted by module
+// path and version once all lookups are complete. It is safe for
+// concurrent use.
+type ResultStore struct {
+	// Reports are the reports written when the store is closed.
+	Reports []ReportOutput
+
+	results map[string]Result // by module path and version
+	lock    sync.Mutex
+}
+
+// Start implements Output
+func (s *ResultStore) Start(m *module.Module) {}
+
+// Update implements Output
+func (s *ResultStore) Update(m *module.Module, t license.StatusType, msg string) {}
+
+// Finish implements Output
+func (s *ResultStore) Finish(m *module.Module, l *license.License, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.results == nil {
+		s.results = make(map[string]Result)
+	}
+
+	// The same module can be in several binaries with different versions,
+	// which are kept apart. The same version is only reported once.
+	s.results[m.Path+"@"+m.Version] = Result{Module: *m, License: l, Err: err}
+}
+
+// Results returns the results collected so far, sorted by module path and
+// then by version.
+func (s *ResultStore) Results() []Result {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	results := make([]Result, 0, len(s.results))
+	for _, r := range s.results {
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i].Module, results[j].Module
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if c, ok := module.CompareVersions(a.Version, b.Version); ok {
+			return c < 0
+		}
+
+		return a.Version < b.Version
+	})
+
+	return results
+}
+
+// Close implements Output. Every report is written, even if an earlier
+// one failed.
+func (s *ResultStore) Close() error {
+	results := s.Results()
+
+	var err error
+	for _, r := range s.Reports {
+		if e := r.Flush(results); e != nil {
+			err = multierror.Append(err, e)
+		}
+	}
+
+	return err
+}
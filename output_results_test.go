@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultStore_order(t *testing.T) {
+	mods := []module.Module{
+		{Path: "github.com/foo/bar", Version: "v1.9.0"},
+		{Path: "github.com/foo/bar", Version: "v1.10.0"},
+		{Path: "github.com/foo/baz", Version: "v0.1.0"},
+		{Path: "github.com/foo/qux", Version: "v2.0.0"},
+	}
+	for i := 0; i < 20; i++ {
+		mods = append(mods, module.Module{Path: fmt.Sprintf("github.com/foo/mod%02d", i), Version: "v1.0.0"})
+	}
+
+	dir := t.TempDir()
+	jsonOut := &JSONOutput{Path: filepath.Join(dir, "report.json")}
+	markdownOut := &MarkdownOutput{Path: filepath.Join(dir, "report.md")}
+	store := &ResultStore{Reports: []ReportOutput{jsonOut, markdownOut}}
+
+	// Finishes arrive concurrently and in any order, as they do from the
+	// lookups
+	var wg sync.WaitGroup
+	for _, i := range rand.Perm(len(mods)) {
+		wg.Add(1)
+		go func(m module.Module) {
+			defer wg.Done()
+			store.Finish(&m, &license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+		}(mods[i])
+	}
+	wg.Wait()
+	require.NoError(t, store.Close())
+
+	// Versions are in semver order, not alphabetical
+	var expected []string
+	for _, r := range store.Results() {
+		expected = append(expected, r.Module.Path+" "+r.Module.Version)
+	}
+	require.Len(t, expected, len(mods))
+	require.Equal(t, "github.com/foo/bar v1.9.0", expected[0])
+	require.Equal(t, "github.com/foo/bar v1.10.0", expected[1])
+
+	data, err := ioutil.ReadFile(jsonOut.Path)
+	require.NoError(t, err)
+	var report []reportModule
+	require.NoError(t, json.Unmarshal(data, &report))
+	var jsonOrder []string
+	for _, rm := range report {
+		jsonOrder = append(jsonOrder, rm.Path+" "+rm.Version)
+	}
+
+	data, err = ioutil.ReadFile(markdownOut.Path)
+	require.NoError(t, err)
+	var markdownOrder []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if cells := strings.Split(line, " | "); len(cells) == 5 && strings.HasPrefix(line, "| github.com/") {
+			markdownOrder = append(markdownOrder, strings.TrimPrefix(cells[0], "| ")+" "+cells[1])
+		}
+	}
+
+	require.Equal(t, expected, jsonOrder)
+	require.Equal(t, expected, markdownOrder)
+}
+
+func TestResultStore_sameVersion(t *testing.T) {
+	store := &ResultStore{}
+	m := &module.Module{Path: "github.com/foo/bar", Version: "v1.0.0"}
+	store.Finish(m, nil, license.ErrNotFound)
+	store.Finish(m, &license.License{SPDX: "MIT"}, nil)
+
+	// The same module version is only reported once, with its last result
+	results := store.Results()
+	require.Len(t, results, 1)
+	require.Equal(t, "MIT", results[0].License.SPDX)
+	require.NoError(t, results[0].Err)
+}
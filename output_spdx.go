@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/license/goproxy"
+	"github.com/mitchellh/golicense/module"
+)
+
+// spdxNoAssertion is used for all SPDX fields where the value is unknown.
+const spdxNoAssertion = "NOASSERTION"
+
+// spdxDetectedPrefix is the prefix of the license comment for a license
+// that has no SPDX ID.
+const spdxDetectedPrefix = "Detected license: "
+
+// spdxExceptionPrefix is the prefix of the license comment for a module
+// that is allowed by an exception.
+const spdxExceptionPrefix = "Allowed by exception in the golicense configuration: "
+
+// SPDXOutput writes the modules and their licenses as an SPDX 2.3 document
+// in tag-value format.
+type SPDXOutput struct {
+	// Path is the path to the file to write. This will be overwritten if
+	// it exists.
+	Path string
+
+	// Config is the configuration (if any). Licenses overridden in the
+	// configuration are always used as the concluded license.
+	Config *config.Config
+}
+
+type spdxPackage struct {
+	Module  module.Module
+	License string
+	Comment string
+}
+
+// newPackage creates the package of a module with its license.
+func (o *SPDXOutput) newPackage(m *module.Module, l *license.License) spdxPackage {
+	p := spdxPackage{Module: *m, License: spdxNoAssertion}
+	switch {
+	case o.Config != nil && o.Config.Override[m.Path] != "":
+		p.License = o.Config.Override[m.Path]
+		p.Comment = "License overridden in the golicense configuration."
+
+	case l != nil && l.SPDX != "":
+		p.License = l.SPDX
+
+	case l != nil && l.Name != "":
+		// Without an SPDX ID there is no valid license expression
+		p.Comment = spdxDetectedPrefix + l.Name
+	}
+	if o.Config != nil && o.Config.Exceptions[m.Path] != "" {
+		p.Comment = strings.TrimSpace(p.Comment + " " + spdxExceptionPrefix + o.Config.Exceptions[m.Path])
+	}
+
+	return p
+}
+
+// Flush implements ReportOutput
+func (o *SPDXOutput) Flush(results []Result) error {
+	id, err := uuidV4()
+	if err != nil {
+		return err
+	}
+
+	var buf strings.Builder
+	buf.WriteString("SPDXVersion: SPDX-2.3\n")
+	buf.WriteString("DataLicense: CC0-1.0\n")
+	buf.WriteString("SPDXID: SPDXRef-DOCUMENT\n")
+	buf.WriteString("DocumentName: golicense\n")
+	fmt.Fprintf(&buf, "DocumentNamespace: https://spdx.org/spdxdocs/golicense-%s\n", id)
+	buf.WriteString("Creator: Tool: golicense\n")
+	fmt.Fprintf(&buf, "Created: %s\n", time.Now().UTC().Format(time.RFC3339))
+
+	for _, r := range results {
+		p := o.newPackage(&r.Module, r.License)
+		m := p.Module
+
+		location := spdxNoAssertion
+		if m.Version != "" {
+			location = goproxy.ZipURL(m.Path, m.Version)
+		}
+
+		buf.WriteString("\n")
+		fmt.Fprintf(&buf, "PackageName: %s\n", m.Path)
+		fmt.Fprintf(&buf, "SPDXID: %s\n", spdxPackageID(m))
+		if m.Version != "" {
+			fmt.Fprintf(&buf, "PackageVersion: %s\n", m.Version)
+		}
+		fmt.Fprintf(&buf, "PackageDownloadLocation: %s\n", location)
+		buf.WriteString("FilesAnalyzed: false\n")
+		fmt.Fprintf(&buf, "PackageLicenseConcluded: %s\n", p.License)
+		fmt.Fprintf(&buf, "PackageLicenseDeclared: %s\n", spdxNoAssertion)
+		if p.Comment != "" {
+			fmt.Fprintf(&buf, "PackageLicenseComments: <text>%s</text>\n", p.Comment)
+		}
+		fmt.Fprintf(&buf, "PackageCopyrightText: %s\n", spdxNoAssertion)
+		fmt.Fprintf(&buf, "ExternalRef: PACKAGE-MANAGER purl %s\n", modulePURL(&m))
+		fmt.Fprintf(&buf, "Relationship: SPDXRef-DOCUMENT DESCRIBES %s\n", spdxPackageID(m))
+	}
+
+	return ioutil.WriteFile(o.Path, []byte(buf.String()), 0644)
+}
+
+// spdxPackageID returns the SPDX identifier of the package for a module.
+// Identifiers may only contain letters, numbers, "." and "-".
+func spdxPackageID(m module.Module) string {
+	id := m.Path
+	if m.Version != "" {
+		id += "-" + m.Version
+	}
+
+	return "SPDXRef-Package-" + spdxIDRe.ReplaceAllString(id, "-")
+}
+
+// spdxIDRe matches the characters that aren't allowed in SPDX identifiers.
+var spdxIDRe = regexp.MustCompile(`[^a-zA-Z0-9.-]`)
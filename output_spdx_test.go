@@ -0,0 +1,113 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSPDXOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.spdx")
+	out := &SPDXOutput{
+		Path: path,
+		Config: &config.Config{
+			Override: map[string]string{"github.com/foo/override": "BSD-3-Clause"},
+		},
+	}
+
+	store := &ResultStore{Reports: []ReportOutput{out}}
+	store.Finish(&module.Module{Path: "github.com/foo/mit", Version: "v1.0.0"},
+		&license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/missing", Version: "v0.1.0"},
+		nil, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/custom", Version: "v2.0.0+incompatible"},
+		&license.License{Name: "Custom License"}, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/override", Version: "v1.1.0"},
+		&license.License{Name: "BSD 3-Clause \"New\" or \"Revised\" License", SPDX: "BSD-3-Clause"}, nil)
+	require.NoError(t, store.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	doc := string(data)
+
+	require.True(t, strings.HasPrefix(doc, "SPDXVersion: SPDX-2.3\n"))
+	require.NotContains(t, doc, ": \n", "fields must never be empty")
+
+	packages := strings.Split(doc, "\nPackageName: ")[1:]
+	require.Len(t, packages, 4)
+
+	cases := []struct {
+		Name     string
+		Contains []string
+	}{
+		{
+			"github.com/foo/custom",
+			[]string{
+				"SPDXID: SPDXRef-Package-github.com-foo-custom-v2.0.0-incompatible\n",
+				"PackageDownloadLocation: https://proxy.golang.org/github.com/foo/custom/@v/v2.0.0+incompatible.zip\n",
+				"PackageLicenseConcluded: NOASSERTION\n",
+				"PackageLicenseComments: <text>Detected license: Custom License</text>\n",
+			},
+		},
+
+		{
+			"github.com/foo/missing",
+			[]string{
+				"PackageLicenseConcluded: NOASSERTION\n",
+			},
+		},
+
+		{
+			"github.com/foo/mit",
+			[]string{
+				"PackageVersion: v1.0.0\n",
+				"PackageLicenseConcluded: MIT\n",
+				"ExternalRef: PACKAGE-MANAGER purl pkg:golang/github.com/foo/mit@v1.0.0\n",
+			},
+		},
+
+		{
+			"github.com/foo/override",
+			[]string{
+				"PackageLicenseConcluded: BSD-3-Clause\n",
+				"PackageLicenseComments: <text>License overridden in the golicense configuration.</text>\n",
+			},
+		},
+	}
+
+	for i, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			p := packages[i]
+			require.True(t, strings.HasPrefix(p, tt.Name+"\n"), p)
+			for _, c := range tt.Contains {
+				require.Contains(t, p, c)
+			}
+		})
+	}
+}
+
+func TestSPDXOutput_overrideError(t *testing.T) {
+	// If the override couldn't be looked up, it must still be reflected
+	path := filepath.Join(t.TempDir(), "report.spdx")
+	out := &SPDXOutput{
+		Path: path,
+		Config: &config.Config{
+			Override: map[string]string{"github.com/foo/bar": "MIT"},
+		},
+	}
+
+	store := &ResultStore{Reports: []ReportOutput{out}}
+	store.Finish(&module.Module{Path: "github.com/foo/bar", Version: "v1.0.0"},
+		nil, nil)
+	require.NoError(t, store.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "PackageLicenseConcluded: MIT\n")
+}
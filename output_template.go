@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/mitchellh/golicense/config"
+)
+
+// TemplateOutput writes the results of license lookups with a user-supplied
+// Go text/template, for reports that no built-in format covers. The
+// template is executed with a templateReport and can use the functions in
+// templateFuncs.
+type TemplateOutput struct {
+	// Path is the path to the file to write. This will be overwritten if
+	// it exists. If Path is "-", the report is written to stdout.
+	Path string
+
+	// Config is the configuration (if any). This will be used to check
+	// if a license is allowed or not.
+	Config *config.Config
+
+	// ShowHashes, if true, sets the Hash of the modules in the report.
+	ShowHashes bool
+
+	tmpl *template.Template
+}
+
+// templateReport is the data the template of a TemplateOutput is executed
+// with. Modules are sorted by path and version.
+type templateReport struct {
+	Modules []reportModule
+
+	// The number of modules by their allowed state
+	Allowed int
+	Denied  int
+	Unknown int
+}
+
+// templateFuncs are the functions available to the template of a
+// TemplateOutput in addition to the built-in ones.
+var templateFuncs = template.FuncMap{
+	// spdx returns the SPDX ID of the license of the module, or
+	// NOASSERTION if it is unknown.
+	"spdx": func(rm reportModule) string {
+		if rm.SPDX == "" {
+			return spdxNoAssertion
+		}
+
+		return rm.SPDX
+	},
+
+	// allowed and denied return whether the license of the module is
+	// allowed or denied by the configuration.
+	"allowed": func(rm reportModule) bool { return rm.Allowed == "yes" },
+	"denied":  func(rm reportModule) bool { return rm.Allowed == "no" },
+
+	// status returns "allowed", "denied" or "unknown".
+	"status": func(rm reportModule) string { return templateStatus[rm.Allowed] },
+
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// templateStatus is the status of each allowed state of a reportModule.
+var templateStatus = map[string]string{
+	"yes":     "allowed",
+	"no":      "denied",
+	"unknown": "unknown",
+}
+
+// NewTemplateOutput parses the template at tmplPath for a TemplateOutput
+// writing to path. The template is parsed up front so that an invalid
+// template is reported before any license is looked up.
+func NewTemplateOutput(tmplPath, path string, c *config.Config) (*TemplateOutput, error) {
+	text, err := ioutil.ReadFile(tmplPath)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(filepath.Base(tmplPath)).Funcs(templateFuncs).Parse(string(text))
+	if err != nil {
+		return nil, err
+	}
+
+	return &TemplateOutput{Path: path, Config: c, tmpl: tmpl}, nil
+}
+
+// parseTemplateFlag splits the value of -out-template into the path of
+// the template and the path to write the report to, separated by a colon.
+// A Windows volume name at the start of the template path is skipped.
+func parseTemplateFlag(v string) (string, string, error) {
+	vol := filepath.VolumeName(v)
+	i := strings.Index(v[len(vol):], ":")
+	if i < 0 {
+		return "", "", fmt.Errorf("-out-template %q must be TEMPLATE:OUT", v)
+	}
+
+	tmplPath, path := v[:len(vol)+i], v[len(vol)+i+1:]
+	if tmplPath == "" || path == "" {
+		return "", "", fmt.Errorf("-out-template %q must be TEMPLATE:OUT", v)
+	}
+
+	return tmplPath, path, nil
+}
+
+// Flush implements ReportOutput
+func (o *TemplateOutput) Flush(results []Result) error {
+	report := templateReport{Modules: reportModules(results, o.Config, o.ShowHashes)}
+	for _, rm := range report.Modules {
+		switch rm.Allowed {
+		case "yes":
+			report.Allowed++
+		case "no":
+			report.Denied++
+		default:
+			report.Unknown++
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := o.tmpl.Execute(&buf, report); err != nil {
+		return err
+	}
+
+	if o.Path == "-" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+
+	return ioutil.WriteFile(o.Path, buf.Bytes(), 0644)
+}
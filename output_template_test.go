@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateOutput(t *testing.T) {
+	dir := t.TempDir()
+	tmplPath := filepath.Join(dir, "report.tmpl")
+	require.NoError(t, ioutil.WriteFile(tmplPath, []byte(
+		`{{range .Modules}}{{.Path}}@{{.Version}} {{spdx .}} {{status . | upper}}{{if allowed .}} ok{{end}}{{if .Error}} ({{.Error}}){{end}}
+{{end}}{{.Allowed}}/{{.Denied}}/{{.Unknown}}
+`), 0644))
+
+	path := filepath.Join(dir, "report.txt")
+	out, err := NewTemplateOutput(tmplPath, path, &config.Config{
+		Allow: []string{"MIT"},
+		Deny:  []string{"GPL-3.0"},
+	})
+	require.NoError(t, err)
+
+	store := &ResultStore{Reports: []ReportOutput{out}}
+	store.Finish(&module.Module{Path: "github.com/foo/mit", Version: "v1.0.0"},
+		&license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/gpl", Version: "v0.2.0"},
+		&license.License{Name: "GNU General Public License v3.0", SPDX: "GPL-3.0"}, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/custom", Version: "v2.0.0"},
+		&license.License{Name: "Custom License"}, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/missing", Version: "v1.1.0"},
+		nil, errors.New("not found"))
+	require.NoError(t, store.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, `github.com/foo/custom@v2.0.0 NOASSERTION DENIED
+github.com/foo/gpl@v0.2.0 GPL-3.0 DENIED
+github.com/foo/missing@v1.1.0 NOASSERTION DENIED (not found)
+github.com/foo/mit@v1.0.0 MIT ALLOWED ok
+1/3/0
+`, string(data))
+}
+
+func TestNewTemplateOutput_invalid(t *testing.T) {
+	tmplPath := filepath.Join(t.TempDir(), "report.tmpl")
+	require.NoError(t, ioutil.WriteFile(tmplPath, []byte(`{{range .Modules}}`), 0644))
+
+	_, err := NewTemplateOutput(tmplPath, "-", nil)
+	require.Error(t, err)
+}
+
+func TestParseTemplateFlag(t *testing.T) {
+	cases := []struct {
+		Value string
+		Tmpl  string
+		Path  string
+		Err   bool
+	}{
+		{"report.tmpl:report.txt", "report.tmpl", "report.txt", false},
+		{"report.tmpl:-", "report.tmpl", "-", false},
+		{"report.tmpl", "", "", true},
+		{":report.txt", "", "", true},
+		{"report.tmpl:", "", "", true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Value, func(t *testing.T) {
+			tmpl, path, err := parseTemplateFlag(tt.Value)
+			require.Equal(t, tt.Err, err != nil)
+			require.Equal(t, tt.Tmpl, tmpl)
+			require.Equal(t, tt.Path, path)
+		})
+	}
+}
@@ -39,23 +39,171 @@ type TermOutput struct {
 	// is not a TTY.
 	Plain bool
 
-	// Verbose will log all status updates in plain mode. This has no effect
-	// in non-plain mode currently.
-	Verbose bool
+	// Log, if set, receives all status updates at debug level, in both
+	// plain and live mode.
+	Log *Logger
+
+	// RequireSPDX, if true, treats any license without an SPDX ID as a
+	// failure.
+	RequireSPDX bool
+
+	// StrictSPDX, if true, also treats licenses whose SPDX ID isn't a
+	// canonical SPDX ID or expression as a failure, see
+	// license.IsCanonicalSPDX. This implies RequireSPDX.
+	StrictSPDX bool
+
+	// FailUnknown, if true, fails the run with ExitCodeUnknown if no
+	// license was found for a module or its lookup failed, even without a
+	// configuration.
+	FailUnknown bool
+
+	// ShowHashes, if true, adds the module hash to each module, such as
+	// "(hash: h1:abcd1234)", for checking it against go.sum by hand.
+	ShowHashes bool
+
+	// Quiet, if true, only outputs the modules that fail the check and
+	// the summary if any did. This implies Plain.
+	Quiet bool
+
+	// SummaryOnly, if true, outputs no modules at all and only a single
+	// line with the verdict when the output is closed, such as
+	// "PASS: 142 modules, all allowed". It takes precedence over Quiet and
+	// implies Plain.
+	SummaryOnly bool
+
+	// color is true if the live output is colored, see useColor.
+	color bool
 
 	modules   map[string]string
 	moduleMax int
 	exitCode  int
+	started   int
+	summary   termSummary
+	noSPDX    int            // licenses without an SPDX ID with RequireSPDX or StrictSPDX
+	licenses  map[string]int // modules by license, see licenseKey
 	lineMax   int
 	live      *uilive.Writer
 	once      sync.Once
 	lock      sync.Mutex
 }
 
+const (
+	// ExitCodePolicy is the exit code if a license is denied, isn't in
+	// the allow list, or has no SPDX ID with RequireSPDX or StrictSPDX.
+	ExitCodePolicy = 1
+
+	// ExitCodeUnknown is the exit code with FailUnknown if no license
+	// was found for a module or its lookup failed.
+	ExitCodeUnknown = 2
+
+	// ExitCodeUsage is the exit code if the flags, arguments,
+	// configuration or input files are invalid, or the check couldn't be
+	// completed, so the results can't be trusted.
+	ExitCodeUsage = 3
+
+	// ExitCodeSumMismatch is the exit code if a module hash doesn't match
+	// the go.sum file given with -verify-sum or the hash in the cache.
+	ExitCodeSumMismatch = 4
+)
+
+// termSummary is the tally of finished modules by their allowed state.
+type termSummary struct {
+	Allowed int
+	Denied  int
+	Unknown int
+	Failed  int // lookup errors
+	Skipped int // lookup errors of a skipped finder, also in Failed
+}
+
+// Total returns the number of finished modules.
+func (s termSummary) Total() int {
+	return s.Allowed + s.Denied + s.Unknown + s.Failed
+}
+
+// String returns the summary line printed when the output is closed.
+func (s termSummary) String() string {
+	result := fmt.Sprintf("%d modules: %d allowed, %d denied, %d unknown, %d failed",
+		s.Total(), s.Allowed, s.Denied, s.Unknown, s.Failed)
+	if s.Skipped > 0 {
+		result += fmt.Sprintf(" (%d with a finder unavailable)", s.Skipped)
+	}
+
+	return result
+}
+
+// termLicenseCount is the number of modules with a license.
+type termLicenseCount struct {
+	License string
+	Count   int
+}
+
+// Licenses returns the number of modules finished so far with each license,
+// by SPDX ID if known, with the most used license first. Failed lookups
+// aren't included.
+func (o *TermOutput) Licenses() []termLicenseCount {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return o.licenseCounts()
+}
+
+// licenseCounts implements Licenses.
+//
+// lock must be held.
+func (o *TermOutput) licenseCounts() []termLicenseCount {
+	result := make([]termLicenseCount, 0, len(o.licenses))
+	for l, n := range o.licenses {
+		result = append(result, termLicenseCount{License: l, Count: n})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+
+		return result[i].License < result[j].License
+	})
+
+	return result
+}
+
+// Summary returns the counts of the modules finished so far.
+func (o *TermOutput) Summary() termSummary {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return o.summary
+}
+
+// ExitCode returns the exit code for the results. If there are multiple
+// problems, a usage error takes priority over a module hash mismatch,
+// which takes priority over a policy violation, which takes priority over
+// an unknown license.
 func (o *TermOutput) ExitCode() int {
+	o.lock.Lock()
+	defer o.lock.Unlock()
 	return o.exitCode
 }
 
+// SumMismatch records that a module hash doesn't match the go.sum file
+// given with -verify-sum, which fails the run with ExitCodeSumMismatch.
+func (o *TermOutput) SumMismatch() {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.setExitCode(ExitCodeSumMismatch)
+}
+
+// setExitCode sets the exit code unless one with a higher priority is
+// already set. lock must be held.
+func (o *TermOutput) setExitCode(code int) {
+	priority := map[int]int{
+		ExitCodeUnknown:     1,
+		ExitCodePolicy:      2,
+		ExitCodeSumMismatch: 3,
+		ExitCodeUsage:       4,
+	}
+	if priority[code] > priority[o.exitCode] {
+		o.exitCode = code
+	}
+}
+
 // Start implements Output
 func (o *TermOutput) Start(m *module.Module) {
 	o.once.Do(o.init)
@@ -66,6 +214,7 @@ func (o *TermOutput) Start(m *module.Module) {
 
 	o.lock.Lock()
 	defer o.lock.Unlock()
+	o.started++
 	o.modules[m.Path] = fmt.Sprintf("%s %s starting...", iconNormal, o.paddedModule(m))
 	o.updateLiveOutput()
 }
@@ -74,12 +223,8 @@ func (o *TermOutput) Start(m *module.Module) {
 func (o *TermOutput) Update(m *module.Module, t license.StatusType, msg string) {
 	o.once.Do(o.init)
 
-	// In plain & verbose mode, we output every status message, but in normal
-	// plain mode we ignore all status updates.
-	if o.Plain && o.Verbose {
-		fmt.Fprintf(o.Out,
-			"%s %s\n", o.paddedModule(m), msg)
-	}
+	// Every status message is logged, but plain mode ignores them otherwise
+	o.Log.Debugf("%s: %s", m.String(), msg)
 
 	if o.Plain {
 		return
@@ -90,11 +235,11 @@ func (o *TermOutput) Update(m *module.Module, t license.StatusType, msg string)
 	switch t {
 	case license.StatusWarning:
 		icon = iconWarning
-		colorFunc = color.YellowString
+		colorFunc = o.colorString(color.FgYellow)
 
 	case license.StatusError:
 		icon = iconError
-		colorFunc = color.RedString
+		colorFunc = o.colorString(color.FgRed)
 	}
 	if icon != "" {
 		icon += " "
@@ -110,51 +255,130 @@ func (o *TermOutput) Update(m *module.Module, t license.StatusType, msg string)
 func (o *TermOutput) Finish(m *module.Module, l *license.License, err error) {
 	o.once.Do(o.init)
 
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o.count(m, l, err)
+
 	var colorFunc func(string, ...interface{}) string = fmt.Sprintf
 	icon := iconNormal
+	failed := true
+	state := config.StateUnknown
 	if o.Config != nil {
-		state := o.Config.Allowed(l)
-		switch state {
-		case config.StateAllowed:
-			colorFunc = color.GreenString
-			icon = iconSuccess
-
-		case config.StateDenied:
-			colorFunc = color.RedString
-			icon = iconError
-			o.exitCode = 1
-
-		case config.StateUnknown:
-			if len(o.Config.Allow) > 0 || len(o.Config.Deny) > 0 {
-				colorFunc = color.YellowString
-				icon = iconWarning
-				o.exitCode = 1
-			}
-		}
+		state = o.Config.AllowedModule(m, l)
+	}
+	switch {
+	case state == config.StateAllowed:
+		colorFunc = o.colorString(color.FgGreen)
+		icon = iconSuccess
+		failed = false
+
+	case l == nil && o.FailUnknown:
+		// No license was found or the lookup failed, so we don't know if
+		// it would be denied
+		colorFunc = o.colorString(color.FgRed)
+		icon = iconError
+		o.setExitCode(ExitCodeUnknown)
+
+	case state == config.StateDenied:
+		colorFunc = o.colorString(color.FgRed)
+		icon = iconError
+		o.setExitCode(ExitCodePolicy)
+
+	case state == config.StateNotAllowed:
+		colorFunc = o.colorString(color.FgYellow)
+		icon = iconWarning
+		o.setExitCode(ExitCodePolicy)
+
+	default:
+		failed = false
+	}
+	if l != nil && ((o.RequireSPDX && l.SPDX == "") || (o.StrictSPDX && !license.IsCanonicalSPDX(l.SPDX))) {
+		colorFunc = o.colorString(color.FgRed)
+		icon = iconError
+		failed = true
+		o.noSPDX++
+		o.setExitCode(ExitCodePolicy)
+	}
+	if o.SummaryOnly || (o.Quiet && !failed) {
+		return
 	}
 	if icon != "" {
 		icon += " "
 	}
 
+	result := l.String()
+	if o.Config != nil && o.Config.Exceptions[m.Path] != "" {
+		result += fmt.Sprintf(" (exception: %s)", o.Config.Exceptions[m.Path])
+	}
+	if m.Private {
+		result += " (internal)"
+	}
+	if l != nil && len(l.Conflicts) > 0 {
+		result += fmt.Sprintf(" (conflicts with %s)", strings.Join(l.Conflicts, ", "))
+	}
+	if o.ShowHashes && m.Hash != "" {
+		result += fmt.Sprintf(" (hash: %s)", m.Hash)
+	}
+	if m.Latest != "" {
+		result += fmt.Sprintf(" (update available: %s)", m.Latest)
+	}
+	if m.Stale {
+		result += fmt.Sprintf(" (stale: published %s)", m.Time.Format("2006-01-02"))
+	}
+	if names := license.UnavailableFinders(err); l == nil && len(names) > 0 {
+		result += fmt.Sprintf(" (skipped: %s unavailable)", strings.Join(names, ", "))
+	}
+
 	if o.Plain {
 		fmt.Fprintf(o.Out,
-			"%s %s\n", o.paddedModule(m), l.String())
+			"%s %s\n", o.paddedModule(m), result)
 		return
 	}
 
-	o.lock.Lock()
-	defer o.lock.Unlock()
 	delete(o.modules, m.Path)
 	o.pauseLive(func() {
-		_, err := o.live.Write([]byte(colorFunc(
-			"%s%s %s\n", icon, o.paddedModule(m), l.String())))
-		if err != nil {
-			fmt.Println("failed write")
-			os.Exit(1)
-		}
+		o.writeLive([]byte(colorFunc(
+			"%s%s %s\n", icon, o.paddedModule(m), result)))
 	})
 }
 
+// count adds the result of a lookup to the summary. Without a
+// configuration every module is unknown, including those without a
+// license, unless its lookup failed.
+//
+// lock must be held.
+func (o *TermOutput) count(m *module.Module, l *license.License, err error) {
+	state := config.StateUnknown
+	if o.Config != nil {
+		state = o.Config.AllowedModule(m, l)
+	}
+	if l == nil && err != nil && state != config.StateAllowed {
+		o.summary.Failed++
+		if len(license.UnavailableFinders(err)) > 0 {
+			o.summary.Skipped++
+		}
+		return
+	}
+
+	if o.licenses == nil {
+		o.licenses = map[string]int{}
+	}
+	o.licenses[licenseKey(l)]++
+
+	switch state {
+	case config.StateAllowed:
+		o.summary.Allowed++
+
+	case config.StateDenied:
+		o.summary.Denied++
+
+	default:
+		// Licenses missing from the allow list are unknown too
+		o.summary.Unknown++
+	}
+}
+
 // Close implements Output
 func (o *TermOutput) Close() error {
 	o.lock.Lock()
@@ -164,7 +388,96 @@ func (o *TermOutput) Close() error {
 		o.live.Stop()
 	}
 
-	return nil
+	if o.SummaryOnly {
+		_, err := fmt.Fprintln(o.Out, o.verdict())
+		return err
+	}
+
+	// The summary is always printed, even in plain mode, but quiet mode
+	// stays silent unless something failed.
+	if o.Quiet && o.exitCode == 0 {
+		return nil
+	}
+
+	// The modules grouped by license come first, except in quiet mode
+	// which only shows what failed.
+	var buf bytes.Buffer
+	if !o.Quiet && len(o.licenses) > 0 {
+		licenses := o.licenseCounts()
+
+		width := 0
+		for _, c := range licenses {
+			if len(c.License) > width {
+				width = len(c.License)
+			}
+		}
+
+		buf.WriteString("\nLicenses:\n")
+		for _, c := range licenses {
+			fmt.Fprintf(&buf, "  %-*s %d\n", width, c.License, c.Count)
+		}
+	}
+
+	fmt.Fprintf(&buf, "\n%s\n", o.summary)
+	_, err := o.Out.Write(buf.Bytes())
+	return err
+}
+
+// verdict returns the line printed with SummaryOnly: whether the check
+// passed followed by the counts that matter for it.
+//
+// lock must be held.
+func (o *TermOutput) verdict() string {
+	s := o.summary
+	if o.exitCode == 0 {
+		if s.Allowed == s.Total() {
+			return fmt.Sprintf("PASS: %d modules, all allowed", s.Total())
+		}
+
+		// Failed lookups only pass without a configuration
+		result := fmt.Sprintf("PASS: %d modules, %d allowed, %d unknown",
+			s.Total(), s.Allowed, s.Unknown)
+		if s.Failed > 0 {
+			result += fmt.Sprintf(", %d failed", s.Failed)
+		}
+
+		return result
+	}
+
+	var counts []string
+	for _, c := range []struct {
+		n    int
+		text string
+	}{
+		{s.Denied, "denied"},
+		{s.Unknown, "unknown"},
+		{s.Failed, "failed"},
+		{o.noSPDX, "without SPDX ID"},
+	} {
+		if c.n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", c.n, c.text))
+		}
+	}
+	if len(counts) == 0 {
+		counts = append(counts, fmt.Sprintf("%d modules", s.Total()))
+	}
+
+	return "FAIL: " + strings.Join(counts, ", ")
+}
+
+// licenseKey returns the license that a module is grouped by in the
+// summary: the SPDX ID if known, otherwise the license name.
+func licenseKey(l *license.License) string {
+	switch {
+	case l == nil:
+		return "unknown"
+
+	case l.SPDX != "":
+		return l.SPDX
+
+	default:
+		return l.Name
+	}
 }
 
 // paddedModule returns the name of the module padded so that they align nicely.
@@ -183,11 +496,7 @@ func (o *TermOutput) paddedModule(m *module.Module) string {
 //
 // lock must be held.
 func (o *TermOutput) pauseLive(f func()) {
-	_, err := o.live.Write([]byte(strings.Repeat(" ", o.lineMax) + "\n"))
-	if err != nil {
-		fmt.Println("failed write")
-		os.Exit(1)
-	}
+	o.writeLive([]byte(strings.Repeat(" ", o.lineMax) + "\n"))
 	o.live.Flush()
 	f()
 	o.live.Flush()
@@ -214,14 +523,39 @@ func (o *TermOutput) updateLiveOutput() {
 
 		buf.WriteString(o.modules[k] + strings.Repeat(" ", o.lineMax-len(o.modules[k])) + "\n")
 	}
+	buf.WriteString(o.progress() + "\n")
+
+	o.writeLive(buf.Bytes())
+	o.live.Flush()
+}
+
+// writeLive writes to the live output. A failed write can't be shown on
+// the terminal, so it is logged and fails the run with ExitCodeUsage.
+//
+// lock must be held.
+func (o *TermOutput) writeLive(p []byte) {
+	if _, err := o.live.Write(p); err != nil {
+		o.Log.Errorf("failed to write output: %s", err)
+		o.setExitCode(ExitCodeUsage)
+	}
+}
 
-	_, err := o.live.Write(buf.Bytes())
-	if err != nil {
-		fmt.Println("failed write")
-		os.Exit(1)
+// progress returns the line showing how many modules are resolved. If the
+// modules aren't given in advance, the total is the number started so far.
+//
+// lock must be held.
+func (o *TermOutput) progress() string {
+	total := len(o.Modules)
+	if total < o.started {
+		total = o.started
 	}
 
-	o.live.Flush()
+	line := fmt.Sprintf("%d of %d modules resolved", o.summary.Total(), total)
+	if len(line) < o.lineMax {
+		line += strings.Repeat(" ", o.lineMax-len(line))
+	}
+
+	return line
 }
 
 func (o *TermOutput) newLive() {
@@ -242,6 +576,11 @@ func (o *TermOutput) init() {
 		}
 	}
 
+	// Quiet mode has no live updates to hide the progress
+	if o.Quiet || o.SummaryOnly {
+		o.Plain = true
+	}
+
 	// Check if the output is a TTY
 	if !o.Plain {
 		o.Plain = true // default to plain mode unless we can verify TTY
@@ -250,11 +589,36 @@ func (o *TermOutput) init() {
 		}
 
 		if !o.Plain {
+			o.color = useColor(o.Out)
 			o.newLive()
 		}
 	}
 }
 
+// useColor returns true if colors should be written to w, which is only
+// the case for a terminal and if NO_COLOR isn't set (https://no-color.org).
+func useColor(w io.Writer) bool {
+	if os.Getenv(EnvNoColor) != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	f, ok := w.(ioFd)
+	return ok && terminal.IsTerminal(int(f.Fd()))
+}
+
+// colorString returns a function like color.RedString that only colors
+// the text if the output is colored. Unlike color.RedString, this doesn't
+// depend on color.NoColor, which is only set for stdout.
+func (o *TermOutput) colorString(attr color.Attribute) func(string, ...interface{}) string {
+	if !o.color {
+		return fmt.Sprintf
+	}
+
+	c := color.New(attr)
+	c.EnableColor()
+	return c.SprintfFunc()
+}
+
 // ioFd is an interface that is implemented by things that have a file
 // descriptor. We use this to check if the io.Writer is a TTY.
 type ioFd interface {
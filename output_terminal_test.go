@@ -0,0 +1,572 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTermOutput_exitCode(t *testing.T) {
+	mit := &license.License{Name: "MIT License", SPDX: "MIT"}
+	gpl := &license.License{Name: "GNU General Public License v3.0", SPDX: "GPL-3.0"}
+	apache := &license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0"}
+
+	type result struct {
+		Lic *license.License
+		Err error
+	}
+
+	cases := []struct {
+		Name        string
+		Results     []result
+		FailUnknown bool
+		SumMismatch bool
+		Expected    int
+	}{
+		{
+			"all allowed",
+			[]result{{mit, nil}},
+			false,
+			false,
+			0,
+		},
+
+		{
+			"denied spdx",
+			[]result{{mit, nil}, {gpl, nil}},
+			false,
+			false,
+			ExitCodePolicy,
+		},
+
+		{
+			"not in allow list",
+			[]result{{mit, nil}, {apache, nil}},
+			false,
+			false,
+			ExitCodePolicy,
+		},
+
+		{
+			"lookup error is denied",
+			[]result{{mit, nil}, {nil, errors.New("rate limited")}},
+			false,
+			false,
+			ExitCodePolicy,
+		},
+
+		{
+			"no license is denied",
+			[]result{{mit, nil}, {nil, nil}},
+			false,
+			false,
+			ExitCodePolicy,
+		},
+
+		{
+			"lookup error with fail unknown",
+			[]result{{mit, nil}, {nil, errors.New("rate limited")}},
+			true,
+			false,
+			ExitCodeUnknown,
+		},
+
+		{
+			"no license with fail unknown",
+			[]result{{mit, nil}, {nil, nil}},
+			true,
+			false,
+			ExitCodeUnknown,
+		},
+
+		{
+			"policy takes priority over unknown",
+			[]result{{nil, errors.New("rate limited")}, {gpl, nil}, {apache, nil}},
+			true,
+			false,
+			ExitCodePolicy,
+		},
+
+		{
+			"sum mismatch",
+			[]result{{mit, nil}},
+			false,
+			true,
+			ExitCodeSumMismatch,
+		},
+
+		{
+			"sum mismatch takes priority",
+			[]result{{gpl, nil}, {nil, errors.New("rate limited")}},
+			true,
+			true,
+			ExitCodeSumMismatch,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			out := &TermOutput{
+				Out:   new(bytes.Buffer),
+				Plain: true,
+				Config: &config.Config{
+					Allow: []string{"MIT"},
+					Deny:  []string{"GPL-3.0"},
+				},
+				FailUnknown: tt.FailUnknown,
+			}
+
+			for _, r := range tt.Results {
+				out.Finish(&module.Module{Path: "github.com/foo/bar"}, r.Lic, r.Err)
+			}
+			if tt.SumMismatch {
+				out.SumMismatch()
+			}
+
+			require.Equal(t, tt.Expected, out.ExitCode())
+		})
+	}
+}
+
+func TestTermOutput_exitCodeNoConfig(t *testing.T) {
+	mit := &license.License{Name: "MIT License", SPDX: "MIT"}
+
+	cases := []struct {
+		Name        string
+		Lic         *license.License
+		Err         error
+		FailUnknown bool
+		Expected    int
+	}{
+		{"license", mit, nil, true, 0},
+		{"no license", nil, nil, false, 0},
+		{"no license with fail unknown", nil, nil, true, ExitCodeUnknown},
+		{"lookup error", nil, errors.New("rate limited"), false, 0},
+		{"lookup error with fail unknown", nil, errors.New("rate limited"), true, ExitCodeUnknown},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			out := &TermOutput{
+				Out:         new(bytes.Buffer),
+				Plain:       true,
+				FailUnknown: tt.FailUnknown,
+			}
+			out.Finish(&module.Module{Path: "github.com/foo/bar"}, tt.Lic, tt.Err)
+			require.Equal(t, tt.Expected, out.ExitCode())
+		})
+	}
+}
+
+func TestTermOutput_exitCodePriority(t *testing.T) {
+	// -list-exit-codes lists the codes in order of priority
+	for i, c := range exitCodes {
+		code, err := strconv.Atoi(c.Name)
+		require.NoError(t, err)
+
+		for _, lower := range exitCodes[i+1:] {
+			lowerCode, err := strconv.Atoi(lower.Name)
+			require.NoError(t, err)
+
+			out := &TermOutput{}
+			out.setExitCode(lowerCode)
+			out.setExitCode(code)
+			out.setExitCode(lowerCode)
+			require.Equal(t, code, out.ExitCode(), "%d over %d", code, lowerCode)
+		}
+	}
+}
+
+func TestTermOutput_exitCodeLists(t *testing.T) {
+	mit := &license.License{Name: "MIT License", SPDX: "MIT"}
+	gpl := &license.License{Name: "GNU General Public License v3.0", SPDX: "GPL-3.0"}
+	apache := &license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0"}
+	custom := &license.License{Name: "Custom License"}
+
+	cases := []struct {
+		Name     string
+		Config   *config.Config
+		Lic      *license.License
+		Expected int
+	}{
+		{"allow only, allowed", &config.Config{Allow: []string{"MIT"}}, mit, 0},
+		{"allow only, not listed", &config.Config{Allow: []string{"MIT"}}, apache, ExitCodePolicy},
+		{"allow only, unknown license", &config.Config{Allow: []string{"MIT"}}, custom, ExitCodePolicy},
+		{"deny only, denied", &config.Config{Deny: []string{"GPL-3.0"}}, gpl, ExitCodePolicy},
+		{"deny only, not listed", &config.Config{Deny: []string{"GPL-3.0"}}, apache, ExitCodePolicy},
+		{"deny only, unknown license", &config.Config{Deny: []string{"GPL-3.0"}}, custom, ExitCodePolicy},
+		{"no lists", &config.Config{}, apache, 0},
+		{"combined, deny wins", &config.Config{Allow: []string{"MIT"}, Deny: []string{"MIT"}}, mit, ExitCodePolicy},
+		{"combined, not listed", &config.Config{Allow: []string{"MIT"}, Deny: []string{"GPL-3.0"}}, apache, ExitCodePolicy},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			out := &TermOutput{
+				Out:    new(bytes.Buffer),
+				Plain:  true,
+				Config: tt.Config,
+			}
+
+			out.Finish(&module.Module{Path: "github.com/foo/bar"}, tt.Lic, nil)
+			require.Equal(t, tt.Expected, out.ExitCode())
+		})
+	}
+}
+
+func TestTermOutput_summary(t *testing.T) {
+	mit := &license.License{Name: "MIT License", SPDX: "MIT"}
+	gpl := &license.License{Name: "GNU General Public License v3.0", SPDX: "GPL-3.0"}
+	apache := &license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0"}
+
+	var buf bytes.Buffer
+	out := &TermOutput{
+		Out:   &buf,
+		Plain: true,
+		Config: &config.Config{
+			Allow: []string{"MIT"},
+			Deny:  []string{"GPL-3.0"},
+		},
+	}
+
+	results := []struct {
+		Lic *license.License
+		Err error
+	}{
+		{mit, nil},
+		{mit, nil},
+		{gpl, nil},
+		{apache, nil},
+		{nil, nil},
+		{nil, errors.New("rate limited")},
+	}
+	for _, r := range results {
+		m := &module.Module{Path: "github.com/foo/bar"}
+		out.Start(m)
+		out.Finish(m, r.Lic, r.Err)
+	}
+	require.NoError(t, out.Close())
+
+	require.Equal(t, termSummary{
+		Allowed: 2,
+		Denied:  2, // no license is never allowed
+		Unknown: 1,
+		Failed:  1,
+	}, out.Summary())
+	require.Contains(t, buf.String(),
+		"\n6 modules: 2 allowed, 2 denied, 1 unknown, 1 failed\n")
+}
+
+func TestTermOutput_licenses(t *testing.T) {
+	var buf bytes.Buffer
+	out := &TermOutput{
+		Out:    &buf,
+		Plain:  true,
+		Config: &config.Config{Allow: []string{"MIT"}},
+	}
+
+	results := []struct {
+		Lic *license.License
+		Err error
+	}{
+		{&license.License{Name: "MIT License", SPDX: "MIT"}, nil},
+		{&license.License{Name: "The MIT License", SPDX: "MIT"}, nil},
+		{&license.License{Name: "MIT License", SPDX: "MIT"}, nil},
+		{&license.License{Name: "GNU General Public License v3.0", SPDX: "GPL-3.0"}, nil},
+		{&license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0"}, nil},
+		{&license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0"}, nil},
+		{&license.License{Name: "Custom License"}, nil},
+		{nil, nil},
+		{nil, errors.New("rate limited")},
+	}
+	for i, r := range results {
+		m := &module.Module{Path: fmt.Sprintf("github.com/foo/bar%d", i)}
+		out.Finish(m, r.Lic, r.Err)
+	}
+	require.NoError(t, out.Close())
+
+	// Grouped by SPDX ID, most used first, without the failed lookup
+	require.Equal(t, []termLicenseCount{
+		{"MIT", 3},
+		{"Apache-2.0", 2},
+		{"Custom License", 1},
+		{"GPL-3.0", 1},
+		{"unknown", 1},
+	}, out.Licenses())
+	require.Contains(t, buf.String(), "\nLicenses:\n"+
+		"  MIT            3\n"+
+		"  Apache-2.0     2\n"+
+		"  Custom License 1\n"+
+		"  GPL-3.0        1\n"+
+		"  unknown        1\n"+
+		"\n9 modules: ")
+}
+
+func TestTermOutput_progress(t *testing.T) {
+	out := &TermOutput{
+		Out:   new(bytes.Buffer),
+		Plain: true,
+		Modules: []module.Module{
+			{Path: "github.com/foo/bar"},
+			{Path: "github.com/foo/baz"},
+			{Path: "github.com/foo/qux"},
+		},
+	}
+
+	out.Finish(&out.Modules[0], &license.License{SPDX: "MIT"}, nil)
+	require.Equal(t, "1 of 3 modules resolved", out.progress())
+}
+
+func TestTermOutput_summaryNoConfig(t *testing.T) {
+	var buf bytes.Buffer
+	out := &TermOutput{Out: &buf, Plain: true}
+
+	out.Finish(&module.Module{Path: "github.com/foo/bar"}, &license.License{SPDX: "MIT"}, nil)
+	out.Finish(&module.Module{Path: "github.com/foo/baz"}, nil, nil)
+	out.Finish(&module.Module{Path: "github.com/foo/missing"}, nil, errors.New("rate limited"))
+	require.NoError(t, out.Close())
+
+	// Nothing is denied without a configuration, not even a module
+	// without a license
+	require.Equal(t, 0, out.ExitCode())
+	require.Equal(t, termSummary{Unknown: 2, Failed: 1}, out.Summary())
+	require.Contains(t, buf.String(), "3 modules: 0 allowed, 0 denied, 2 unknown, 1 failed")
+}
+
+func TestTermOutput_quiet(t *testing.T) {
+	cfg := &config.Config{Allow: []string{"MIT"}, Deny: []string{"GPL-3.0"}}
+
+	t.Run("all allowed", func(t *testing.T) {
+		var buf bytes.Buffer
+		out := &TermOutput{Out: &buf, Config: cfg, Quiet: true}
+
+		for _, p := range []string{"github.com/foo/bar", "github.com/foo/baz"} {
+			m := &module.Module{Path: p}
+			out.Start(m)
+			out.Update(m, license.StatusNormal, "looking up")
+			out.Finish(m, &license.License{SPDX: "MIT"}, nil)
+		}
+		require.NoError(t, out.Close())
+
+		require.Equal(t, 0, out.ExitCode())
+		require.Empty(t, buf.String())
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		var buf bytes.Buffer
+		out := &TermOutput{Out: &buf, Config: cfg, Quiet: true}
+
+		out.Finish(&module.Module{Path: "github.com/foo/bar"}, &license.License{SPDX: "MIT"}, nil)
+		out.Finish(&module.Module{Path: "github.com/foo/baz"}, &license.License{SPDX: "GPL-3.0"}, nil)
+		require.NoError(t, out.Close())
+
+		require.Equal(t, ExitCodePolicy, out.ExitCode())
+		require.NotContains(t, buf.String(), "github.com/foo/bar")
+		require.Contains(t, buf.String(), "github.com/foo/baz")
+		require.Contains(t, buf.String(), "2 modules: 1 allowed, 1 denied")
+	})
+}
+
+func TestTermOutput_summaryOnly(t *testing.T) {
+	cfg := &config.Config{Allow: []string{"MIT"}, Deny: []string{"GPL-3.0"}}
+
+	t.Run("pass", func(t *testing.T) {
+		var buf bytes.Buffer
+		out := &TermOutput{Out: &buf, Config: cfg, SummaryOnly: true}
+
+		for _, p := range []string{"github.com/foo/bar", "github.com/foo/baz"} {
+			m := &module.Module{Path: p}
+			out.Start(m)
+			out.Update(m, license.StatusNormal, "looking up")
+			out.Finish(m, &license.License{SPDX: "MIT"}, nil)
+		}
+		require.NoError(t, out.Close())
+
+		require.Equal(t, 0, out.ExitCode())
+		require.Equal(t, "PASS: 2 modules, all allowed\n", buf.String())
+	})
+
+	t.Run("pass with unknown", func(t *testing.T) {
+		var buf bytes.Buffer
+		out := &TermOutput{Out: &buf, Config: &config.Config{}, SummaryOnly: true}
+
+		out.Finish(&module.Module{Path: "github.com/foo/bar"}, &license.License{SPDX: "MIT"}, nil)
+		require.NoError(t, out.Close())
+
+		require.Equal(t, 0, out.ExitCode())
+		require.Equal(t, "PASS: 1 modules, 0 allowed, 1 unknown\n", buf.String())
+	})
+
+	t.Run("pass without configuration", func(t *testing.T) {
+		var buf bytes.Buffer
+		out := &TermOutput{Out: &buf, SummaryOnly: true}
+
+		out.Finish(&module.Module{Path: "github.com/foo/bar"}, &license.License{SPDX: "MIT"}, nil)
+		out.Finish(&module.Module{Path: "github.com/foo/none"}, nil, nil)
+		out.Finish(&module.Module{Path: "github.com/foo/missing"}, nil, errors.New("not found"))
+		require.NoError(t, out.Close())
+
+		require.Equal(t, 0, out.ExitCode())
+		require.Equal(t, "PASS: 3 modules, 0 allowed, 2 unknown, 1 failed\n", buf.String())
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		var buf bytes.Buffer
+		out := &TermOutput{Out: &buf, Config: cfg, SummaryOnly: true, Quiet: true}
+
+		out.Finish(&module.Module{Path: "github.com/foo/bar"}, &license.License{SPDX: "MIT"}, nil)
+		out.Finish(&module.Module{Path: "github.com/foo/gpl1"}, &license.License{SPDX: "GPL-3.0"}, nil)
+		out.Finish(&module.Module{Path: "github.com/foo/gpl2"}, &license.License{SPDX: "GPL-3.0"}, nil)
+		out.Finish(&module.Module{Path: "github.com/foo/isc"}, &license.License{SPDX: "ISC"}, nil)
+		out.Finish(&module.Module{Path: "github.com/foo/missing"}, nil, errors.New("not found"))
+		require.NoError(t, out.Close())
+
+		require.Equal(t, ExitCodePolicy, out.ExitCode())
+		require.Equal(t, "FAIL: 2 denied, 1 unknown, 1 failed\n", buf.String())
+	})
+
+	t.Run("fail without SPDX ID", func(t *testing.T) {
+		var buf bytes.Buffer
+		out := &TermOutput{Out: &buf, Config: &config.Config{}, SummaryOnly: true, RequireSPDX: true}
+
+		out.Finish(&module.Module{Path: "github.com/foo/bar"}, &license.License{Name: "Custom"}, nil)
+		require.NoError(t, out.Close())
+
+		require.Equal(t, ExitCodePolicy, out.ExitCode())
+		require.Equal(t, "FAIL: 1 unknown, 1 without SPDX ID\n", buf.String())
+	})
+
+	t.Run("fail without canonical SPDX ID", func(t *testing.T) {
+		for _, strict := range []bool{false, true} {
+			var buf bytes.Buffer
+			out := &TermOutput{
+				Out:         &buf,
+				Config:      &config.Config{Allow: []string{"Custom", "MIT"}},
+				SummaryOnly: true,
+				StrictSPDX:  strict,
+			}
+
+			out.Finish(&module.Module{Path: "github.com/foo/bar"}, &license.License{Name: "Custom"}, nil)
+			out.Finish(&module.Module{Path: "github.com/foo/baz"}, &license.License{Name: "Custom", SPDX: "Custom"}, nil)
+			out.Finish(&module.Module{Path: "github.com/foo/mit"}, &license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+			require.NoError(t, out.Close())
+
+			if !strict {
+				require.Equal(t, 0, out.ExitCode())
+				require.Equal(t, "PASS: 3 modules, all allowed\n", buf.String())
+				continue
+			}
+			require.Equal(t, ExitCodePolicy, out.ExitCode())
+			require.Equal(t, "FAIL: 2 without SPDX ID\n", buf.String())
+		}
+	})
+}
+
+func TestTermOutput_exception(t *testing.T) {
+	var buf bytes.Buffer
+	out := &TermOutput{
+		Out:   &buf,
+		Plain: true,
+		Config: &config.Config{
+			Allow: []string{"MIT"},
+			Deny:  []string{"GPL-3.0"},
+			Exceptions: map[string]string{
+				"github.com/foo/gpl":     "Only used by internal tooling",
+				"github.com/foo/missing": "Vendored with the license attached",
+			},
+		},
+	}
+
+	out.Finish(&module.Module{Path: "github.com/foo/gpl"}, &license.License{SPDX: "GPL-3.0"}, nil)
+	out.Finish(&module.Module{Path: "github.com/foo/missing"}, nil, errors.New("not found"))
+	require.NoError(t, out.Close())
+
+	require.Equal(t, 0, out.ExitCode())
+	require.Equal(t, termSummary{Allowed: 2}, out.Summary())
+	require.Contains(t, buf.String(), "(exception: Only used by internal tooling)")
+	require.Contains(t, buf.String(), "(exception: Vendored with the license attached)")
+}
+
+func TestTermOutput_noColor(t *testing.T) {
+	// Force colors on for the package so that only the writer decides
+	old := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = old }()
+
+	var buf bytes.Buffer
+	out := &TermOutput{
+		Out: &buf,
+		Config: &config.Config{
+			Allow: []string{"MIT"},
+			Deny:  []string{"GPL-3.0"},
+		},
+	}
+
+	out.Start(&module.Module{Path: "github.com/foo/bar"})
+	out.Finish(&module.Module{Path: "github.com/foo/bar"}, &license.License{SPDX: "MIT"}, nil)
+	out.Finish(&module.Module{Path: "github.com/foo/baz"}, &license.License{SPDX: "GPL-3.0"}, nil)
+	out.Finish(&module.Module{Path: "github.com/foo/qux"}, nil, nil)
+	out.Finish(&module.Module{Path: "github.com/foo/err"}, nil, errors.New("boom"))
+	require.NoError(t, out.Close())
+
+	require.Contains(t, buf.String(), "github.com/foo/baz")
+	require.NotContains(t, buf.String(), "\x1b[")
+}
+
+func TestUseColor(t *testing.T) {
+	f, err := ioutil.TempFile("", "golicense")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	require.False(t, useColor(new(bytes.Buffer)))
+	require.False(t, useColor(f))
+
+	os.Setenv(EnvNoColor, "1")
+	defer os.Unsetenv(EnvNoColor)
+	require.False(t, useColor(os.Stdout))
+}
+
+func TestTermOutput_internal(t *testing.T) {
+	var buf bytes.Buffer
+	out := &TermOutput{Out: &buf, Plain: true, Config: &config.Config{AllowPrivate: true}}
+
+	out.Finish(&module.Module{Path: "git.corp.example.com/lib", Private: true}, nil, nil)
+	require.NoError(t, out.Close())
+
+	require.Contains(t, buf.String(), "git.corp.example.com/lib")
+	require.Contains(t, buf.String(), "(internal)")
+	require.Equal(t, termSummary{Allowed: 1}, out.Summary())
+	require.Equal(t, 0, out.ExitCode())
+}
+
+func TestTermOutput_unavailable(t *testing.T) {
+	var buf bytes.Buffer
+	out := &TermOutput{Out: &buf, Plain: true, Config: &config.Config{Allow: []string{"MIT"}}}
+
+	err := license.Transient(&license.UnavailableError{
+		Finder:   "github",
+		Failures: 5,
+		Err:      errors.New("502 Bad Gateway"),
+	})
+	out.Finish(&module.Module{Path: "github.com/foo/bar"}, nil, err)
+	out.Finish(&module.Module{Path: "github.com/foo/baz"}, nil, errors.New("rate limited"))
+	require.NoError(t, out.Close())
+
+	require.Contains(t, buf.String(), "(skipped: github unavailable)")
+	require.Equal(t, 1, strings.Count(buf.String(), "(skipped:"))
+	require.Equal(t, termSummary{Failed: 2, Skipped: 1}, out.Summary())
+	require.Contains(t, buf.String(),
+		"2 modules: 0 allowed, 0 denied, 0 unknown, 2 failed (1 with a finder unavailable)\n")
+}
@@ -4,15 +4,19 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
-	"sync"
 
-	"github.com/360EntSecGroup-Skylar/excelize"
 	"github.com/mitchellh/golicense/config"
 	"github.com/mitchellh/golicense/license"
 	"github.com/mitchellh/golicense/module"
+	"github.com/xuri/excelize/v2"
 )
 
-// XLSXOutput writes the results of license lookups to an XLSX file.
+// XLSXOutput writes the results of license lookups to an XLSX file. The
+// file has a Summary sheet with the number of modules per license, a
+// Details sheet with every module and a Violations sheet with only the
+// modules that aren't allowed. The sheets are written with a stream writer
+// a row at a time, which excelize moves to a temporary file once it gets
+// large, so the workbook isn't built in memory.
 type XLSXOutput struct {
 	// Path is the path to the file to write. This will be overwritten if
 	// it exists.
@@ -22,129 +26,196 @@ type XLSXOutput struct {
 	// if a license is allowed or not.
 	Config *config.Config
 
-	modules map[*module.Module]interface{}
-	lock    sync.Mutex
+	// ShowHashes, if true, adds a Hash column with the module hash, such
+	// as "h1:abcd1234", for checking the modules against go.sum.
+	ShowHashes bool
 }
 
-// Start implements Output
-func (o *XLSXOutput) Start(m *module.Module) {}
+// xlsxRow is a row of the Details and Violations sheets.
+type xlsxRow struct {
+	// Cells are the values of the columns, from Dependency to License URL
+	// or Hash.
+	Cells []interface{}
+	Style xlsxStyle
 
-// Update implements Output
-func (o *XLSXOutput) Update(m *module.Module, t license.StatusType, msg string) {}
+	// License is what the module is counted as on the Summary sheet, and
+	// Allowed is "yes", "no" or "unknown".
+	License string
+	Allowed string
+}
+
+// xlsxHeaders are the headers of the Details and Violations sheets. Hash
+// is added with ShowHashes.
+var xlsxHeaders = []interface{}{
+	"Dependency", "Version", "SPDX ID", "License", "Allowed", "Update Available", "Stale",
+	"Exception", "License URL",
+}
+
+// xlsxLicense is a row of the Summary sheet.
+type xlsxLicense struct {
+	License                           string
+	SPDX                              string
+	Modules, Allowed, Denied, Unknown int
+}
 
-// Finish implements Output
-func (o *XLSXOutput) Finish(m *module.Module, l *license.License, err error) {
-	o.lock.Lock()
-	defer o.lock.Unlock()
+// xlsxStyle is the background color of a row.
+type xlsxStyle int
 
-	if o.modules == nil {
-		o.modules = make(map[*module.Module]interface{})
+const (
+	xlsxYellow xlsxStyle = iota
+	xlsxRed
+	xlsxGreen
+)
+
+// row creates the row of a module with the result of its lookup.
+func (o *XLSXOutput) row(m *module.Module, l *license.License, err error) xlsxRow {
+	var spdx, url, exception string
+	if l != nil {
+		spdx = l.SPDX
+		url = l.URL
+	}
+	if o.Config != nil {
+		exception = o.Config.Exceptions[m.Path]
 	}
 
-	o.modules[m] = l
-	if err != nil {
-		o.modules[m] = err
+	lic, allowed, style := l.String(), "unknown", xlsxYellow
+	summary := lic
+	switch {
+	case err != nil:
+		// Note the error. The module is still allowed if it has an
+		// exception.
+		lic, allowed, style = fmt.Sprintf("ERROR: %s", err), "no", xlsxRed
+		summary = "<lookup failed>"
+		if o.Config != nil && o.Config.AllowedModule(m, nil) == config.StateAllowed {
+			allowed, style = "yes", xlsxGreen
+		}
+
+	case o.Config != nil:
+		switch o.Config.AllowedModule(m, l) {
+		case config.StateAllowed:
+			allowed, style = "yes", xlsxGreen
+
+		case config.StateDenied, config.StateNotAllowed:
+			allowed, style = "no", xlsxRed
+		}
+	}
+
+	stale := ""
+	if m.Stale {
+		stale = "published " + m.Time.Format("2006-01-02")
 	}
-}
 
-// Close implements Output
-func (o *XLSXOutput) Close() error {
-	o.lock.Lock()
-	defer o.lock.Unlock()
+	cells := []interface{}{m.Path, m.Version, spdx, lic, allowed, m.Latest, stale, exception, url}
+	if o.ShowHashes {
+		cells = append(cells, m.Hash)
+	}
 
-	const s = "Sheet1"
-	f := excelize.NewFile()
+	return xlsxRow{
+		Cells:   cells,
+		Style:   style,
+		License: summary,
+		Allowed: allowed,
+	}
+}
 
-	// Headers
-	f.SetCellValue(s, "A1", "Dependency")
-	f.SetCellValue(s, "B1", "Version")
-	f.SetCellValue(s, "C1", "SPDX ID")
-	f.SetCellValue(s, "D1", "License")
-	f.SetCellValue(s, "E1", "Allowed")
-	f.SetColWidth(s, "A", "A", 40)
-	f.SetColWidth(s, "B", "B", 20)
-	f.SetColWidth(s, "C", "C", 20)
-	f.SetColWidth(s, "D", "D", 40)
-	f.SetColWidth(s, "E", "E", 10)
+// Flush implements ReportOutput
+func (o *XLSXOutput) Flush(results []Result) error {
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName("Sheet1", "Summary"); err != nil {
+		return err
+	}
+	for _, s := range []string{"Details", "Violations"} {
+		if _, err := f.NewSheet(s); err != nil {
+			return err
+		}
+	}
 
 	// Create all our styles
-	redStyle, _ := f.NewStyle(`{"fill":{"type":"pattern","pattern":1,"color":["#FFCCCC"]}}`)
-	yellowStyle, _ := f.NewStyle(`{"fill":{"type":"pattern","pattern":1,"color":["#FFC107"]}}`)
-	greenStyle, _ := f.NewStyle(`{"fill":{"type":"pattern","pattern":1,"color":["#9CCC65"]}}`)
-
-	// Sort the modules by name
-	keys := make([]string, 0, len(o.modules))
-	index := map[string]*module.Module{}
-	for m := range o.modules {
-		keys = append(keys, m.Path)
-		index[m.Path] = m
-	}
-	sort.Strings(keys)
-
-	// Go through each module and output it into the spreadsheet
-	for i, k := range keys {
-		row := strconv.FormatInt(int64(i+2), 10)
-
-		m := index[k]
-		f.SetCellValue(s, "A"+row, m.Path)
-		f.SetCellValue(s, "B"+row, m.Version)
-		f.SetCellValue(s, "E"+row, "unknown")
-		f.SetCellStyle(s, "A"+row, "A"+row, yellowStyle)
-		f.SetCellStyle(s, "B"+row, "B"+row, yellowStyle)
-		f.SetCellStyle(s, "C"+row, "C"+row, yellowStyle)
-		f.SetCellStyle(s, "D"+row, "D"+row, yellowStyle)
-		f.SetCellStyle(s, "E"+row, "E"+row, yellowStyle)
-
-		raw := o.modules[m]
-		if raw == nil {
-			f.SetCellValue(s, "D"+row, "no")
-			f.SetCellStyle(s, "A"+row, "A"+row, redStyle)
-			f.SetCellStyle(s, "B"+row, "B"+row, redStyle)
-			f.SetCellStyle(s, "C"+row, "C"+row, redStyle)
-			f.SetCellStyle(s, "D"+row, "D"+row, redStyle)
-			f.SetCellStyle(s, "E"+row, "E"+row, redStyle)
-			continue
+	styles := map[xlsxStyle]int{}
+	for _, st := range []struct {
+		Style xlsxStyle
+		Color string
+	}{
+		{xlsxRed, "#FFCCCC"},
+		{xlsxYellow, "#FFC107"},
+		{xlsxGreen, "#9CCC65"},
+	} {
+		id, err := f.NewStyle(&excelize.Style{
+			Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{st.Color}},
+		})
+		if err != nil {
+			return err
 		}
+		styles[st.Style] = id
+	}
+
+	details, err := o.newModulesSheet(f, "Details")
+	if err != nil {
+		return err
+	}
+	violations, err := o.newModulesSheet(f, "Violations")
+	if err != nil {
+		return err
+	}
 
-		// If the value is an error, then note the error
-		if err, ok := raw.(error); ok {
-			f.SetCellValue(s, "D"+row, fmt.Sprintf("ERROR: %s", err))
-			f.SetCellValue(s, "E"+row, "no")
-			f.SetCellStyle(s, "A"+row, "A"+row, redStyle)
-			f.SetCellStyle(s, "B"+row, "B"+row, redStyle)
-			f.SetCellStyle(s, "C"+row, "C"+row, redStyle)
-			f.SetCellStyle(s, "D"+row, "D"+row, redStyle)
-			f.SetCellStyle(s, "E"+row, "E"+row, redStyle)
-			continue
+	// Go through each module and output it into the Details sheet, and
+	// into the Violations sheet if it isn't allowed. Only the columns up
+	// to Allowed are colored. Just the counts per license are kept for
+	// the Summary sheet.
+	summary := map[string]*xlsxLicense{}
+	total := xlsxLicense{License: "Total"}
+	for _, res := range results {
+		res := res
+		r := o.row(&res.Module, res.License, res.Err)
+
+		values := make([]interface{}, len(r.Cells))
+		for i, v := range r.Cells {
+			values[i] = v
+			if i <= 4 {
+				values[i] = excelize.Cell{StyleID: styles[r.Style], Value: v}
+			}
+		}
+		if err := details.Append(values); err != nil {
+			return err
+		}
+		if r.Allowed != "yes" {
+			if err := violations.Append(values); err != nil {
+				return err
+			}
 		}
 
-		// If the value is a license, then mark the license
-		if lic, ok := raw.(*license.License); ok {
-			if lic != nil {
-				f.SetCellValue(s, fmt.Sprintf("C%d", i+2), lic.SPDX)
+		sum, ok := summary[r.License]
+		if !ok {
+			sum = &xlsxLicense{License: r.License}
+			if res.License != nil && res.Err == nil {
+				sum.SPDX = res.License.SPDX
 			}
-			f.SetCellValue(s, fmt.Sprintf("D%d", i+2), lic.String())
-			if o.Config != nil {
-				switch o.Config.Allowed(lic) {
-				case config.StateAllowed:
-					f.SetCellValue(s, fmt.Sprintf("E%d", i+2), "yes")
-					f.SetCellStyle(s, "A"+row, "A"+row, greenStyle)
-					f.SetCellStyle(s, "B"+row, "B"+row, greenStyle)
-					f.SetCellStyle(s, "C"+row, "C"+row, greenStyle)
-					f.SetCellStyle(s, "D"+row, "D"+row, greenStyle)
-					f.SetCellStyle(s, "E"+row, "E"+row, greenStyle)
-
-				case config.StateDenied:
-					f.SetCellValue(s, fmt.Sprintf("E%d", i+2), "no")
-					f.SetCellStyle(s, "A"+row, "A"+row, redStyle)
-					f.SetCellStyle(s, "B"+row, "B"+row, redStyle)
-					f.SetCellStyle(s, "C"+row, "C"+row, redStyle)
-					f.SetCellStyle(s, "D"+row, "D"+row, redStyle)
-					f.SetCellStyle(s, "E"+row, "E"+row, redStyle)
-				}
+			summary[r.License] = sum
+		}
+		for _, c := range []*xlsxLicense{sum, &total} {
+			c.Modules++
+			switch r.Allowed {
+			case "yes":
+				c.Allowed++
+
+			case "no":
+				c.Denied++
+
+			default:
+				c.Unknown++
 			}
 		}
 	}
+	if err := details.Flush(); err != nil {
+		return err
+	}
+	if err := violations.Flush(); err != nil {
+		return err
+	}
+	if err := writeSummary(f, summary, &total); err != nil {
+		return err
+	}
 
 	// Save
 	if err := f.SaveAs(o.Path); err != nil {
@@ -153,3 +224,88 @@ func (o *XLSXOutput) Close() error {
 
 	return nil
 }
+
+// xlsxSheet streams the rows of a sheet, starting at the first row.
+type xlsxSheet struct {
+	*excelize.StreamWriter
+	rows int
+}
+
+// newXLSXSheet creates a stream writer for the sheet s with the given
+// column widths, starting at column A.
+func newXLSXSheet(f *excelize.File, s string, widths []float64) (*xlsxSheet, error) {
+	sw, err := f.NewStreamWriter(s)
+	if err != nil {
+		return nil, err
+	}
+	for i, w := range widths {
+		if err := sw.SetColWidth(i+1, i+1, w); err != nil {
+			return nil, err
+		}
+	}
+
+	return &xlsxSheet{StreamWriter: sw}, nil
+}
+
+// Append writes the next row.
+func (s *xlsxSheet) Append(values []interface{}) error {
+	s.rows++
+	return s.SetRow("A"+strconv.Itoa(s.rows), values)
+}
+
+// newModulesSheet starts a sheet with a row per module by writing the
+// headers.
+func (o *XLSXOutput) newModulesSheet(f *excelize.File, s string) (*xlsxSheet, error) {
+	headers := xlsxHeaders
+	widths := []float64{40, 20, 20, 40, 10, 20, 15, 40, 60}
+	if o.ShowHashes {
+		headers = append(headers[:len(headers):len(headers)], "Hash")
+		widths = append(widths, 60)
+	}
+
+	sheet, err := newXLSXSheet(f, s, widths)
+	if err != nil {
+		return nil, err
+	}
+	if err := sheet.Append(headers); err != nil {
+		return nil, err
+	}
+
+	return sheet, nil
+}
+
+// writeSummary writes the Summary sheet, which counts the modules per
+// license, the most common license first, followed by the total.
+func writeSummary(f *excelize.File, summary map[string]*xlsxLicense, total *xlsxLicense) error {
+	sheet, err := newXLSXSheet(f, "Summary", []float64{40, 20, 10, 10, 10, 10})
+	if err != nil {
+		return err
+	}
+	if err := sheet.Append([]interface{}{
+		"License", "SPDX ID", "Modules", "Allowed", "Denied", "Unknown",
+	}); err != nil {
+		return err
+	}
+
+	licenses := make([]*xlsxLicense, 0, len(summary))
+	for _, sum := range summary {
+		licenses = append(licenses, sum)
+	}
+	sort.Slice(licenses, func(i, j int) bool {
+		if licenses[i].Modules != licenses[j].Modules {
+			return licenses[i].Modules > licenses[j].Modules
+		}
+		return licenses[i].License < licenses[j].License
+	})
+	licenses = append(licenses, total)
+
+	for _, sum := range licenses {
+		if err := sheet.Append([]interface{}{
+			sum.License, sum.SPDX, sum.Modules, sum.Allowed, sum.Denied, sum.Unknown,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return sheet.Flush()
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestXLSXOutput_sorted(t *testing.T) {
+	mods := []*module.Module{
+		{Path: "github.com/foo/zeta", Version: "v1.0.0"},
+		{Path: "github.com/foo/bar", Version: "v1.2.0"},
+		{Path: "github.com/foo/alpha", Version: "v0.1.0"},
+		{Path: "github.com/foo/bar", Version: "v1.1.0"},
+	}
+
+	rows := func(order []int) [][]string {
+		path := filepath.Join(t.TempDir(), "report.xlsx")
+		store := &ResultStore{Reports: []ReportOutput{&XLSXOutput{Path: path, Config: &config.Config{}}}}
+		for _, i := range order {
+			store.Finish(mods[i], &license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+		}
+		require.NoError(t, store.Close())
+
+		var result [][]string
+		for _, row := range xlsxRows(t, path, "Details")[1:] {
+			result = append(result, row[:2])
+		}
+		return result
+	}
+
+	expected := [][]string{
+		{"github.com/foo/alpha", "v0.1.0"},
+		{"github.com/foo/bar", "v1.1.0"},
+		{"github.com/foo/bar", "v1.2.0"},
+		{"github.com/foo/zeta", "v1.0.0"},
+	}
+	require.Equal(t, expected, rows([]int{0, 1, 2, 3}))
+	require.Equal(t, expected, rows([]int{3, 2, 1, 0}))
+}
+
+func TestXLSXOutput_large(t *testing.T) {
+	// write returns the bytes and the number of allocations per module of
+	// writing a report of n modules.
+	write := func(path string, n int) (bytes, mallocs uint64) {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		writeXLSX(t, path, n)
+		runtime.ReadMemStats(&after)
+		return (after.TotalAlloc - before.TotalAlloc) / uint64(n),
+			(after.Mallocs - before.Mallocs) / uint64(n)
+	}
+
+	const n = 4000
+	path := filepath.Join(t.TempDir(), "report.xlsx")
+	smallBytes, _ := write(filepath.Join(t.TempDir(), "small.xlsx"), n/4)
+	bytes, mallocs := write(path, n)
+
+	// The sheets are streamed, so the cost per module doesn't grow with
+	// the size of the report.
+	require.Less(t, bytes, uint64(128<<10))
+	require.Less(t, mallocs, uint64(500))
+	require.Less(t, bytes, smallBytes*3/2)
+
+	rows := xlsxRows(t, path, "Details")
+	require.Len(t, rows, n+1)
+	require.Equal(t, []string{"github.com/foo/mod0000", "v1.0.0", "MIT", "MIT License", "yes"}, rows[1])
+	require.Equal(t, "no", rows[n][4])
+}
+
+func TestXLSXOutput_sheets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.xlsx")
+	store := &ResultStore{Reports: []ReportOutput{&XLSXOutput{Path: path, Config: &config.Config{
+		Allow:      []string{"MIT"},
+		Deny:       []string{"GPL-3.0"},
+		Exceptions: map[string]string{"github.com/foo/excepted": "approved by legal"},
+	}}}}
+
+	mit := &license.License{Name: "MIT License", SPDX: "MIT"}
+	gpl := &license.License{Name: "GNU General Public License v3.0", SPDX: "GPL-3.0"}
+	store.Finish(&module.Module{Path: "github.com/foo/a", Version: "v1.0.0"}, mit, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/b", Version: "v1.0.0"}, mit, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/denied", Version: "v1.0.0"}, gpl, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/excepted", Version: "v1.0.0"}, gpl, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/failed", Version: "v1.0.0"}, nil, fmt.Errorf("boom"))
+	store.Finish(&module.Module{Path: "github.com/foo/none", Version: "v1.0.0"}, nil, nil)
+	store.Finish(&module.Module{Path: "github.com/foo/other", Version: "v1.0.0"},
+		&license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0"}, nil)
+	require.NoError(t, store.Close())
+
+	f, err := excelize.OpenFile(path)
+	require.NoError(t, err)
+	require.Equal(t, map[int]string{1: "Summary", 2: "Details", 3: "Violations"}, f.GetSheetMap())
+	require.NoError(t, f.Close())
+
+	// Every module is in the details
+	require.Len(t, xlsxRows(t, path, "Details"), 8)
+
+	// Only the modules that aren't allowed are violations
+	var violations []string
+	for _, row := range xlsxRows(t, path, "Violations")[1:] {
+		violations = append(violations, row[0]+" "+row[4])
+	}
+	require.Equal(t, []string{
+		"github.com/foo/denied no",
+		"github.com/foo/failed no",
+		"github.com/foo/none no",
+		"github.com/foo/other no",
+	}, violations)
+
+	// The summary counts the modules per license, then all of them
+	require.Equal(t, [][]string{
+		{"License", "SPDX ID", "Modules", "Allowed", "Denied", "Unknown"},
+		{"GNU General Public License v3.0", "GPL-3.0", "2", "1", "1", "0"},
+		{"MIT License", "MIT", "2", "2", "0", "0"},
+		{"<license not found or detected>", "", "1", "0", "1", "0"},
+		{"<lookup failed>", "", "1", "0", "1", "0"},
+		{"Apache License 2.0", "Apache-2.0", "1", "0", "1", "0"},
+		{"Total", "", "7", "3", "4", "0"},
+	}, xlsxRows(t, path, "Summary"))
+}
+
+func TestXLSXOutput_hashes(t *testing.T) {
+	rows := func(show bool) [][]string {
+		path := filepath.Join(t.TempDir(), "report.xlsx")
+		store := &ResultStore{Reports: []ReportOutput{&XLSXOutput{
+			Path:       path,
+			Config:     &config.Config{},
+			ShowHashes: show,
+		}}}
+		store.Finish(&module.Module{Path: "github.com/foo/bar", Version: "v1.0.0", Hash: "h1:abcd1234="},
+			&license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+		require.NoError(t, store.Close())
+
+		return xlsxRows(t, path, "Details")
+	}
+
+	// Omitted by default
+	result := rows(false)
+	require.Len(t, result[0], 9)
+	require.NotContains(t, result[0], "Hash")
+	require.NotContains(t, result[1], "h1:abcd1234=")
+
+	// The last column with ShowHashes
+	result = rows(true)
+	require.Equal(t, "Hash", result[0][9])
+	require.Equal(t, "h1:abcd1234=", result[1][9])
+}
+
+func BenchmarkXLSXOutput(b *testing.B) {
+	dir := b.TempDir()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		writeXLSX(b, filepath.Join(dir, "report.xlsx"), 3000)
+	}
+}
+
+// writeXLSX writes a report of n synthetic modules, every tenth of which
+// is denied.
+func writeXLSX(t require.TestingT, path string, n int) {
+	out := &XLSXOutput{Path: path, Config: &config.Config{
+		Allow: []string{"MIT"},
+		Deny:  []string{"GPL-3.0"},
+	}}
+	mit := &license.License{Name: "MIT License", SPDX: "MIT"}
+	gpl := &license.License{Name: "GNU General Public License v3.0", SPDX: "GPL-3.0"}
+	store := &ResultStore{Reports: []ReportOutput{out}}
+	for i := n - 1; i >= 0; i-- {
+		l := mit
+		if i%10 == 9 {
+			l = gpl
+		}
+
+		store.Finish(&module.Module{
+			Path:    fmt.Sprintf("github.com/foo/mod%04d", i),
+			Version: "v1.0.0",
+		}, l, nil)
+	}
+	require.NoError(t, store.Close())
+}
+
+// xlsxRows returns the rows of a sheet of an XLSX file.
+func xlsxRows(t *testing.T, path, sheet string) [][]string {
+	f, err := excelize.OpenFile(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	rows, err := f.GetRows(sheet)
+	require.NoError(t, err)
+	return rows
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// reportDirFiles are the formats that -report-dir can write, with the name
+// of their file in the directory, in the order of -list-formats.
+var reportDirFiles = []struct {
+	Format string
+	Name   string
+}{
+	{"xlsx", "report.xlsx"},
+	{"json", "report.json"},
+	{"jsonl", "report.jsonl"},
+	{"cyclonedx", "report.cdx.json"},
+	{"spdx", "report.spdx"},
+	{"junit", "report.junit.xml"},
+	{"html", "report.html"},
+	{"markdown", "report.md"},
+}
+
+// reportDirPaths returns the path of the report of each format in the
+// comma-separated list of -formats, by format, when written to dir.
+func reportDirPaths(dir, list string) (map[string]string, error) {
+	paths := map[string]string{}
+	for _, format := range strings.Split(list, ",") {
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format == "" {
+			continue
+		}
+
+		var names []string
+		for _, f := range reportDirFiles {
+			if f.Format == format {
+				paths[format] = filepath.Join(dir, f.Name)
+			}
+			names = append(names, f.Format)
+		}
+		if _, ok := paths[format]; !ok {
+			return nil, fmt.Errorf("unknown format %q in -formats, expected one of: %s",
+				format, strings.Join(names, ", "))
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("-formats is empty")
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+// sbomEntry is a module and its license as recorded in an SBOM.
+type sbomEntry struct {
+	Module  module.Module
+	License *license.License
+}
+
+// sbomFinder is a license.Finder that returns the licenses recorded in
+// CycloneDX or SPDX documents, so that the policy can be checked for an
+// SBOM that was produced elsewhere without looking anything up.
+type sbomFinder struct {
+	licenses map[string]*license.License // by path@version
+}
+
+// License implements license.Finder
+func (f *sbomFinder) License(ctx context.Context, m module.Module) (*license.License, error) {
+	if l := f.licenses[m.Path+"@"+m.Version]; l != nil {
+		// Copy so the lookup can't modify the SBOM's license
+		result := *l
+		return &result, nil
+	}
+
+	return nil, license.ErrNotFound
+}
+
+// readSBOMs reads the modules and their licenses from the given CycloneDX
+// or SPDX documents. Modules in several documents are only returned once
+// and are sorted like ReadModules sorts them.
+func readSBOMs(paths []string) ([]module.Module, *sbomFinder, error) {
+	f := &sbomFinder{licenses: map[string]*license.License{}}
+	seen := map[string]struct{}{}
+	var mods []module.Module
+	for _, path := range paths {
+		entries, err := readSBOM(path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, e := range entries {
+			key := e.Module.Path + "@" + e.Module.Version
+			if e.License != nil {
+				f.licenses[key] = e.License
+			}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+
+			seen[key] = struct{}{}
+			mods = append(mods, e.Module)
+		}
+	}
+
+	sort.Slice(mods, func(i, j int) bool {
+		if mods[i].Path != mods[j].Path {
+			return mods[i].Path < mods[j].Path
+		}
+
+		return mods[i].Version < mods[j].Version
+	})
+
+	return mods, f, nil
+}
+
+// readSBOM reads an SBOM file. CycloneDX documents must be JSON and SPDX
+// documents tag-value, the formats of the -out-cyclonedx and -out-spdx
+// outputs.
+func readSBOM(path string) ([]sbomEntry, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []sbomEntry
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		entries, err = parseCycloneDX(trimmed)
+
+	case bytes.HasPrefix(trimmed, []byte("SPDXVersion:")):
+		entries, err = parseSPDX(string(trimmed))
+
+	default:
+		err = fmt.Errorf("not a CycloneDX JSON or SPDX tag-value document")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+
+	return entries, nil
+}
+
+// parseCycloneDX parses the library components of a CycloneDX JSON
+// document.
+func parseCycloneDX(data []byte) ([]sbomEntry, error) {
+	var bom cdxBOM
+	if err := json.Unmarshal(data, &bom); err != nil {
+		return nil, err
+	}
+	if bom.BOMFormat != "CycloneDX" {
+		return nil, fmt.Errorf("unexpected bomFormat %q", bom.BOMFormat)
+	}
+
+	var result []sbomEntry
+	for _, c := range bom.Components {
+		if c.Type != "library" || c.Name == "" {
+			continue
+		}
+
+		e := sbomEntry{Module: module.Module{Path: c.Name, Version: c.Version}}
+		for _, h := range c.Hashes {
+			if h.Alg != "SHA-256" {
+				continue
+			}
+
+			if sum, err := hex.DecodeString(h.Content); err == nil && len(sum) == 32 {
+				e.Module.Hash = "h1:" + base64.StdEncoding.EncodeToString(sum)
+			}
+		}
+
+		// Only the first license is used, which is the only one that
+		// CycloneDXOutput writes
+		if len(c.Licenses) > 0 {
+			switch ref := c.Licenses[0]; {
+			case ref.Expression != "":
+				e.License = &license.License{Name: ref.Expression, SPDX: ref.Expression}
+
+			case ref.License != nil && ref.License.ID != "":
+				e.License = &license.License{Name: ref.License.ID, SPDX: ref.License.ID}
+
+			case ref.License != nil && ref.License.Name != "":
+				e.License = &license.License{Name: ref.License.Name}
+			}
+		}
+
+		result = append(result, e)
+	}
+
+	return result, nil
+}
+
+// parseSPDX parses the packages of an SPDX tag-value document. The
+// concluded license is used, falling back to the declared license and
+// then the license detected by golicense that isn't an SPDX expression.
+func parseSPDX(data string) ([]sbomEntry, error) {
+	type spdxPackageInfo struct {
+		Name, Version       string
+		Concluded, Declared string
+		Comment             string
+	}
+
+	var pkgs []*spdxPackageInfo
+	var p *spdxPackageInfo
+	s := bufio.NewScanner(strings.NewReader(data))
+	for s.Scan() {
+		idx := strings.Index(s.Text(), ":")
+		if idx < 0 {
+			continue
+		}
+		tag, value := s.Text()[:idx], strings.TrimSpace(s.Text()[idx+1:])
+
+		if tag == "PackageName" {
+			p = &spdxPackageInfo{Name: value}
+			pkgs = append(pkgs, p)
+			continue
+		}
+		if p == nil {
+			continue
+		}
+
+		switch tag {
+		case "PackageVersion":
+			p.Version = value
+
+		case "PackageLicenseConcluded":
+			p.Concluded = value
+
+		case "PackageLicenseDeclared":
+			p.Declared = value
+
+		case "PackageLicenseComments":
+			p.Comment = strings.TrimSuffix(strings.TrimPrefix(value, "<text>"), "</text>")
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]sbomEntry, 0, len(pkgs))
+	for _, p := range pkgs {
+		e := sbomEntry{Module: module.Module{Path: p.Name, Version: p.Version}}
+		switch {
+		case spdxAssertion(p.Concluded):
+			e.License = &license.License{Name: p.Concluded, SPDX: p.Concluded}
+
+		case spdxAssertion(p.Declared):
+			e.License = &license.License{Name: p.Declared, SPDX: p.Declared}
+
+		case strings.HasPrefix(p.Comment, spdxDetectedPrefix):
+			name := strings.TrimPrefix(p.Comment, spdxDetectedPrefix)
+			if idx := strings.Index(name, " "+spdxExceptionPrefix); idx >= 0 {
+				name = name[:idx]
+			}
+			e.License = &license.License{Name: name}
+		}
+
+		result = append(result, e)
+	}
+
+	return result, nil
+}
+
+// spdxAssertion returns true if the license field has a value.
+func spdxAssertion(v string) bool {
+	return v != "" && v != spdxNoAssertion && v != "NONE"
+}
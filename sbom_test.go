@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSBOMs(t *testing.T) {
+	mods := []struct {
+		Module  module.Module
+		License *license.License
+	}{
+		{
+			module.Module{
+				Path:    "github.com/foo/bar",
+				Version: "v1.2.3",
+				Hash:    "h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=",
+			},
+			&license.License{Name: "MIT", SPDX: "MIT"},
+		},
+		{
+			module.Module{Path: "github.com/foo/custom", Version: "v2.0.0+incompatible"},
+			&license.License{Name: "Custom License"},
+		},
+		{
+			module.Module{Path: "github.com/foo/gpl", Version: "v1.0.0"},
+			&license.License{Name: "GPL-3.0", SPDX: "GPL-3.0"},
+		},
+		{
+			module.Module{Path: "github.com/foo/missing", Version: "v0.1.0"},
+			nil,
+		},
+	}
+
+	cases := []struct {
+		Name   string
+		Output func(path string) ReportOutput
+		Hash   bool
+	}{
+		{
+			"cyclonedx",
+			func(path string) ReportOutput { return &CycloneDXOutput{Path: path} },
+			true,
+		},
+		{
+			"spdx",
+			func(path string) ReportOutput { return &SPDXOutput{Path: path} },
+			false,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "sbom")
+			store := &ResultStore{Reports: []ReportOutput{tt.Output(path)}}
+			for _, m := range mods {
+				m := m
+				store.Finish(&m.Module, m.License, nil)
+			}
+			require.NoError(t, store.Close())
+
+			// Reading the same document twice must not duplicate modules
+			actual, f, err := readSBOMs([]string{path, path})
+			require.NoError(t, err)
+			require.Len(t, actual, len(mods))
+
+			for i, m := range mods {
+				expected := m.Module
+				if !tt.Hash {
+					expected.Hash = ""
+				}
+				require.Equal(t, expected, actual[i])
+
+				l, err := f.License(context.Background(), actual[i])
+				if m.License == nil {
+					require.True(t, errors.Is(err, license.ErrNotFound), "%v", err)
+				} else {
+					require.NoError(t, err)
+				}
+				require.Equal(t, m.License, l, m.Module.Path)
+			}
+		})
+	}
+}
+
+func TestReadSBOMs_invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sbom")
+	require.NoError(t, ioutil.WriteFile(path, []byte("hello"), 0644))
+
+	_, _, err := readSBOMs([]string{path})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), path)
+}
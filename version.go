@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// These are set when building a release with -ldflags, for example:
+//
+//	go build -ldflags "-X main.buildVersion=v1.2.3 -X main.buildCommit=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%FT%TZ)"
+var (
+	buildVersion = "dev"
+	buildCommit  = "none"
+	buildDate    = "unknown"
+)
+
+// versionString returns the version, git commit and build date of this
+// build for the -version flag.
+func versionString() string {
+	v := buildVersion
+	if v == "dev" {
+		// Installed with "go install module@version" rather than a release
+		if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			v = bi.Main.Version
+		}
+	}
+
+	return fmt.Sprintf("golicense %s (commit %s, built %s, %s)", v, buildCommit, buildDate, runtime.Version())
+}
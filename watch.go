@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/mitchellh/golicense/analysis"
+	"github.com/mitchellh/golicense/config"
+)
+
+// watchInterval is how often -watch checks go.mod and go.sum for changes
+// by default.
+const watchInterval = time.Second
+
+// watcher re-runs the analysis of a module directory whenever its go.mod or
+// go.sum file changes and prints how the licenses changed since the
+// previous run. Modules that didn't change are taken from the cache, so
+// only new modules are looked up.
+type watcher struct {
+	// Dir is the module directory or the path of its go.mod file.
+	Dir string
+
+	// Out is where the changes of each run are written.
+	Out io.Writer
+
+	// Options are the options of each run. Options.Cache should be set,
+	// otherwise every module is looked up again on each change.
+	Options analysis.Options
+
+	// Interval is how often the files are checked for changes. If zero,
+	// watchInterval is used.
+	Interval time.Duration
+
+	// AfterRun, if set, is called after each run, such as to save the
+	// cache. An error is reported but doesn't stop the watch.
+	AfterRun func() error
+
+	stamps map[string]fileStamp
+	last   map[string]diffModule
+}
+
+// fileStamp is what is compared to notice that a file changed. A missing
+// file has the zero stamp.
+type fileStamp struct {
+	ModTime time.Time
+	Size    int64
+}
+
+// Run analyzes the module and then again on every change until the context
+// ends, which is the only error returned. Errors of a run, such as a
+// go.mod file that is being edited, are written to Out.
+func (w *watcher) Run(ctx context.Context) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = watchInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if w.changed() {
+			if err := w.run(ctx); err != nil {
+				fmt.Fprintf(w.Out, "Error: %s\n", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-ticker.C:
+		}
+	}
+}
+
+// changed returns true if go.mod or go.sum changed since it was last
+// called. It is always true the first time.
+func (w *watcher) changed() bool {
+	goMod, _ := analysis.GoModPath(w.Dir)
+	files := []string{goMod, filepath.Join(filepath.Dir(goMod), "go.sum")}
+
+	stamps := make(map[string]fileStamp, len(files))
+	for _, fn := range files {
+		if fi, err := os.Stat(fn); err == nil {
+			stamps[fn] = fileStamp{ModTime: fi.ModTime(), Size: fi.Size()}
+		}
+	}
+
+	changed := w.stamps == nil || len(stamps) != len(w.stamps)
+	for fn, s := range stamps {
+		if prev, ok := w.stamps[fn]; !ok || !prev.ModTime.Equal(s.ModTime) || prev.Size != s.Size {
+			changed = true
+		}
+	}
+
+	w.stamps = stamps
+	return changed
+}
+
+// run analyzes the module once and writes the changes since the previous
+// run followed by a summary of all modules.
+func (w *watcher) run(ctx context.Context) error {
+	results, err := analysis.Analyze(ctx, []string{w.Dir}, w.Options)
+	if err != nil {
+		return err
+	}
+
+	var summary termSummary
+	current := make(map[string]diffModule, len(results))
+	for _, r := range results {
+		dm := diffModule{Path: r.Module.Path, Version: r.Module.Version}
+		if r.License != nil {
+			dm.License, dm.SPDX = r.License.Name, r.License.SPDX
+		}
+		current[dm.Path] = dm
+
+		switch {
+		case r.License == nil && r.Err != nil && r.State != config.StateAllowed:
+			summary.Failed++
+
+		case r.State == config.StateAllowed:
+			summary.Allowed++
+
+		case r.State == config.StateDenied:
+			summary.Denied++
+
+		default:
+			summary.Unknown++
+		}
+	}
+
+	d := watchDiff(w.last, current)
+	w.last = current
+
+	fmt.Fprintf(w.Out, "[%s] Checked %s\n", time.Now().Format("15:04:05"), w.Dir)
+	if err := d.WriteText(w.Out); err != nil {
+		return err
+	}
+	fmt.Fprintln(w.Out, summary.String())
+
+	if w.AfterRun != nil {
+		return w.AfterRun()
+	}
+
+	return nil
+}
+
+// watchDiff compares the modules of two runs. Unlike diffCaches, a module
+// is also changed if only its version changed, since that is usually what
+// was edited.
+func watchDiff(old, new map[string]diffModule) cacheDiff {
+	var d cacheDiff
+	for path, nm := range new {
+		om, ok := old[path]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, nm)
+
+		case om != nm:
+			d.Changed = append(d.Changed, diffChange{Path: path, Old: om, New: nm})
+		}
+	}
+	for path, om := range old {
+		if _, ok := new[path]; !ok {
+			d.Removed = append(d.Removed, om)
+		}
+	}
+
+	sort.Slice(d.Added, func(i, j int) bool { return d.Added[i].Path < d.Added[j].Path })
+	sort.Slice(d.Removed, func(i, j int) bool { return d.Removed[i].Path < d.Removed[j].Path })
+	sort.Slice(d.Changed, func(i, j int) bool { return d.Changed[i].Path < d.Changed[j].Path })
+	return d
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/golicense/analysis"
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher_goSumChange(t *testing.T) {
+	dir := t.TempDir()
+	writeModule := func(gomod, gosum string) {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0644))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.sum"), []byte(gosum), 0644))
+	}
+	writeModule(
+		"module example.com/app\n\nrequire github.com/foo/bar v1.0.0\n",
+		"github.com/foo/bar v1.0.0 h1:bar=\n")
+
+	var f license.MockFinder
+	f.On("License", mock.Anything, mock.Anything).Return(
+		&license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+
+	var buf bytes.Buffer
+	w := &watcher{
+		Dir: dir,
+		Out: &buf,
+		Options: analysis.Options{
+			Config:      &config.Config{Allow: []string{"MIT"}},
+			Finders:     []license.Finder{&f},
+			Translators: []license.Translator{},
+			Cache:       &Cache{},
+		},
+	}
+
+	require.True(t, w.changed())
+	require.NoError(t, w.run(context.Background()))
+	f.AssertNumberOfCalls(t, "License", 1)
+	require.Contains(t, buf.String(), "+ github.com/foo/bar v1.0.0: MIT License (MIT)")
+	require.Contains(t, buf.String(), "1 modules: 1 allowed, 0 denied, 0 unknown, 0 failed")
+
+	// Nothing changed, so there is nothing to do
+	require.False(t, w.changed())
+
+	// A new dependency is only looked up itself
+	buf.Reset()
+	writeModule(
+		"module example.com/app\n\nrequire (\n\tgithub.com/foo/bar v1.0.0\n\tgithub.com/foo/baz v0.1.0\n)\n",
+		"github.com/foo/bar v1.0.0 h1:bar=\ngithub.com/foo/baz v0.1.0 h1:baz=\n")
+	require.True(t, w.changed())
+	require.NoError(t, w.run(context.Background()))
+	f.AssertNumberOfCalls(t, "License", 2)
+	require.Equal(t, "github.com/foo/baz", f.Calls[1].Arguments.Get(1).(module.Module).Path)
+	require.Contains(t, buf.String(), "+ github.com/foo/baz v0.1.0: MIT License (MIT)")
+	require.NotContains(t, buf.String(), "github.com/foo/bar")
+	require.Contains(t, buf.String(), "2 modules: 2 allowed, 0 denied, 0 unknown, 0 failed")
+}
+
+func TestWatchDiff(t *testing.T) {
+	old := map[string]diffModule{
+		"github.com/foo/bar": {Path: "github.com/foo/bar", Version: "v1.0.0", SPDX: "MIT"},
+		"github.com/foo/baz": {Path: "github.com/foo/baz", Version: "v1.0.0", SPDX: "MIT"},
+	}
+	new := map[string]diffModule{
+		"github.com/foo/bar": {Path: "github.com/foo/bar", Version: "v1.1.0", SPDX: "MIT"},
+		"github.com/foo/qux": {Path: "github.com/foo/qux", Version: "v1.0.0", SPDX: "MPL-2.0"},
+	}
+
+	d := watchDiff(old, new)
+	require.Equal(t, []diffModule{new["github.com/foo/qux"]}, d.Added)
+	require.Equal(t, []diffModule{old["github.com/foo/baz"]}, d.Removed)
+
+	// A version change alone is reported too
+	require.Equal(t, []diffChange{{
+		Path: "github.com/foo/bar",
+		Old:  old["github.com/foo/bar"],
+		New:  new["github.com/foo/bar"],
+	}}, d.Changed)
+}